@@ -0,0 +1,478 @@
+package bubbleteahelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// DefaultLogLevelEnvironmentVariable is the environment variable from
+	// which the level of LogHandler is read unless overridden
+	DefaultLogLevelEnvironmentVariable = "LOG_LEVEL"
+
+	defaultLogMaxSize     int64 = 10 * 1024 * 1024
+	defaultLogMaxBackups        = 3
+	defaultLogBufferLines       = 500
+)
+
+// LogHandler is a slog.Handler which writes text records to a rotating log
+// file and, optionally, to a LogBuffer shown by a LogPane inside the TUI.
+type LogHandler struct {
+	handlers []slog.Handler
+	file     *RotatingFile
+}
+
+// LogHandlerOptions configures the level, the rotation of the log file, the
+// LogBuffer and the source locations of records of NewLogHandler. By
+// default, the level is read from LOG_LEVEL (info if it is unset or invalid)
+// and the log file is rotated at 10 MiB with 3 rotated files kept.
+type LogHandlerOptions struct {
+	level      slog.Leveler
+	levelEnv   string
+	maxSize    int64
+	maxBackups int
+	buffer     *LogBuffer
+	addSource  bool
+}
+
+// LogHandlerOption is a functional option for NewLogHandler.
+type LogHandlerOption func(*LogHandlerOptions)
+
+// WithLogLevel sets the minimum level of records to be logged which takes
+// precedence over the level from environment variable.
+func WithLogLevel(level slog.Leveler) LogHandlerOption {
+	return func(o *LogHandlerOptions) {
+		o.level = level
+	}
+}
+
+// WithLogLevelEnvironmentVariable sets the environment variable from which
+// the minimum level is read (default: LOG_LEVEL).
+func WithLogLevelEnvironmentVariable(name string) LogHandlerOption {
+	return func(o *LogHandlerOptions) {
+		o.levelEnv = name
+	}
+}
+
+// WithLogMaxSize sets the size in bytes at which the log file is rotated.
+func WithLogMaxSize(size int64) LogHandlerOption {
+	return func(o *LogHandlerOptions) {
+		o.maxSize = size
+	}
+}
+
+// WithLogMaxBackups sets the number of rotated log files to be kept.
+func WithLogMaxBackups(count int) LogHandlerOption {
+	return func(o *LogHandlerOptions) {
+		o.maxBackups = count
+	}
+}
+
+// WithLogBuffer sets the buffer to which records are also written so that
+// they can be shown by a LogPane.
+func WithLogBuffer(buffer *LogBuffer) LogHandlerOption {
+	return func(o *LogHandlerOptions) {
+		o.buffer = buffer
+	}
+}
+
+// WithLogSource includes source file and line of the log statement.
+func WithLogSource() LogHandlerOption {
+	return func(o *LogHandlerOptions) {
+		o.addSource = true
+	}
+}
+
+// defaultLogHandlerOptions leaves level unset so that it is read from the
+// environment variable when the handler is created.
+func defaultLogHandlerOptions() *LogHandlerOptions {
+	return &LogHandlerOptions{
+		levelEnv:   DefaultLogLevelEnvironmentVariable,
+		maxSize:    defaultLogMaxSize,
+		maxBackups: defaultLogMaxBackups,
+	}
+}
+
+// NewLogHandler returns a handler writing to the log file in the specified
+// path; the file should be closed by calling Close on the handler
+func NewLogHandler(logFilePath string, opts ...LogHandlerOption) (*LogHandler, error) {
+	options := defaultLogHandlerOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	level := options.level
+	if level == nil {
+		level = GetLogLevelFromEnvironment(options.levelEnv, slog.LevelInfo)
+	}
+
+	file, err := OpenRotatingFile(logFilePath, options.maxSize, options.maxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	handlerOptions := &slog.HandlerOptions{
+		Level:     level,
+		AddSource: options.addSource,
+	}
+	h := &LogHandler{
+		handlers: []slog.Handler{slog.NewTextHandler(file, handlerOptions)},
+		file:     file,
+	}
+	if options.buffer != nil {
+		h.handlers = append(h.handlers, slog.NewTextHandler(options.buffer, handlerOptions))
+	}
+	return h, nil
+}
+
+// SetupSlogFile sets the default slog logger to write to the log file in the
+// specified path and returns the handler which should be closed on exit
+func SetupSlogFile(logFilePath string, opts ...LogHandlerOption) (*LogHandler, error) {
+	h, err := NewLogHandler(logFilePath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(slog.New(h))
+	return h, nil
+}
+
+// GetLogLevelFromEnvironment returns the log level set in the specified
+// environment variable (such as debug, info, warn, error or DEBUG+2) or
+// fallback if the variable is not set or invalid
+func GetLogLevelFromEnvironment(name string, fallback slog.Level) slog.Level {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return fallback
+	}
+	return level
+}
+
+// Enabled implements slog.Handler
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements slog.Handler
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs implements slog.Handler
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &LogHandler{handlers: handlers, file: h.file}
+}
+
+// WithGroup implements slog.Handler
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &LogHandler{handlers: handlers, file: h.file}
+}
+
+// Close closes the underlying log file
+func (h *LogHandler) Close() error {
+	return h.file.Close()
+}
+
+// RotatingFile is an io.WriteCloser which renames the file to path.1 (and
+// existing backups to path.2, path.3 and so on) once it reaches its maximum
+// size
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// OpenRotatingFile opens or creates the file in the specified path for
+// appending; maxSize of zero or less disables rotation
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	f := &RotatingFile{
+		path:       filepath.Clean(path),
+		maxSize:    maxSize,
+		maxBackups: max(maxBackups, 0),
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write implements io.Writer
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	var rotateErr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		rotateErr = f.rotate()
+		if f.file == nil {
+			return 0, rotateErr
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, errors.Join(rotateErr, err)
+}
+
+// Close implements io.Closer
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate renames the log file to its first backup and opens a new one; if
+// the renaming fails, the log file is reopened so that writing continues and
+// the rotation is retried only after another maxSize bytes are written
+func (f *RotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		err = fmt.Errorf("failed to close log file: %w", err)
+	} else {
+		err = f.renameBackups()
+	}
+	if openErr := f.open(); openErr != nil {
+		return errors.Join(err, openErr)
+	}
+	if err != nil {
+		f.size = 0
+	}
+	return err
+}
+
+func (f *RotatingFile) renameBackups() error {
+	if f.maxBackups == 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove log file: %w", err)
+		}
+		return nil
+	}
+
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(f.backupPath(i), f.backupPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	if err := os.Rename(f.path, f.backupPath(1)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return nil
+}
+
+func (f *RotatingFile) backupPath(index int) string {
+	return fmt.Sprintf("%s.%d", f.path, index)
+}
+
+// LogBuffer keeps the most recent lines written to it so that they can be
+// tailed by a LogPane
+type LogBuffer struct {
+	mu       sync.Mutex
+	lines    []string
+	capacity int
+	partial  string
+	notify   chan struct{}
+}
+
+// NewLogBuffer returns a buffer keeping the specified number of lines
+// (default: 500)
+func NewLogBuffer(capacity int) *LogBuffer {
+	if capacity <= 0 {
+		capacity = defaultLogBufferLines
+	}
+	return &LogBuffer{
+		capacity: capacity,
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// Write implements io.Writer
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	text := b.partial + string(p)
+	lines := strings.Split(text, "\n")
+	b.partial = lines[len(lines)-1]
+	b.lines = append(b.lines, lines[:len(lines)-1]...)
+	if len(b.lines) > b.capacity {
+		b.lines = append([]string(nil), b.lines[len(b.lines)-b.capacity:]...)
+	}
+	b.mu.Unlock()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// Lines returns a copy of the lines in the buffer
+func (b *LogBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.lines...)
+}
+
+// LogUpdatedMsg is sent to a LogPane when new lines are written to its
+// buffer
+type LogUpdatedMsg struct{}
+
+// LogPane is a Bubble Tea model which tails the lines of a LogBuffer. It is
+// meant to be embedded in another model, which forwards messages to it and
+// sets its size.
+//
+// Key bindings:
+//
+//	pgup, pgdown  scroll the pane
+//	end           follow the latest lines
+type LogPane struct {
+	buffer *LogBuffer
+	lines  []string
+	offset int
+	follow bool
+	width  int
+	height int
+}
+
+// NewLogPane returns a pane tailing the specified buffer
+func NewLogPane(buffer *LogBuffer, width int, height int) *LogPane {
+	p := &LogPane{
+		buffer: buffer,
+		follow: true,
+	}
+	p.SetSize(width, height)
+	p.refresh()
+	return p
+}
+
+// SetSize sets the dimension of the pane
+func (p *LogPane) SetSize(width int, height int) {
+	p.width = max(width, 1)
+	p.height = max(height, 1)
+	p.clampOffset()
+}
+
+// Init implements tea.Model
+func (p *LogPane) Init() tea.Cmd {
+	return p.waitForLog()
+}
+
+// Update implements tea.Model
+func (p *LogPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case LogUpdatedMsg:
+		p.refresh()
+		return p, p.waitForLog()
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyPgUp:
+			p.follow = false
+			p.offset -= p.height
+			p.clampOffset()
+		case tea.KeyPgDown:
+			p.offset += p.height
+			p.clampOffset()
+			p.follow = p.offset == p.maxOffset()
+		case tea.KeyEnd:
+			p.follow = true
+			p.offset = p.maxOffset()
+		}
+	}
+	return p, nil
+}
+
+// View implements tea.Model
+func (p *LogPane) View() string {
+	lines := make([]string, p.height)
+	for i := range lines {
+		if p.offset+i < len(p.lines) {
+			lines[i] = strings.TrimRight(fitCell(p.lines[p.offset+i], p.width), " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *LogPane) waitForLog() tea.Cmd {
+	return func() tea.Msg {
+		<-p.buffer.notify
+		return LogUpdatedMsg{}
+	}
+}
+
+func (p *LogPane) refresh() {
+	p.lines = p.buffer.Lines()
+	if p.follow {
+		p.offset = p.maxOffset()
+	}
+	p.clampOffset()
+}
+
+func (p *LogPane) maxOffset() int {
+	return max(len(p.lines)-p.height, 0)
+}
+
+func (p *LogPane) clampOffset() {
+	p.offset = min(max(p.offset, 0), p.maxOffset())
+}
@@ -0,0 +1,340 @@
+package bubbleteahelper
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewLogHandler(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "debug.log")
+
+	h, err := NewLogHandler(logPath, WithLogLevel(slog.LevelDebug))
+	if err != nil {
+		t.Fatalf("NewLogHandler() error: %v", err)
+	}
+	logger := slog.New(h)
+	logger.Debug("debug message", slog.String("key", "value"))
+	logger.With(slog.String("component", "auth")).WithGroup("token").Info("refreshed", slog.Int("expires_in", 3600))
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("unable to read log file: %v", err)
+	}
+	for _, want := range []string{
+		`level=DEBUG msg="debug message" key=value`,
+		"component=auth token.expires_in=3600",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("log file = %q, should contain %q", content, want)
+		}
+	}
+}
+
+func TestNewLogHandlerLevelFromEnvironment(t *testing.T) {
+	t.Setenv("TEST_TUI_LOG_LEVEL", "warn")
+	logPath := filepath.Join(t.TempDir(), "debug.log")
+
+	h, err := NewLogHandler(logPath, WithLogLevelEnvironmentVariable("TEST_TUI_LOG_LEVEL"))
+	if err != nil {
+		t.Fatalf("NewLogHandler() error: %v", err)
+	}
+	defer h.Close()
+	logger := slog.New(h)
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	content, _ := os.ReadFile(logPath)
+	if strings.Contains(string(content), "hidden") {
+		t.Errorf("log file = %q, should not contain info record", content)
+	}
+	if !strings.Contains(string(content), "shown") {
+		t.Errorf("log file = %q, should contain warn record", content)
+	}
+}
+
+func TestNewLogHandlerInvalidPath(t *testing.T) {
+	_, err := NewLogHandler("/nonexistent/directory/path/test.log")
+	if err == nil {
+		t.Fatal("NewLogHandler() with invalid path should return error")
+	}
+	if !strings.Contains(err.Error(), "failed to open log file") {
+		t.Errorf("error = %v, should contain 'failed to open log file'", err)
+	}
+}
+
+func TestNewLogHandlerWithBuffer(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "debug.log")
+	buffer := NewLogBuffer(10)
+
+	h, err := NewLogHandler(logPath, WithLogLevel(slog.LevelInfo), WithLogBuffer(buffer))
+	if err != nil {
+		t.Fatalf("NewLogHandler() error: %v", err)
+	}
+	defer h.Close()
+	logger := slog.New(h)
+	logger.Info("first")
+	logger.Info("second")
+
+	lines := buffer.Lines()
+	if len(lines) != 2 {
+		t.Fatalf("len(Lines()) = %d, want 2", len(lines))
+	}
+	if !strings.Contains(lines[1], "msg=second") {
+		t.Errorf("Lines()[1] = %q, should contain second record", lines[1])
+	}
+}
+
+func TestSetupSlogFile(t *testing.T) {
+	original := slog.Default()
+	defer slog.SetDefault(original)
+	logPath := filepath.Join(t.TempDir(), "debug.log")
+
+	h, err := SetupSlogFile(logPath, WithLogLevel(slog.LevelInfo))
+	if err != nil {
+		t.Fatalf("SetupSlogFile() error: %v", err)
+	}
+	slog.Info("from default logger")
+	_ = h.Close()
+
+	content, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(content), "from default logger") {
+		t.Errorf("log file = %q, should contain record from default logger", content)
+	}
+}
+
+func TestGetLogLevelFromEnvironment(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  slog.Level
+	}{
+		{"unset", "", slog.LevelInfo},
+		{"debug", "debug", slog.LevelDebug},
+		{"upper case", "ERROR", slog.LevelError},
+		{"offset", "DEBUG+2", slog.LevelDebug + 2},
+		{"invalid", "verbose", slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_LEVEL", tt.value)
+			if got := GetLogLevelFromEnvironment("TEST_LEVEL", slog.LevelInfo); got != tt.want {
+				t.Errorf("GetLogLevelFromEnvironment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "rotate.log")
+
+	f, err := OpenRotatingFile(logPath, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := fmt.Fprintf(f, "line %d\n", i); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	expected := map[string]string{
+		logPath:        "line 3\n",
+		logPath + ".1": "line 2\n",
+		logPath + ".2": "line 1\n",
+	}
+	for path, want := range expected {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unable to read %s: %v", path, err)
+		}
+		if string(content) != want {
+			t.Errorf("content of %s = %q, want %q", path, content, want)
+		}
+	}
+	if _, err := os.Stat(logPath + ".3"); !os.IsNotExist(err) {
+		t.Error("backups beyond the maximum should be removed")
+	}
+}
+
+func TestRotatingFileWithoutBackups(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "rotate.log")
+
+	f, err := OpenRotatingFile(logPath, 10, 0)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	_, _ = f.Write([]byte("first line\n"))
+	_, _ = f.Write([]byte("second\n"))
+	_ = f.Close()
+
+	content, _ := os.ReadFile(logPath)
+	if string(content) != "second\n" {
+		t.Errorf("content = %q, want %q", content, "second\n")
+	}
+	if _, err := os.Stat(logPath + ".1"); !os.IsNotExist(err) {
+		t.Error("no backup should be created")
+	}
+}
+
+func TestRotatingFileRotationFailure(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "rotate.log")
+	// a non-empty directory in place of the backup cannot be replaced
+	if err := os.MkdirAll(filepath.Join(logPath+".1", "dir"), 0700); err != nil {
+		t.Fatalf("unable to create directory: %v", err)
+	}
+
+	f, err := OpenRotatingFile(logPath, 10, 1)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	if _, err := f.Write([]byte("first line\n")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	if _, err := f.Write([]byte("second\n")); err == nil {
+		t.Error("Write() should return error of the failed rotation")
+	}
+	if _, err := f.Write([]byte("3\n")); err != nil {
+		t.Errorf("Write() after the failed rotation error: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	content, _ := os.ReadFile(logPath)
+	want := "first line\nsecond\n3\n"
+	if string(content) != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+}
+
+func TestRotatingFileAppendsToExistingFile(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "rotate.log")
+	if err := os.WriteFile(logPath, []byte("existing\n"), 0600); err != nil {
+		t.Fatalf("unable to create file: %v", err)
+	}
+
+	f, err := OpenRotatingFile(logPath, 0, 1)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	_, _ = f.Write([]byte("appended\n"))
+	_ = f.Close()
+
+	content, _ := os.ReadFile(logPath)
+	if string(content) != "existing\nappended\n" {
+		t.Errorf("content = %q, want appended content", content)
+	}
+}
+
+func TestRotatingFileWriteAfterClose(t *testing.T) {
+	f, err := OpenRotatingFile(filepath.Join(t.TempDir(), "closed.log"), 0, 0)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	_ = f.Close()
+
+	if _, err := f.Write([]byte("x")); err == nil {
+		t.Error("Write() after Close() should return error")
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("second Close() error: %v", err)
+	}
+}
+
+func TestOpenRotatingFileEmptyPath(t *testing.T) {
+	if _, err := OpenRotatingFile("", 0, 0); err == nil {
+		t.Error("OpenRotatingFile() with empty path should return error")
+	}
+}
+
+func TestLogBuffer(t *testing.T) {
+	b := NewLogBuffer(2)
+
+	_, _ = b.Write([]byte("one\ntw"))
+	if got := b.Lines(); len(got) != 1 || got[0] != "one" {
+		t.Errorf("Lines() = %v, want [one]", got)
+	}
+	_, _ = b.Write([]byte("o\nthree\n"))
+	if got := b.Lines(); len(got) != 2 || got[0] != "two" || got[1] != "three" {
+		t.Errorf("Lines() = %v, want [two three]", got)
+	}
+}
+
+func TestLogPane(t *testing.T) {
+	b := NewLogBuffer(0)
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(b, "line %d\n", i)
+	}
+
+	p := NewLogPane(b, 20, 2)
+	if got := p.View(); got != "line 3\nline 4" {
+		t.Errorf("View() = %q, want tail of buffer", got)
+	}
+
+	cmd := p.Init()
+	msg := cmd()
+	if _, ok := msg.(LogUpdatedMsg); !ok {
+		t.Fatalf("Init() command returned %T, want LogUpdatedMsg", msg)
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	if got := p.View(); got != "line 1\nline 2" {
+		t.Errorf("View() after pgup = %q", got)
+	}
+
+	// new lines should not move the pane when it is not following
+	fmt.Fprintln(b, "line 5")
+	p.Update(LogUpdatedMsg{})
+	if got := p.View(); got != "line 1\nline 2" {
+		t.Errorf("View() after update without follow = %q", got)
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if got := p.View(); got != "line 4\nline 5" {
+		t.Errorf("View() after end = %q", got)
+	}
+
+	fmt.Fprintln(b, "line 6")
+	p.Update(LogUpdatedMsg{})
+	if got := p.View(); got != "line 5\nline 6" {
+		t.Errorf("View() after update with follow = %q", got)
+	}
+}
+
+func TestLogPaneTruncatesLongLines(t *testing.T) {
+	b := NewLogBuffer(0)
+	fmt.Fprintln(b, "a very long line of log")
+
+	p := NewLogPane(b, 10, 1)
+	if got := p.View(); got != "a very lo…" {
+		t.Errorf("View() = %q, want truncated line", got)
+	}
+}
+
+func BenchmarkLogHandler(b *testing.B) {
+	logPath := filepath.Join(b.TempDir(), "bench.log")
+	h, err := NewLogHandler(logPath, WithLogLevel(slog.LevelInfo), WithLogBuffer(NewLogBuffer(0)))
+	if err != nil {
+		b.Fatalf("NewLogHandler() error: %v", err)
+	}
+	defer h.Close()
+	logger := slog.New(h)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("bench", slog.Int("i", i))
+	}
+}
@@ -0,0 +1,379 @@
+package bubbleteahelper
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	defaultPickerPrompt = "> "
+	minPreviewWidth     = 20
+)
+
+// ErrPickerCancelled is returned by Pick when user quits the picker without
+// choosing any items
+var ErrPickerCancelled = errors.New("picker cancelled")
+
+// Picker is a generic Bubble Tea model which lets user choose one or more
+// items by fuzzy matching a query against the rendered items.
+//
+// Key bindings:
+//
+//	up/ctrl+p, down/ctrl+n  move between matched items
+//	tab                     toggle selection of an item (multi-select only)
+//	enter                   confirm the choice
+//	esc, ctrl+c             cancel
+type Picker[T any] struct {
+	items    []T
+	labels   []string
+	render   func(T) string
+	preview  func(T) string
+	prompt   string
+	multi    bool
+	limit    int
+	query    string
+	matches  []pickerMatch
+	selected map[int]bool
+
+	cursor int
+	offset int
+	width  int
+	height int
+
+	confirmed bool
+	cancelled bool
+}
+
+// PickerOption is a functional option for NewPicker.
+type PickerOption[T any] func(*Picker[T])
+
+type pickerMatch struct {
+	index int
+	score int
+}
+
+// WithRenderer sets the function rendering an item to a single line of text
+// which is used for both display and matching (default: fmt.Sprint).
+func WithRenderer[T any](render func(T) string) PickerOption[T] {
+	return func(p *Picker[T]) {
+		p.render = render
+	}
+}
+
+// WithPreview sets the function rendering the preview pane of the item under
+// cursor.
+func WithPreview[T any](preview func(T) string) PickerOption[T] {
+	return func(p *Picker[T]) {
+		p.preview = preview
+	}
+}
+
+// WithPrompt sets the prompt shown in front of the query.
+func WithPrompt[T any](prompt string) PickerOption[T] {
+	return func(p *Picker[T]) {
+		p.prompt = prompt
+	}
+}
+
+// WithMultiSelect allows user to choose more than one item; limit caps the
+// number of items which can be selected (0 for unlimited).
+func WithMultiSelect[T any](limit int) PickerOption[T] {
+	return func(p *Picker[T]) {
+		p.multi = true
+		p.limit = limit
+	}
+}
+
+// NewPicker returns a picker model of the specified items
+func NewPicker[T any](items []T, opts ...PickerOption[T]) *Picker[T] {
+	p := &Picker[T]{
+		items:    items,
+		render:   func(item T) string { return fmt.Sprint(item) },
+		prompt:   defaultPickerPrompt,
+		selected: map[int]bool{},
+		width:    defaultTableWidth,
+		height:   defaultTableHeight,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.labels = make([]string, len(items))
+	for i, item := range items {
+		p.labels[i] = p.render(item)
+	}
+	p.filter()
+	return p
+}
+
+// Pick runs a picker of the specified items and returns the chosen items
+// once the program exits
+func Pick[T any](items []T, opts ...PickerOption[T]) ([]T, error) {
+	model, err := tea.NewProgram(NewPicker(items, opts...)).Run()
+	if err != nil {
+		return nil, fmt.Errorf("unable to run picker: %w", err)
+	}
+	picker, ok := model.(*Picker[T])
+	if !ok {
+		return nil, fmt.Errorf("unexpected model type %T", model)
+	}
+	if !picker.confirmed {
+		return nil, ErrPickerCancelled
+	}
+	return picker.Chosen(), nil
+}
+
+// Init implements tea.Model
+func (p *Picker[T]) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (p *Picker[T]) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+		p.ensureVisible()
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			p.cancelled = true
+			return p, tea.Quit
+		case tea.KeyEnter:
+			if len(p.Chosen()) == 0 {
+				return p, nil
+			}
+			p.confirmed = true
+			return p, tea.Quit
+		case tea.KeyUp, tea.KeyCtrlP:
+			p.moveCursor(-1)
+		case tea.KeyDown, tea.KeyCtrlN:
+			p.moveCursor(1)
+		case tea.KeyPgUp:
+			p.moveCursor(-p.listHeight())
+		case tea.KeyPgDown:
+			p.moveCursor(p.listHeight())
+		case tea.KeyTab:
+			p.toggle()
+		case tea.KeyBackspace:
+			if p.query != "" {
+				runes := []rune(p.query)
+				p.query = string(runes[:len(runes)-1])
+				p.filter()
+			}
+		case tea.KeySpace:
+			p.query += " "
+			p.filter()
+		case tea.KeyRunes:
+			p.query += string(msg.Runes)
+			p.filter()
+		}
+	}
+	return p, nil
+}
+
+// View implements tea.Model
+func (p *Picker[T]) View() string {
+	listWidth := p.width
+	var previewLines []string
+	if p.preview != nil && p.width >= 2*minPreviewWidth {
+		listWidth = p.width / 2
+		if len(p.matches) > 0 {
+			previewLines = strings.Split(p.preview(p.items[p.matches[p.cursor].index]), "\n")
+		}
+	}
+
+	lines := []string{
+		fitCell(p.prompt+p.query, listWidth),
+	}
+	height := p.listHeight()
+	for i := p.offset; i < p.offset+height; i++ {
+		var line string
+		if i < len(p.matches) {
+			line = p.itemLine(i)
+		}
+		lines = append(lines, fitCell(line, listWidth))
+	}
+	lines = append(lines, fitCell(p.statusLine(), listWidth))
+
+	if p.preview != nil && listWidth != p.width {
+		previewWidth := p.width - listWidth - len(columnSeparator)
+		for i := range lines {
+			var previewLine string
+			if i < len(previewLines) {
+				previewLine = previewLines[i]
+			}
+			lines[i] += columnSeparator + fitCell(previewLine, previewWidth)
+		}
+	}
+
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Chosen returns the items chosen by user; in multi-select mode these are
+// the toggled items in their original order or the item under cursor if
+// nothing is toggled
+func (p *Picker[T]) Chosen() []T {
+	var chosen []T
+	if p.multi {
+		for i, item := range p.items {
+			if p.selected[i] {
+				chosen = append(chosen, item)
+			}
+		}
+	}
+	if len(chosen) == 0 && len(p.matches) > 0 {
+		chosen = append(chosen, p.items[p.matches[p.cursor].index])
+	}
+	return chosen
+}
+
+// Confirmed returns true if user has confirmed the choice
+func (p *Picker[T]) Confirmed() bool {
+	return p.confirmed
+}
+
+// Cancelled returns true if user has quit without confirming the choice
+func (p *Picker[T]) Cancelled() bool {
+	return p.cancelled
+}
+
+func (p *Picker[T]) itemLine(i int) string {
+	match := p.matches[i]
+	var builder strings.Builder
+	if i == p.cursor {
+		builder.WriteString("> ")
+	} else {
+		builder.WriteString("  ")
+	}
+	if p.multi {
+		if p.selected[match.index] {
+			builder.WriteString("[x] ")
+		} else {
+			builder.WriteString("[ ] ")
+		}
+	}
+	builder.WriteString(p.labels[match.index])
+	return builder.String()
+}
+
+func (p *Picker[T]) statusLine() string {
+	status := fmt.Sprintf("%d/%d", len(p.matches), len(p.items))
+	if p.multi {
+		status += fmt.Sprintf(" (%d selected)", len(p.selected))
+	}
+	return status
+}
+
+func (p *Picker[T]) listHeight() int {
+	// prompt and status lines
+	return max(p.height-2, 1)
+}
+
+func (p *Picker[T]) moveCursor(delta int) {
+	if len(p.matches) == 0 {
+		p.cursor = 0
+		return
+	}
+	p.cursor = min(max(p.cursor+delta, 0), len(p.matches)-1)
+	p.ensureVisible()
+}
+
+func (p *Picker[T]) ensureVisible() {
+	height := p.listHeight()
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+height {
+		p.offset = p.cursor - height + 1
+	}
+}
+
+func (p *Picker[T]) toggle() {
+	if !p.multi || len(p.matches) == 0 {
+		return
+	}
+	index := p.matches[p.cursor].index
+	switch {
+	case p.selected[index]:
+		delete(p.selected, index)
+	case p.limit > 0 && len(p.selected) >= p.limit:
+		return
+	default:
+		p.selected[index] = true
+	}
+	p.moveCursor(1)
+}
+
+// filter re-computes matched items of the current query ordered by score
+func (p *Picker[T]) filter() {
+	p.matches = p.matches[:0]
+	for i, label := range p.labels {
+		if score, ok := fuzzyMatch(p.query, label); ok {
+			p.matches = append(p.matches, pickerMatch{index: i, score: score})
+		}
+	}
+	if p.query != "" {
+		slices.SortStableFunc(p.matches, func(a, b pickerMatch) int {
+			return b.score - a.score
+		})
+	}
+	p.cursor = 0
+	p.offset = 0
+}
+
+// fuzzyMatch returns true if all runes of query appear in text in order
+// (case-insensitive) together with a score which favours consecutive runes,
+// runes at word boundaries and matches close to the start of text
+func fuzzyMatch(query string, text string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+
+	queryRunes := []rune(strings.ToLower(query))
+	textRunes := []rune(text)
+	positions := make([]int, 0, len(queryRunes))
+	score := 0
+	q := 0
+	for i, r := range textRunes {
+		if q == len(queryRunes) {
+			break
+		}
+		if unicode.ToLower(r) != queryRunes[q] {
+			continue
+		}
+
+		score++
+		switch {
+		case i == 0:
+			score += 8
+		case !unicode.IsLetter(textRunes[i-1]) && !unicode.IsDigit(textRunes[i-1]):
+			score += 6
+		case unicode.IsUpper(r) && unicode.IsLower(textRunes[i-1]):
+			score += 4
+		}
+		if len(positions) > 0 && positions[len(positions)-1] == i-1 {
+			score += 5
+		}
+		positions = append(positions, i)
+		q++
+	}
+	if q != len(queryRunes) {
+		return 0, false
+	}
+
+	// penalise leading unmatched runes and long text
+	score -= min(positions[0], 10)
+	score -= min(runewidth.StringWidth(text)/10, 5)
+	return score, true
+}
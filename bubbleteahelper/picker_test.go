@@ -0,0 +1,256 @@
+package bubbleteahelper
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type testCalendar struct {
+	ID      string
+	Summary string
+}
+
+func newTestCalendars() []testCalendar {
+	return []testCalendar{
+		{ID: "primary", Summary: "Personal"},
+		{ID: "work@example.com", Summary: "Work Meetings"},
+		{ID: "holidays", Summary: "Public Holidays"},
+		{ID: "family", Summary: "Family"},
+	}
+}
+
+func sendPickerKeys[T any](p *Picker[T], msgs ...tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	for _, msg := range msgs {
+		_, cmd = p.Update(msg)
+	}
+	return cmd
+}
+
+func typeQuery(query string) []tea.KeyMsg {
+	var msgs []tea.KeyMsg
+	for _, r := range query {
+		if r == ' ' {
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeySpace})
+			continue
+		}
+		msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return msgs
+}
+
+func TestNewPickerDefaults(t *testing.T) {
+	p := NewPicker([]int{3, 1, 2})
+
+	if len(p.matches) != 3 {
+		t.Fatalf("len(matches) = %d, want 3", len(p.matches))
+	}
+	if got := p.Chosen(); len(got) != 1 || got[0] != 3 {
+		t.Errorf("Chosen() = %v, want [3]", got)
+	}
+	view := p.View()
+	if !strings.HasPrefix(view, strings.TrimSpace(defaultPickerPrompt)+"\n") {
+		t.Errorf("View() = %q, should start with default prompt", view)
+	}
+	if !strings.Contains(view, "3/3") {
+		t.Errorf("View() = %q, should contain match count", view)
+	}
+}
+
+func TestPickerRendererAndFilter(t *testing.T) {
+	p := NewPicker(
+		newTestCalendars(),
+		WithRenderer(func(c testCalendar) string { return c.Summary }),
+		WithPrompt[testCalendar]("calendar: "),
+	)
+
+	sendPickerKeys(p, typeQuery("hol")...)
+	if len(p.matches) != 1 {
+		t.Fatalf("len(matches) = %d, want 1", len(p.matches))
+	}
+	if got := p.Chosen(); got[0].ID != "holidays" {
+		t.Errorf("Chosen() = %v, want holidays", got)
+	}
+	if !strings.HasPrefix(p.View(), "calendar: hol") {
+		t.Errorf("View() = %q, should start with prompt and query", p.View())
+	}
+
+	sendPickerKeys(p, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace})
+	if len(p.matches) != 4 {
+		t.Errorf("len(matches) after clearing query = %d, want 4", len(p.matches))
+	}
+}
+
+func TestPickerRanksBetterMatchesFirst(t *testing.T) {
+	p := NewPicker([]string{"feature/web-meeting", "wm", "work-meetings"})
+
+	sendPickerKeys(p, typeQuery("wm")...)
+	if got := p.Chosen()[0]; got != "wm" {
+		t.Errorf("Chosen() = %v, want wm", got)
+	}
+}
+
+func TestPickerSingleSelect(t *testing.T) {
+	p := NewPicker([]string{"main", "develop", "feature/picker"})
+
+	cmd := sendPickerKeys(p, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || cmd() != tea.Quit() {
+		t.Fatal("enter should quit the picker")
+	}
+	if !p.Confirmed() || p.Cancelled() {
+		t.Errorf("Confirmed() = %v, Cancelled() = %v", p.Confirmed(), p.Cancelled())
+	}
+	if got := p.Chosen(); len(got) != 1 || got[0] != "develop" {
+		t.Errorf("Chosen() = %v, want [develop]", got)
+	}
+}
+
+func TestPickerMultiSelect(t *testing.T) {
+	p := NewPicker([]string{"a", "b", "c", "d"}, WithMultiSelect[string](0))
+
+	// tab toggles and moves to the next item
+	sendPickerKeys(p, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyTab})
+	if got := p.Chosen(); len(got) != 2 || got[0] != "b" || got[1] != "d" {
+		t.Errorf("Chosen() = %v, want [b d]", got)
+	}
+	if !strings.Contains(p.View(), "[x] b") {
+		t.Errorf("View() = %q, should mark selected items", p.View())
+	}
+	if !strings.Contains(p.View(), "(2 selected)") {
+		t.Errorf("View() = %q, should show selected count", p.View())
+	}
+
+	sendPickerKeys(p, tea.KeyMsg{Type: tea.KeyTab})
+	if got := p.Chosen(); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Chosen() after untoggle = %v, want [b]", got)
+	}
+}
+
+func TestPickerMultiSelectLimit(t *testing.T) {
+	p := NewPicker([]string{"a", "b", "c"}, WithMultiSelect[string](1))
+
+	sendPickerKeys(p, tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyTab})
+	if got := p.Chosen(); len(got) != 1 || got[0] != "a" {
+		t.Errorf("Chosen() = %v, want [a]", got)
+	}
+}
+
+func TestPickerCancel(t *testing.T) {
+	for _, key := range []tea.KeyType{tea.KeyEsc, tea.KeyCtrlC} {
+		p := NewPicker([]string{"a"})
+		cmd := sendPickerKeys(p, tea.KeyMsg{Type: key})
+		if cmd == nil || cmd() != tea.Quit() {
+			t.Fatalf("key %v should quit the picker", key)
+		}
+		if !p.Cancelled() || p.Confirmed() {
+			t.Errorf("Confirmed() = %v, Cancelled() = %v", p.Confirmed(), p.Cancelled())
+		}
+	}
+}
+
+func TestPickerEnterWithoutMatches(t *testing.T) {
+	p := NewPicker([]string{"a", "b"})
+
+	cmd := sendPickerKeys(p, append(typeQuery("zz"), tea.KeyMsg{Type: tea.KeyEnter})...)
+	if cmd != nil {
+		t.Error("enter without matches should not quit")
+	}
+	if p.Confirmed() {
+		t.Error("picker should not be confirmed without matches")
+	}
+}
+
+func TestPickerPreview(t *testing.T) {
+	p := NewPicker(
+		newTestCalendars(),
+		WithRenderer(func(c testCalendar) string { return c.Summary }),
+		WithPreview(func(c testCalendar) string { return "id: " + c.ID + "\nsummary: " + c.Summary }),
+	)
+	p.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+
+	sendPickerKeys(p, tea.KeyMsg{Type: tea.KeyDown})
+	view := p.View()
+	if !strings.Contains(view, "id: work@example.com") {
+		t.Errorf("View() = %q, should contain preview of item under cursor", view)
+	}
+	if len(strings.Split(view, "\n")) != 10 {
+		t.Errorf("View() should have 10 lines, got %d", len(strings.Split(view, "\n")))
+	}
+}
+
+func TestPickerPreviewHiddenOnNarrowScreen(t *testing.T) {
+	p := NewPicker([]string{"a"}, WithPreview(func(s string) string { return "preview of " + s }))
+	p.Update(tea.WindowSizeMsg{Width: 30, Height: 5})
+
+	if strings.Contains(p.View(), "preview of") {
+		t.Errorf("View() = %q, should not contain preview", p.View())
+	}
+}
+
+func TestPickerScrolling(t *testing.T) {
+	items := make([]int, 20)
+	for i := range items {
+		items[i] = i
+	}
+	p := NewPicker(items)
+	p.Update(tea.WindowSizeMsg{Width: 40, Height: 5})
+
+	sendPickerKeys(p, tea.KeyMsg{Type: tea.KeyPgDown}, tea.KeyMsg{Type: tea.KeyDown})
+	if p.cursor != 4 || p.offset != 2 {
+		t.Errorf("cursor = %d, offset = %d, want 4 and 2", p.cursor, p.offset)
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		text    string
+		matched bool
+	}{
+		{"empty query", "", "anything", true},
+		{"prefix", "mai", "main", true},
+		{"subsequence", "fpk", "feature/picker", true},
+		{"case insensitive", "WM", "work meetings", true},
+		{"out of order", "ba", "ab", false},
+		{"missing rune", "abc", "ab", false},
+		{"unicode", "日本", "日本語", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := fuzzyMatch(tt.query, tt.text); ok != tt.matched {
+				t.Errorf("fuzzyMatch(%q, %q) matched = %v, want %v", tt.query, tt.text, ok, tt.matched)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatchScore(t *testing.T) {
+	prefix, _ := fuzzyMatch("dev", "develop")
+	middle, _ := fuzzyMatch("dev", "feature/dev")
+	scattered, _ := fuzzyMatch("dev", "added review")
+
+	if prefix <= middle {
+		t.Errorf("prefix score %d should be greater than middle score %d", prefix, middle)
+	}
+	if middle <= scattered {
+		t.Errorf("middle score %d should be greater than scattered score %d", middle, scattered)
+	}
+}
+
+func BenchmarkPickerFilter(b *testing.B) {
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = strings.Repeat("branch-name-", i%5+1)
+	}
+	p := NewPicker(items)
+	p.query = "bnm"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.filter()
+	}
+}
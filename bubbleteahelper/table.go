@@ -0,0 +1,580 @@
+package bubbleteahelper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alexhokl/helper/database"
+	"github.com/aymanbagabas/go-osc52"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	defaultTableWidth     = 80
+	defaultTableHeight    = 24
+	defaultMaxColumnWidth = 40
+	minColumnWidth        = 3
+	columnSeparator       = " | "
+	cellEllipsis          = "…"
+)
+
+// TableModel is a Bubble Tea model which browses a database.TableData
+// interactively. It supports scrolling, column resizing, sorting by column,
+// incremental search, a row detail view and copying to clipboard via OSC52.
+//
+// Key bindings:
+//
+//	up/k, down/j, pgup, pgdown, home/g, end/G  move between rows
+//	left/h, right/l                            move between columns
+//	+/>, -/<                                   widen or narrow the selected column
+//	s                                          sort by the selected column (ascending, descending, none)
+//	/                                          search rows incrementally (enter to accept, esc to clear)
+//	enter                                      show details of the selected row
+//	y, Y                                       copy the selected cell or row to clipboard
+//	q, ctrl+c                                  quit
+type TableModel struct {
+	columns []string
+	values  [][]interface{}
+	cells   [][]string
+	widths  []int
+
+	// rows contains indices of values which are visible after filtering and
+	// sorting
+	rows []int
+
+	cursor       int
+	offset       int
+	column       int
+	columnOffset int
+
+	sortColumn     int
+	sortDescending bool
+
+	searching bool
+	query     string
+
+	detail       bool
+	detailOffset int
+
+	width  int
+	height int
+	status string
+
+	copyToClipboard func(string)
+}
+
+// NewTableModel returns a model browsing the specified table
+func NewTableModel(data *database.TableData) *TableModel {
+	m := &TableModel{
+		sortColumn:      -1,
+		width:           defaultTableWidth,
+		height:          defaultTableHeight,
+		copyToClipboard: osc52.Copy,
+	}
+	if data == nil {
+		m.applyView()
+		return m
+	}
+
+	m.columns = data.Columns
+	m.widths = make([]int, len(data.Columns))
+	for i, c := range data.Columns {
+		m.widths[i] = runewidth.StringWidth(c)
+	}
+	for _, r := range data.Rows {
+		vals := make([]interface{}, len(r))
+		cells := make([]string, len(r))
+		for i, c := range r {
+			vals[i] = getCellValue(c)
+			cells[i] = getCellString(vals[i])
+			if i < len(m.widths) {
+				m.widths[i] = max(m.widths[i], runewidth.StringWidth(cells[i]))
+			}
+		}
+		m.values = append(m.values, vals)
+		m.cells = append(m.cells, cells)
+	}
+	for i := range m.widths {
+		m.widths[i] = min(max(m.widths[i], minColumnWidth), defaultMaxColumnWidth)
+	}
+	m.applyView()
+	return m
+}
+
+// BrowseTable runs an interactive table viewer of the specified table in the
+// alternate screen of the terminal
+func BrowseTable(data *database.TableData) error {
+	if _, err := tea.NewProgram(NewTableModel(data), tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("unable to browse table: %w", err)
+	}
+	return nil
+}
+
+// Init implements tea.Model
+func (m *TableModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *TableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ensureVisible()
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.searching {
+			m.updateSearch(msg)
+			return m, nil
+		}
+		if m.detail {
+			return m, m.updateDetail(msg)
+		}
+		return m, m.updateTable(msg)
+	}
+	return m, nil
+}
+
+// View implements tea.Model
+func (m *TableModel) View() string {
+	if m.detail {
+		return m.detailView()
+	}
+	return m.tableView()
+}
+
+// SelectedRow returns the formatted cells of the selected row or nil if no
+// row is selected
+func (m *TableModel) SelectedRow() []string {
+	if len(m.rows) == 0 {
+		return nil
+	}
+	return m.cells[m.rows[m.cursor]]
+}
+
+func (m *TableModel) updateTable(msg tea.KeyMsg) tea.Cmd {
+	m.status = ""
+	switch msg.String() {
+	case "q", "esc":
+		return tea.Quit
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	case "pgup":
+		m.moveCursor(-m.bodyHeight())
+	case "pgdown":
+		m.moveCursor(m.bodyHeight())
+	case "home", "g":
+		m.moveCursor(-len(m.rows))
+	case "end", "G":
+		m.moveCursor(len(m.rows))
+	case "left", "h":
+		m.moveColumn(-1)
+	case "right", "l":
+		m.moveColumn(1)
+	case "+", ">":
+		m.resizeColumn(1)
+	case "-", "<":
+		m.resizeColumn(-1)
+	case "s":
+		m.toggleSort()
+	case "/":
+		m.searching = true
+	case "enter":
+		if len(m.rows) > 0 {
+			m.detail = true
+			m.detailOffset = 0
+		}
+	case "y":
+		m.copyCell()
+	case "Y":
+		m.copyRow()
+	}
+	return nil
+}
+
+func (m *TableModel) updateSearch(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.searching = false
+	case tea.KeyEsc:
+		m.searching = false
+		m.query = ""
+		m.applyView()
+	case tea.KeyBackspace:
+		if m.query != "" {
+			runes := []rune(m.query)
+			m.query = string(runes[:len(runes)-1])
+			m.applyView()
+		}
+	case tea.KeySpace:
+		m.query += " "
+		m.applyView()
+	case tea.KeyRunes:
+		m.query += string(msg.Runes)
+		m.applyView()
+	}
+}
+
+func (m *TableModel) updateDetail(msg tea.KeyMsg) tea.Cmd {
+	m.status = ""
+	switch msg.String() {
+	case "q":
+		return tea.Quit
+	case "esc", "enter":
+		m.detail = false
+	case "up", "k":
+		m.detailOffset = max(m.detailOffset-1, 0)
+	case "down", "j":
+		m.detailOffset = min(m.detailOffset+1, max(len(m.columns)-1, 0))
+	case "y":
+		m.copyCell()
+	case "Y":
+		m.copyRow()
+	}
+	return nil
+}
+
+func (m *TableModel) moveCursor(delta int) {
+	if len(m.rows) == 0 {
+		m.cursor = 0
+		return
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.rows)-1)
+	m.ensureVisible()
+}
+
+func (m *TableModel) moveColumn(delta int) {
+	if len(m.columns) == 0 {
+		return
+	}
+	m.column = min(max(m.column+delta, 0), len(m.columns)-1)
+	m.ensureVisible()
+}
+
+func (m *TableModel) resizeColumn(delta int) {
+	if len(m.widths) == 0 {
+		return
+	}
+	m.widths[m.column] = max(m.widths[m.column]+delta, minColumnWidth)
+	m.ensureVisible()
+}
+
+func (m *TableModel) toggleSort() {
+	if len(m.columns) == 0 {
+		return
+	}
+	switch {
+	case m.sortColumn != m.column:
+		m.sortColumn = m.column
+		m.sortDescending = false
+	case !m.sortDescending:
+		m.sortDescending = true
+	default:
+		m.sortColumn = -1
+		m.sortDescending = false
+	}
+	m.applyView()
+}
+
+func (m *TableModel) copyCell() {
+	row := m.SelectedRow()
+	if row == nil || m.column >= len(row) {
+		return
+	}
+	m.copyToClipboard(row[m.column])
+	m.status = fmt.Sprintf("copied cell of column %s", m.columns[m.column])
+}
+
+func (m *TableModel) copyRow() {
+	row := m.SelectedRow()
+	if row == nil {
+		return
+	}
+	m.copyToClipboard(strings.Join(row, "\t"))
+	m.status = "copied row"
+}
+
+// applyView re-computes visible rows according to the current search query
+// and sort order
+func (m *TableModel) applyView() {
+	query := strings.ToLower(m.query)
+	m.rows = m.rows[:0]
+	for i, cells := range m.cells {
+		if query == "" || containsQuery(cells, query) {
+			m.rows = append(m.rows, i)
+		}
+	}
+
+	if m.sortColumn >= 0 {
+		slices.SortStableFunc(m.rows, func(a, b int) int {
+			result := compareValues(
+				getValueAt(m.values[a], m.sortColumn),
+				getValueAt(m.values[b], m.sortColumn),
+			)
+			if m.sortDescending {
+				return -result
+			}
+			return result
+		})
+	}
+
+	m.cursor = min(m.cursor, max(len(m.rows)-1, 0))
+	m.ensureVisible()
+}
+
+func (m *TableModel) bodyHeight() int {
+	// header, separator and status lines
+	return max(m.height-3, 1)
+}
+
+func (m *TableModel) ensureVisible() {
+	bodyHeight := m.bodyHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+bodyHeight {
+		m.offset = m.cursor - bodyHeight + 1
+	}
+
+	if m.column < m.columnOffset {
+		m.columnOffset = m.column
+	}
+	for m.columnOffset < m.column && m.column >= m.columnOffset+len(m.visibleColumns()) {
+		m.columnOffset++
+	}
+}
+
+// visibleColumns returns indices of columns which fit in the width of the
+// screen starting from the current column offset
+func (m *TableModel) visibleColumns() []int {
+	var columns []int
+	used := 0
+	for i := m.columnOffset; i < len(m.columns); i++ {
+		width := m.widths[i]
+		if len(columns) > 0 {
+			width += len(columnSeparator)
+		}
+		if len(columns) > 0 && used+width > m.width {
+			break
+		}
+		used += width
+		columns = append(columns, i)
+	}
+	return columns
+}
+
+func (m *TableModel) tableView() string {
+	var builder strings.Builder
+	columns := m.visibleColumns()
+
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		name := m.columns[c]
+		if c == m.sortColumn {
+			if m.sortDescending {
+				name += " ▼"
+			} else {
+				name += " ▲"
+			}
+		}
+		if c == m.column {
+			name = "[" + name + "]"
+		}
+		headers[i] = fitCell(name, m.widths[c])
+	}
+	builder.WriteString("  " + strings.Join(headers, columnSeparator) + "\n")
+
+	separators := make([]string, len(columns))
+	for i, c := range columns {
+		separators[i] = strings.Repeat("-", m.widths[c])
+	}
+	builder.WriteString("  " + strings.Join(separators, strings.ReplaceAll(columnSeparator, " ", "-")) + "\n")
+
+	end := min(m.offset+m.bodyHeight(), len(m.rows))
+	for i := m.offset; i < end; i++ {
+		cells := m.cells[m.rows[i]]
+		line := make([]string, len(columns))
+		for j, c := range columns {
+			line[j] = fitCell(getStringAt(cells, c), m.widths[c])
+		}
+		prefix := "  "
+		if i == m.cursor {
+			prefix = "> "
+		}
+		builder.WriteString(prefix + strings.Join(line, columnSeparator) + "\n")
+	}
+
+	builder.WriteString(m.statusLine())
+	return builder.String()
+}
+
+func (m *TableModel) detailView() string {
+	var builder strings.Builder
+	row := m.SelectedRow()
+	nameWidth := 0
+	for _, c := range m.columns {
+		nameWidth = max(nameWidth, runewidth.StringWidth(c))
+	}
+
+	end := min(m.detailOffset+m.bodyHeight()+2, len(m.columns))
+	for i := m.detailOffset; i < end; i++ {
+		prefix := "  "
+		if i == m.column {
+			prefix = "> "
+		}
+		builder.WriteString(fmt.Sprintf(
+			"%s%s : %s\n",
+			prefix,
+			runewidth.FillRight(m.columns[i], nameWidth),
+			getStringAt(row, i),
+		))
+	}
+
+	status := fmt.Sprintf("row %d of %d (esc to go back, y/Y to copy)", m.cursor+1, len(m.rows))
+	if m.status != "" {
+		status = m.status
+	}
+	builder.WriteString(status)
+	return builder.String()
+}
+
+func (m *TableModel) statusLine() string {
+	if m.searching {
+		return fmt.Sprintf("/%s", m.query)
+	}
+	if m.status != "" {
+		return m.status
+	}
+	position := 0
+	if len(m.rows) > 0 {
+		position = m.cursor + 1
+	}
+	status := fmt.Sprintf("row %d of %d", position, len(m.rows))
+	if len(m.rows) != len(m.cells) {
+		status += fmt.Sprintf(" (filtered from %d)", len(m.cells))
+	}
+	if m.query != "" {
+		status += fmt.Sprintf(" search: %s", m.query)
+	}
+	return status
+}
+
+func containsQuery(cells []string, query string) bool {
+	for _, c := range cells {
+		if strings.Contains(strings.ToLower(c), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// fitCell pads or truncates the specified value to the specified width
+func fitCell(value string, width int) string {
+	value = strings.NewReplacer("\r\n", " ", "\n", " ", "\t", " ").Replace(value)
+	if runewidth.StringWidth(value) > width {
+		value = runewidth.Truncate(value, width, cellEllipsis)
+	}
+	return runewidth.FillRight(value, width)
+}
+
+func getCellValue(cell interface{}) interface{} {
+	if p, ok := cell.(*interface{}); ok {
+		if p == nil {
+			return nil
+		}
+		return *p
+	}
+	return cell
+}
+
+func getCellString(value interface{}) string {
+	return database.GetValue(&value)
+}
+
+func getValueAt(values []interface{}, index int) interface{} {
+	if index >= len(values) {
+		return nil
+	}
+	return values[index]
+}
+
+func getStringAt(cells []string, index int) string {
+	if index >= len(cells) {
+		return ""
+	}
+	return cells[index]
+}
+
+// compareValues compares cell values with awareness of numbers and time;
+// NULL values are sorted before any other values
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+
+	return strings.Compare(getCellString(a), getCellString(b))
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,347 @@
+package bubbleteahelper
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexhokl/helper/database"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newTestTableData() *database.TableData {
+	rows := [][]interface{}{
+		{int64(3), "charlie", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{int64(1), "alpha", nil},
+		{int64(20), "bravo", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	data := &database.TableData{
+		Columns: []string{"id", "name", "created_at"},
+	}
+	for _, r := range rows {
+		var row []interface{}
+		for _, c := range r {
+			cell := new(interface{})
+			*cell = c
+			row = append(row, cell)
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	return data
+}
+
+func sendKeys(m *TableModel, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "ctrl+c":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlC}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		_, cmd = m.Update(msg)
+	}
+	return cmd
+}
+
+func TestNewTableModel(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+
+	if len(m.rows) != 3 {
+		t.Fatalf("len(rows) = %d, want 3", len(m.rows))
+	}
+	if got := m.SelectedRow(); got[1] != "charlie" {
+		t.Errorf("SelectedRow() = %v, want charlie", got)
+	}
+	if m.widths[2] != len("2024-03-01 00:00:00") {
+		t.Errorf("widths[2] = %d, want %d", m.widths[2], len("2024-03-01 00:00:00"))
+	}
+	if m.widths[0] != minColumnWidth {
+		t.Errorf("widths[0] = %d, want %d", m.widths[0], minColumnWidth)
+	}
+}
+
+func TestNewTableModelNil(t *testing.T) {
+	m := NewTableModel(nil)
+
+	if m.SelectedRow() != nil {
+		t.Errorf("SelectedRow() = %v, want nil", m.SelectedRow())
+	}
+	if !strings.Contains(m.View(), "row 0 of 0") {
+		t.Errorf("View() = %q, should contain status of empty table", m.View())
+	}
+}
+
+func TestTableModelScrolling(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+
+	sendKeys(m, "down", "down", "down")
+	if m.cursor != 2 {
+		t.Errorf("cursor = %d, want 2", m.cursor)
+	}
+	sendKeys(m, "up")
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want 1", m.cursor)
+	}
+	sendKeys(m, "G")
+	if m.cursor != 2 {
+		t.Errorf("cursor after end = %d, want 2", m.cursor)
+	}
+	sendKeys(m, "g")
+	if m.cursor != 0 {
+		t.Errorf("cursor after home = %d, want 0", m.cursor)
+	}
+}
+
+func TestTableModelScrollingKeepsCursorVisible(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 5})
+
+	sendKeys(m, "down", "down")
+	if m.offset != 1 {
+		t.Errorf("offset = %d, want 1", m.offset)
+	}
+	if !strings.Contains(m.View(), "> 20") {
+		t.Errorf("View() = %q, should show selected row", m.View())
+	}
+}
+
+func TestTableModelSort(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+
+	sendKeys(m, "s")
+	if got := m.SelectedRow()[0]; got != "1" {
+		t.Errorf("first row after ascending sort = %s, want 1", got)
+	}
+	if m.cells[m.rows[2]][0] != "20" {
+		t.Errorf("last row after ascending sort = %s, want 20 (numeric sort)", m.cells[m.rows[2]][0])
+	}
+
+	sendKeys(m, "s")
+	if got := m.SelectedRow()[0]; got != "20" {
+		t.Errorf("first row after descending sort = %s, want 20", got)
+	}
+
+	sendKeys(m, "s")
+	if got := m.SelectedRow()[0]; got != "3" {
+		t.Errorf("first row after clearing sort = %s, want 3", got)
+	}
+}
+
+func TestTableModelSortNullFirst(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+
+	sendKeys(m, "l", "l", "s")
+	if got := m.SelectedRow()[2]; got != "NULL" {
+		t.Errorf("first row after sorting by time = %s, want NULL", got)
+	}
+	if got := m.cells[m.rows[1]][1]; got != "bravo" {
+		t.Errorf("second row after sorting by time = %s, want bravo", got)
+	}
+}
+
+func TestTableModelSearch(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+
+	sendKeys(m, "/", "a", "l")
+	if len(m.rows) != 1 {
+		t.Fatalf("len(rows) = %d, want 1", len(m.rows))
+	}
+	if !strings.Contains(m.View(), "/al") {
+		t.Errorf("View() = %q, should show search query", m.View())
+	}
+
+	sendKeys(m, "backspace", "backspace")
+	if len(m.rows) != 3 {
+		t.Errorf("len(rows) after backspace = %d, want 3", len(m.rows))
+	}
+
+	sendKeys(m, "B", "R", "enter")
+	if m.searching {
+		t.Error("searching should end after enter")
+	}
+	if len(m.rows) != 1 || m.SelectedRow()[1] != "bravo" {
+		t.Errorf("search should be case insensitive, got rows %v", m.rows)
+	}
+	if !strings.Contains(m.View(), "filtered from 3") {
+		t.Errorf("View() = %q, should show filter status", m.View())
+	}
+
+	sendKeys(m, "/", "esc")
+	if len(m.rows) != 3 || m.query != "" {
+		t.Errorf("esc should clear search, got query %q and %d rows", m.query, len(m.rows))
+	}
+}
+
+func TestTableModelSearchNoMatch(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+
+	sendKeys(m, "/", "z", "z", "enter")
+	if m.SelectedRow() != nil {
+		t.Errorf("SelectedRow() = %v, want nil", m.SelectedRow())
+	}
+	sendKeys(m, "enter")
+	if m.detail {
+		t.Error("detail view should not open without rows")
+	}
+}
+
+func TestTableModelResizeColumn(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+	original := m.widths[0]
+
+	sendKeys(m, "+", "+")
+	if m.widths[0] != original+2 {
+		t.Errorf("widths[0] = %d, want %d", m.widths[0], original+2)
+	}
+	sendKeys(m, "-", "-", "-", "-", "-")
+	if m.widths[0] != minColumnWidth {
+		t.Errorf("widths[0] = %d, want %d", m.widths[0], minColumnWidth)
+	}
+}
+
+func TestTableModelColumnScrolling(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+	m.Update(tea.WindowSizeMsg{Width: 12, Height: 10})
+
+	sendKeys(m, "l", "l")
+	if m.columnOffset != 2 {
+		t.Errorf("columnOffset = %d, want 2", m.columnOffset)
+	}
+	if !strings.Contains(m.View(), "[created_at]") {
+		t.Errorf("View() = %q, should show selected column", m.View())
+	}
+	sendKeys(m, "h", "h")
+	if m.columnOffset != 0 {
+		t.Errorf("columnOffset = %d, want 0", m.columnOffset)
+	}
+}
+
+func TestTableModelDetailView(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+
+	sendKeys(m, "down", "enter")
+	if !m.detail {
+		t.Fatal("detail view should be opened")
+	}
+	view := m.View()
+	for _, want := range []string{"id", "alpha", "created_at", "NULL"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() = %q, should contain %q", view, want)
+		}
+	}
+
+	sendKeys(m, "esc")
+	if m.detail {
+		t.Error("esc should close detail view")
+	}
+}
+
+func TestTableModelCopy(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+	var copied string
+	m.copyToClipboard = func(s string) { copied = s }
+
+	sendKeys(m, "l", "y")
+	if copied != "charlie" {
+		t.Errorf("copied = %q, want %q", copied, "charlie")
+	}
+	if !strings.Contains(m.View(), "copied cell of column name") {
+		t.Errorf("View() = %q, should show copy status", m.View())
+	}
+
+	sendKeys(m, "Y")
+	if copied != "3\tcharlie\t2024-03-01 00:00:00" {
+		t.Errorf("copied = %q, want tab separated row", copied)
+	}
+}
+
+func TestTableModelQuit(t *testing.T) {
+	m := NewTableModel(newTestTableData())
+
+	for _, key := range []string{"q", "ctrl+c"} {
+		cmd := sendKeys(m, key)
+		if cmd == nil {
+			t.Fatalf("key %s should return a command", key)
+		}
+		if cmd() != tea.Quit() {
+			t.Errorf("key %s should quit", key)
+		}
+	}
+}
+
+func TestFitCell(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		width int
+		want  string
+	}{
+		{"pad", "ab", 4, "ab  "},
+		{"exact", "abcd", 4, "abcd"},
+		{"truncate", "abcdef", 4, "abc…"},
+		{"newline", "a\nb", 3, "a b"},
+		{"wide characters", "中文字", 4, "中… "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fitCell(tt.value, tt.width); got != tt.want {
+				t.Errorf("fitCell(%q, %d) = %q, want %q", tt.value, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		a, b interface{}
+		want int
+	}{
+		{"both nil", nil, nil, 0},
+		{"nil first", nil, int64(1), -1},
+		{"nil last", "a", nil, 1},
+		{"numbers", int64(2), float64(10), -1},
+		{"equal numbers", int32(2), int64(2), 0},
+		{"times", now.Add(time.Hour), now, 1},
+		{"strings", "b", "a", 1},
+		{"bytes", []byte("a"), []byte("b"), -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareValues(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareValues(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkNewTableModel(b *testing.B) {
+	data := &database.TableData{Columns: []string{"id", "name"}}
+	for i := 0; i < 1000; i++ {
+		id := new(interface{})
+		*id = int64(i)
+		name := new(interface{})
+		*name = "name"
+		data.Rows = append(data.Rows, []interface{}{id, name})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewTableModel(data)
+	}
+}
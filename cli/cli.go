@@ -1,46 +1,32 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/structs"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
-// GetOpenCommand returns the command and its required arguments according to
-// the current operation system
-func GetOpenCommand(args ...string) (string, []string) {
-	switch runtime.GOOS {
-	case "windows":
-		cmdArgs := []string{"/C", "start"}
-		cmdArgs = append(cmdArgs, args...)
-		return "cmd", cmdArgs
-	case "darwin":
-		return "open", args
-	default:
-		return "xdg-open", args
-	}
-}
-
-func OpenInBrowser(url string) error {
-	cmdName, cmdArgs := GetOpenCommand(url)
-	_, errOpen := exec.Command(cmdName, cmdArgs...).Output() // #nosec G204
-	if errOpen != nil {
-		cmdParts := []string{cmdName}
-		cmdParts = append(cmdParts, cmdArgs...)
-		return fmt.Errorf("unable to complete command [%s] %w", strings.Join(cmdParts, " "), errOpen)
-	}
-	return nil
-}
+const defaultConfigName = "config"
 
-func ConfigureViper(configFilePath string, applicationName string, verbose bool, environmentVariablePrefix string) {
+// ConfigureViper configures viper to read configuration from the specified
+// file or, if it is not specified, from config.yaml (or another format
+// supported by viper) in the user configuration directory of the application,
+// together with environment variables with the specified prefix. An error is
+// returned if the specified file does not exist or if the configuration file
+// cannot be parsed; a missing configuration file in the default location is
+// not an error.
+func ConfigureViper(configFilePath string, applicationName string, verbose bool, environmentVariablePrefix string) error {
 	if environmentVariablePrefix == "" {
 		environmentVariablePrefix = strings.ReplaceAll(applicationName, "-", "_")
 	}
@@ -48,31 +34,55 @@ func ConfigureViper(configFilePath string, applicationName string, verbose bool,
 	if configFilePath != "" {
 		viper.SetConfigFile(configFilePath)
 	} else {
-		configDir, err := os.UserConfigDir()
+		configDir, err := GetConfigDirectory(applicationName)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
 
-		viper.AddConfigPath(filepath.Join(configDir, applicationName))
-		viper.SetConfigName("config")
+		viper.AddConfigPath(configDir)
+		viper.SetConfigName(defaultConfigName)
 	}
 
 	viper.SetEnvPrefix(environmentVariablePrefix)
 	viper.AutomaticEnv()
 
-	if err := viper.ReadInConfig(); err == nil {
-		if verbose {
-			fmt.Println("Using config file:", viper.ConfigFileUsed())
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if configFilePath == "" && errors.As(err, &notFound) {
+			return nil
 		}
+		return fmt.Errorf("unable to read config file: %w", err)
 	}
+	if verbose {
+		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	}
+	return nil
+}
+
+// GetConfigDirectory returns the directory of configuration of the specified
+// application in the user configuration directory
+func GetConfigDirectory(applicationName string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to find user config directory: %w", err)
+	}
+	return filepath.Join(configDir, applicationName), nil
 }
 
 // / BindFlagsAndEnvToViper binds the flags and environment variables to viper
 // /
+// / Flags which have not been registered to the command are registered
+// / according to the type of the field (string, int, bool, time.Duration or
+// / []string) together with the values of tags default and usage. Fields of
+// / nested structs are bound with dotted keys (such as db.host) and flags
+// / named with hyphens (such as db-host); their environment variables are
+// / prefixed by the env tag of the parent struct if it is set.
+// /
 // / Example:
 // / type config struct {
-// /   Param1 string `mapstructure:"param1" structs:"param1" env:"PARAM1"`
+// /   Param1  string        `mapstructure:"param1" structs:"param1" env:"PARAM1" usage:"Parameter 1" required:"true"`
+// /   Timeout time.Duration `mapstructure:"timeout" structs:"timeout" env:"TIMEOUT" default:"30s"`
+// /   DB      dbConfig      `mapstructure:"db" structs:"db" env:"DB"`
 // / }
 // /
 // / func init() {
@@ -82,21 +92,166 @@ func ConfigureViper(configFilePath string, applicationName string, verbose bool,
 // /    cli.BindFlagsAndEnvToViper(listCmd, listOpts)
 // / }
 func BindFlagsAndEnvToViper(cmd *cobra.Command, params any) (err error) {
-	for _, field := range structs.Fields(params) {
-		key := field.Tag("structs")
+	return bindFieldsToViper(cmd.Flags(), structs.Fields(params), "", "")
+}
+
+// UnmarshalFromViper fills params, which must be a pointer to struct, with
+// the values resolved by viper (flags, environment variables, configuration
+// and defaults) and returns an error if any field tagged with required is
+// not set
+func UnmarshalFromViper(params any) error {
+	if err := viper.Unmarshal(params); err != nil {
+		return fmt.Errorf("unable to unmarshal configuration: %w", err)
+	}
+	missing := getMissingRequiredKeys(structs.Fields(params), "")
+	if len(missing) > 0 {
+		return fmt.Errorf("required configuration is not set: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func bindFieldsToViper(flags *pflag.FlagSet, fields []*structs.Field, keyPrefix string, envPrefix string) error {
+	for _, field := range fields {
+		if !field.IsExported() {
+			continue
+		}
+		key := joinKey(keyPrefix, getFieldKey(field))
 		env := field.Tag("env")
-		err = viper.BindPFlag(key, cmd.Flags().Lookup(key))
-		if err != nil {
+		if env != "" && envPrefix != "" {
+			env = envPrefix + "_" + env
+		}
+
+		if isNestedStruct(field) {
+			nestedEnvPrefix := envPrefix
+			if env != "" {
+				nestedEnvPrefix = env
+			}
+			if err := bindFieldsToViper(flags, field.Fields(), key, nestedEnvPrefix); err != nil {
+				return err
+			}
+			continue
+		}
+
+		flagName := strings.ReplaceAll(key, ".", "-")
+		if flags.Lookup(flagName) == nil {
+			if err := addFlagOfField(flags, flagName, field); err != nil {
+				return err
+			}
+		}
+		if err := viper.BindPFlag(key, flags.Lookup(flagName)); err != nil {
 			return err
 		}
-		err = viper.BindEnv(key, env)
-		if err != nil {
+		if env == "" {
+			continue
+		}
+		if err := viper.BindEnv(key, env); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func addFlagOfField(flags *pflag.FlagSet, name string, field *structs.Field) error {
+	usage := field.Tag("usage")
+	if isRequiredField(field) {
+		usage = strings.TrimSpace(usage + " (required)")
+	}
+	defaultValue := field.Tag("default")
+
+	switch v := field.Value().(type) {
+	case string:
+		if defaultValue != "" {
+			v = defaultValue
+		}
+		flags.String(name, v, usage)
+	case int:
+		if defaultValue != "" {
+			parsed, err := strconv.Atoi(defaultValue)
+			if err != nil {
+				return fmt.Errorf("invalid default value [%s] of field %s: %w", defaultValue, field.Name(), err)
+			}
+			v = parsed
+		}
+		flags.Int(name, v, usage)
+	case bool:
+		if defaultValue != "" {
+			parsed, err := strconv.ParseBool(defaultValue)
+			if err != nil {
+				return fmt.Errorf("invalid default value [%s] of field %s: %w", defaultValue, field.Name(), err)
+			}
+			v = parsed
+		}
+		flags.Bool(name, v, usage)
+	case time.Duration:
+		if defaultValue != "" {
+			parsed, err := time.ParseDuration(defaultValue)
+			if err != nil {
+				return fmt.Errorf("invalid default value [%s] of field %s: %w", defaultValue, field.Name(), err)
+			}
+			v = parsed
+		}
+		flags.Duration(name, v, usage)
+	case []string:
+		if defaultValue != "" {
+			v = strings.Split(defaultValue, ",")
+		}
+		flags.StringSlice(name, v, usage)
+	default:
+		return fmt.Errorf("unsupported type %T of field %s", v, field.Name())
+	}
+	return nil
+}
+
+func getMissingRequiredKeys(fields []*structs.Field, keyPrefix string) []string {
+	var missing []string
+	for _, field := range fields {
+		if !field.IsExported() {
+			continue
+		}
+		key := joinKey(keyPrefix, getFieldKey(field))
+		if isNestedStruct(field) {
+			missing = append(missing, getMissingRequiredKeys(field.Fields(), key)...)
+			continue
+		}
+		if isRequiredField(field) && field.IsZero() {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// getFieldKey returns the viper key of a field from its structs tag, falling
+// back to its mapstructure tag and its name in lower case
+func getFieldKey(field *structs.Field) string {
+	if key := field.Tag("structs"); key != "" {
+		return key
+	}
+	if key, _, _ := strings.Cut(field.Tag("mapstructure"), ","); key != "" {
+		return key
+	}
+	return strings.ToLower(field.Name())
+}
+
+func joinKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func isNestedStruct(field *structs.Field) bool {
+	if field.Kind() != reflect.Struct {
+		return false
+	}
+	_, isTime := field.Value().(time.Time)
+	return !isTime
+}
+
+func isRequiredField(field *structs.Field) bool {
+	required, err := strconv.ParseBool(field.Tag("required"))
+	return err == nil && required
+}
+
 // LogUnableToMarkFlagAsRequired logs an error when unable to mark a flag as required
 func LogUnableToMarkFlagAsRequired(flagName string, err error) {
 	slog.Error(
@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -170,7 +171,7 @@ func TestConfigureViper(t *testing.T) {
 						t.Errorf("ConfigureViper() panicked: %v", r)
 					}
 				}()
-				ConfigureViper(tt.configFilePath, tt.applicationName, tt.verbose, tt.environmentVariablePrefix)
+				_ = ConfigureViper(tt.configFilePath, tt.applicationName, tt.verbose, tt.environmentVariablePrefix)
 			}()
 		})
 	}
@@ -188,7 +189,9 @@ func TestConfigureViperWithExistingConfigFile(t *testing.T) {
 	viper.Reset()
 	defer viper.Reset()
 
-	ConfigureViper(configFile, "test-app", false, "TEST")
+	if err := ConfigureViper(configFile, "test-app", false, "TEST"); err != nil {
+		t.Fatalf("ConfigureViper() returned error: %v", err)
+	}
 
 	// Verify the config file was read
 	if viper.ConfigFileUsed() != configFile {
@@ -266,11 +269,13 @@ func TestBindFlagsAndEnvToViperWithMissingFlag(t *testing.T) {
 	}
 	opts := configWithMissingFlag{}
 
-	// This should return an error when flag doesn't exist
-	// (viper.BindPFlag returns error for nil flags)
+	// The missing flag should be registered from the struct field
 	err := BindFlagsAndEnvToViper(cmd, opts)
-	if err == nil {
-		t.Error("BindFlagsAndEnvToViper() with missing flag should return error")
+	if err != nil {
+		t.Fatalf("BindFlagsAndEnvToViper() with missing flag returned error: %v", err)
+	}
+	if cmd.Flags().Lookup("param1") == nil {
+		t.Error("BindFlagsAndEnvToViper() should register missing flag param1")
 	}
 }
 
@@ -416,7 +421,9 @@ func TestConfigureViperWithVerboseAndExistingConfig(t *testing.T) {
 	defer viper.Reset()
 
 	// Test with verbose=true to cover the verbose output path
-	ConfigureViper(configFile, "test-app", true, "TEST")
+	if err := ConfigureViper(configFile, "test-app", true, "TEST"); err != nil {
+		t.Fatalf("ConfigureViper() returned error: %v", err)
+	}
 
 	// Verify the config file was read
 	if viper.ConfigFileUsed() != configFile {
@@ -428,12 +435,57 @@ func TestConfigureViperWithNonExistentConfig(t *testing.T) {
 	viper.Reset()
 	defer viper.Reset()
 
-	// Test with a config file that doesn't exist
-	// This should not panic, just not find a config
-	ConfigureViper("/nonexistent/path/config.yaml", "test-app", false, "TEST")
+	// An explicitly specified config file must exist
+	err := ConfigureViper("/nonexistent/path/config.yaml", "test-app", false, "TEST")
+	if err == nil {
+		t.Error("ConfigureViper() with non-existent config file should return error")
+	}
+}
 
-	// Viper should have been configured but no config loaded
-	// This is a valid use case
+func TestConfigureViperWithMalformedConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("key: [unclosed\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	viper.Reset()
+	defer viper.Reset()
+
+	err := ConfigureViper(configFile, "test-app", false, "TEST")
+	if err == nil {
+		t.Fatal("ConfigureViper() with malformed config file should return error")
+	}
+	if !strings.Contains(err.Error(), "unable to read config file") {
+		t.Errorf("ConfigureViper() error = %v, should contain 'unable to read config file'", err)
+	}
+}
+
+func TestConfigureViperWithoutConfigInDefaultLocation(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	viper.Reset()
+	defer viper.Reset()
+
+	// A missing config file in the default location is not an error
+	if err := ConfigureViper("", "test-missing-app", false, ""); err != nil {
+		t.Errorf("ConfigureViper() returned error: %v", err)
+	}
+}
+
+func TestGetConfigDirectory(t *testing.T) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Skipf("os.UserConfigDir() returned error: %v", err)
+	}
+
+	dir, err := GetConfigDirectory("test-app")
+	if err != nil {
+		t.Fatalf("GetConfigDirectory() returned error: %v", err)
+	}
+	if dir != filepath.Join(configDir, "test-app") {
+		t.Errorf("GetConfigDirectory() = %q, want %q", dir, filepath.Join(configDir, "test-app"))
+	}
 }
 
 func TestConfigureViperEnvPrefixWithHyphens(t *testing.T) {
@@ -442,7 +494,7 @@ func TestConfigureViperEnvPrefixWithHyphens(t *testing.T) {
 
 	// Test that app name with hyphens gets converted to underscores
 	// when environmentVariablePrefix is empty
-	ConfigureViper("", "my-cool-app", false, "")
+	_ = ConfigureViper("", "my-cool-app", false, "")
 
 	// Set an environment variable with the expected prefix
 	t.Setenv("MY_COOL_APP_TEST_VAR", "test_value")
@@ -481,7 +533,9 @@ func TestConfigureViperWithXDGConfigDir(t *testing.T) {
 	defer viper.Reset()
 
 	// Use the explicit-path code path to verify the file is readable as config.yaml
-	ConfigureViper(configFile, "test-xdg-app", false, "TEST_XDG")
+	if err := ConfigureViper(configFile, "test-xdg-app", false, "TEST_XDG"); err != nil {
+		t.Fatalf("ConfigureViper() returned error: %v", err)
+	}
 
 	if viper.ConfigFileUsed() != configFile {
 		t.Errorf("ConfigureViper() config file = %q, want %q", viper.ConfigFileUsed(), configFile)
@@ -498,7 +552,7 @@ func TestConfigureViperWithXDGConfigDirDefaultPath(t *testing.T) {
 	defer viper.Reset()
 
 	// Should not panic even when the config file does not exist
-	ConfigureViper("", "test-xdg-app", false, "TEST_XDG")
+	_ = ConfigureViper("", "test-xdg-app", false, "TEST_XDG")
 
 	// No config file will be found (temp environment), but Viper should be
 	// configured and the call must not panic.
@@ -615,3 +669,225 @@ func TestLogUnableToMarkFlagAsRequired_DifferentErrors(t *testing.T) {
 		})
 	}
 }
+
+// Tests for flag registration and unmarshalling
+
+type databaseTestConfig struct {
+	Host string `mapstructure:"host" structs:"host" env:"HOST" default:"localhost" usage:"Database host"`
+	Port int    `mapstructure:"port" structs:"port" env:"PORT" default:"5432"`
+}
+
+type registeredConfig struct {
+	Name     string             `mapstructure:"name" structs:"name" env:"TEST_NAME" usage:"Name of the item" required:"true"`
+	Count    int                `mapstructure:"count" structs:"count" default:"3"`
+	Verbose  bool               `mapstructure:"verbose" structs:"verbose" default:"true"`
+	Timeout  time.Duration      `mapstructure:"timeout" structs:"timeout" env:"TEST_TIMEOUT" default:"30s"`
+	Tags     []string           `mapstructure:"tags" structs:"tags" default:"a,b"`
+	Database databaseTestConfig `mapstructure:"db" structs:"db" env:"TEST_DB"`
+	internal string
+}
+
+func TestBindFlagsAndEnvToViperRegistersFlags(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	cmd := &cobra.Command{Use: "test"}
+	if err := BindFlagsAndEnvToViper(cmd, registeredConfig{}); err != nil {
+		t.Fatalf("BindFlagsAndEnvToViper() returned error: %v", err)
+	}
+
+	tests := []struct {
+		flag         string
+		flagType     string
+		defaultValue string
+		usage        string
+	}{
+		{"name", "string", "", "Name of the item (required)"},
+		{"count", "int", "3", ""},
+		{"verbose", "bool", "true", ""},
+		{"timeout", "duration", "30s", ""},
+		{"tags", "stringSlice", "[a,b]", ""},
+		{"db-host", "string", "localhost", "Database host"},
+		{"db-port", "int", "5432", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			flag := cmd.Flags().Lookup(tt.flag)
+			if flag == nil {
+				t.Fatalf("flag %s is not registered", tt.flag)
+			}
+			if flag.Value.Type() != tt.flagType {
+				t.Errorf("type = %s, want %s", flag.Value.Type(), tt.flagType)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %s, want %s", flag.DefValue, tt.defaultValue)
+			}
+			if flag.Usage != tt.usage {
+				t.Errorf("usage = %q, want %q", flag.Usage, tt.usage)
+			}
+		})
+	}
+	if cmd.Flags().Lookup("internal") != nil {
+		t.Error("unexported field should not be registered")
+	}
+
+	if viper.GetString("db.host") != "localhost" {
+		t.Errorf("db.host = %q, want %q", viper.GetString("db.host"), "localhost")
+	}
+}
+
+func TestBindFlagsAndEnvToViperNestedEnvironmentVariables(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	cmd := &cobra.Command{Use: "test"}
+	if err := BindFlagsAndEnvToViper(cmd, registeredConfig{}); err != nil {
+		t.Fatalf("BindFlagsAndEnvToViper() returned error: %v", err)
+	}
+	t.Setenv("TEST_DB_HOST", "db.example.com")
+
+	if viper.GetString("db.host") != "db.example.com" {
+		t.Errorf("db.host = %q, want %q", viper.GetString("db.host"), "db.example.com")
+	}
+}
+
+func TestBindFlagsAndEnvToViperKeepsExistingFlags(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("name", "existing", "Existing flag")
+	if err := BindFlagsAndEnvToViper(cmd, registeredConfig{}); err != nil {
+		t.Fatalf("BindFlagsAndEnvToViper() returned error: %v", err)
+	}
+
+	if usage := cmd.Flags().Lookup("name").Usage; usage != "Existing flag" {
+		t.Errorf("usage = %q, existing flag should not be replaced", usage)
+	}
+}
+
+func TestBindFlagsAndEnvToViperUsesCurrentValuesAsDefaults(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	type config struct {
+		Name string `structs:"name"`
+	}
+	cmd := &cobra.Command{Use: "test"}
+	if err := BindFlagsAndEnvToViper(cmd, config{Name: "current"}); err != nil {
+		t.Fatalf("BindFlagsAndEnvToViper() returned error: %v", err)
+	}
+
+	if viper.GetString("name") != "current" {
+		t.Errorf("name = %q, want %q", viper.GetString("name"), "current")
+	}
+}
+
+func TestBindFlagsAndEnvToViperErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params any
+	}{
+		{
+			name: "unsupported type",
+			params: struct {
+				Ratio float32 `structs:"ratio"`
+			}{},
+		},
+		{
+			name: "invalid int default",
+			params: struct {
+				Count int `structs:"count" default:"many"`
+			}{},
+		},
+		{
+			name: "invalid bool default",
+			params: struct {
+				Enabled bool `structs:"enabled" default:"maybe"`
+			}{},
+		},
+		{
+			name: "invalid duration default",
+			params: struct {
+				Timeout time.Duration `structs:"timeout" default:"soon"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			defer viper.Reset()
+
+			cmd := &cobra.Command{Use: "test"}
+			if err := BindFlagsAndEnvToViper(cmd, tt.params); err == nil {
+				t.Error("BindFlagsAndEnvToViper() should return error")
+			}
+		})
+	}
+}
+
+func TestUnmarshalFromViper(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	cmd := &cobra.Command{Use: "test"}
+	if err := BindFlagsAndEnvToViper(cmd, registeredConfig{}); err != nil {
+		t.Fatalf("BindFlagsAndEnvToViper() returned error: %v", err)
+	}
+	if err := cmd.Flags().Set("name", "from-flag"); err != nil {
+		t.Fatalf("unable to set flag: %v", err)
+	}
+	if err := cmd.Flags().Set("db-port", "6543"); err != nil {
+		t.Fatalf("unable to set flag: %v", err)
+	}
+	t.Setenv("TEST_TIMEOUT", "1m")
+
+	var config registeredConfig
+	if err := UnmarshalFromViper(&config); err != nil {
+		t.Fatalf("UnmarshalFromViper() returned error: %v", err)
+	}
+
+	if config.Name != "from-flag" {
+		t.Errorf("Name = %q, want %q", config.Name, "from-flag")
+	}
+	if config.Count != 3 {
+		t.Errorf("Count = %d, want 3", config.Count)
+	}
+	if !config.Verbose {
+		t.Error("Verbose = false, want true")
+	}
+	if config.Timeout != time.Minute {
+		t.Errorf("Timeout = %v, want %v", config.Timeout, time.Minute)
+	}
+	if len(config.Tags) != 2 || config.Tags[1] != "b" {
+		t.Errorf("Tags = %v, want [a b]", config.Tags)
+	}
+	if config.Database.Host != "localhost" || config.Database.Port != 6543 {
+		t.Errorf("Database = %+v, want localhost:6543", config.Database)
+	}
+}
+
+func TestUnmarshalFromViperMissingRequired(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	cmd := &cobra.Command{Use: "test"}
+	if err := BindFlagsAndEnvToViper(cmd, registeredConfig{}); err != nil {
+		t.Fatalf("BindFlagsAndEnvToViper() returned error: %v", err)
+	}
+
+	var config registeredConfig
+	err := UnmarshalFromViper(&config)
+	if err == nil {
+		t.Fatal("UnmarshalFromViper() should return error when required field is not set")
+	}
+	if !strings.Contains(err.Error(), "name") {
+		t.Errorf("error = %v, should mention the missing key", err)
+	}
+
+	t.Setenv("TEST_NAME", "from-env")
+	if err := UnmarshalFromViper(&config); err != nil {
+		t.Errorf("UnmarshalFromViper() with environment variable returned error: %v", err)
+	}
+}
@@ -0,0 +1,408 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alexhokl/helper/iohelper"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultCompletionCacheTTL   = 5 * time.Minute
+	defaultCompletionTimeout    = 5 * time.Second
+	completionCacheSubdirectory = "completion"
+)
+
+// supported shells of completion command
+const (
+	ShellBash       = "bash"
+	ShellZsh        = "zsh"
+	ShellFish       = "fish"
+	ShellPowerShell = "powershell"
+)
+
+// CompletionProvider returns the candidates of a flag or an argument; args
+// are the arguments already specified in the command line. A candidate may
+// contain a description separated by a tab (see cobra.CompletionWithDesc).
+type CompletionProvider func(ctx context.Context, args []string) ([]string, error)
+
+// CompletionOptions configures the caching and the timeout of providers and
+// the shell directive of completion functions returned by NewCompletionFunc
+type CompletionOptions struct {
+	cacheTTL       time.Duration
+	cacheDirectory string
+	timeout        time.Duration
+	directive      cobra.ShellCompDirective
+}
+
+// CompletionOption is a functional option for NewCompletionFunc,
+// RegisterFlagCompletion and SetArgsCompletion
+type CompletionOption func(*CompletionOptions)
+
+// WithCompletionCacheTTL sets the duration for which candidates are cached
+// on disk; zero disables caching
+func WithCompletionCacheTTL(ttl time.Duration) CompletionOption {
+	return func(o *CompletionOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
+// WithCompletionCacheDirectory sets the directory of cached candidates; it
+// defaults to completion in the user cache directory of the application
+func WithCompletionCacheDirectory(path string) CompletionOption {
+	return func(o *CompletionOptions) {
+		o.cacheDirectory = path
+	}
+}
+
+// WithCompletionTimeout sets the maximum duration a provider may take
+func WithCompletionTimeout(timeout time.Duration) CompletionOption {
+	return func(o *CompletionOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithCompletionDirective sets the directive returned to the shell together
+// with the candidates; it defaults to cobra.ShellCompDirectiveNoFileComp
+func WithCompletionDirective(directive cobra.ShellCompDirective) CompletionOption {
+	return func(o *CompletionOptions) {
+		o.directive = directive
+	}
+}
+
+func defaultCompletionOptions() *CompletionOptions {
+	return &CompletionOptions{
+		cacheTTL:  defaultCompletionCacheTTL,
+		timeout:   defaultCompletionTimeout,
+		directive: cobra.ShellCompDirectiveNoFileComp,
+	}
+}
+
+type completionCache struct {
+	Values []string `json:"values"`
+}
+
+// NewCompletionFunc returns a cobra completion function which completes with
+// the candidates returned by provider. Candidates are cached on disk with the
+// specified key and the arguments already specified so that slow providers
+// (such as those calling remote APIs) are called once for a short period of
+// time; expired candidates are used if the provider returns an error.
+func NewCompletionFunc(key string, provider CompletionProvider, opts ...CompletionOption) cobra.CompletionFunc {
+	options := defaultCompletionOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		cachePath := ""
+		if options.cacheTTL > 0 {
+			path, err := getCompletionCachePath(cmd, options.cacheDirectory, key, args)
+			if err != nil {
+				cobra.CompDebugln(err.Error(), false)
+			}
+			cachePath = path
+		}
+
+		values, err := getCompletionValues(cmd, provider, args, cachePath, options)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filterCompletions(values, toComplete), options.directive
+	}
+}
+
+// RegisterFlagCompletion registers provider as the completion function of the
+// specified flag of cmd
+//
+// Example:
+//
+//	cli.RegisterFlagCompletion(listCmd, "calendar", func(ctx context.Context, _ []string) ([]string, error) {
+//		return listCalendarIDs(ctx)
+//	})
+func RegisterFlagCompletion(cmd *cobra.Command, flagName string, provider CompletionProvider, opts ...CompletionOption) error {
+	key := fmt.Sprintf("%s --%s", cmd.CommandPath(), flagName)
+	if err := cmd.RegisterFlagCompletionFunc(flagName, NewCompletionFunc(key, provider, opts...)); err != nil {
+		return fmt.Errorf("unable to register completion of flag [%s]: %w", flagName, err)
+	}
+	return nil
+}
+
+// SetArgsCompletion sets provider as the completion function of arguments of
+// cmd
+func SetArgsCompletion(cmd *cobra.Command, provider CompletionProvider, opts ...CompletionOption) {
+	cmd.ValidArgsFunction = NewCompletionFunc(cmd.CommandPath(), provider, opts...)
+}
+
+func getCompletionValues(cmd *cobra.Command, provider CompletionProvider, args []string, cachePath string, options *CompletionOptions) ([]string, error) {
+	var cached *completionCache
+	var isExpired bool
+	if cachePath != "" {
+		cached, isExpired = readCompletionCache(cachePath, options.cacheTTL)
+		if cached != nil && !isExpired {
+			return cached.Values, nil
+		}
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+
+	values, err := provider(ctx, args)
+	if err != nil {
+		if cached != nil {
+			return cached.Values, nil
+		}
+		return nil, fmt.Errorf("unable to retrieve completions: %w", err)
+	}
+
+	if cachePath != "" {
+		if err := writeCompletionCache(cachePath, values); err != nil {
+			cobra.CompDebugln(err.Error(), false)
+		}
+	}
+	return values, nil
+}
+
+func getCompletionCachePath(cmd *cobra.Command, directory string, key string, args []string) (string, error) {
+	if directory == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to find user cache directory: %w", err)
+		}
+		directory = filepath.Join(cacheDir, cmd.Root().Name(), completionCacheSubdirectory)
+	}
+	hash := sha256.Sum256([]byte(strings.Join(append([]string{key}, args...), "\x00")))
+	return filepath.Join(directory, hex.EncodeToString(hash[:16])+".json"), nil
+}
+
+// readCompletionCache returns the cached candidates, if any, and whether they
+// are older than ttl
+func readCompletionCache(path string, ttl time.Duration) (*completionCache, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, false
+	}
+	var cache completionCache
+	if err := json.Unmarshal(content, &cache); err != nil {
+		return nil, false
+	}
+	return &cache, time.Since(info.ModTime()) > ttl
+}
+
+func writeCompletionCache(path string, values []string) error {
+	directory := filepath.Dir(path)
+	if err := iohelper.CreateDirectory(directory); err != nil {
+		return fmt.Errorf("unable to create completion cache directory: %w", err)
+	}
+	content, err := json.Marshal(completionCache{Values: values})
+	if err != nil {
+		return fmt.Errorf("unable to marshal completions: %w", err)
+	}
+
+	// writes to a temporary file first so that concurrent completions never
+	// read a partially written cache
+	file, err := os.CreateTemp(directory, ".completion-*")
+	if err != nil {
+		return fmt.Errorf("unable to create completion cache: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("unable to write completion cache: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("unable to write completion cache: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("unable to write completion cache: %w", err)
+	}
+	return nil
+}
+
+func filterCompletions(values []string, toComplete string) []cobra.Completion {
+	var completions []cobra.Completion
+	for _, value := range values {
+		candidate, _, _ := strings.Cut(value, "\t")
+		if strings.HasPrefix(candidate, toComplete) {
+			completions = append(completions, value)
+		}
+	}
+	return completions
+}
+
+// NewCompletionCommand returns a completion command which prints or, with
+// flag --install, installs the completion script of the root command for
+// bash, zsh, fish or PowerShell. Cobra does not add its default completion
+// command once this command is added.
+//
+// Example:
+//
+//	func init() {
+//		rootCmd.AddCommand(cli.NewCompletionCommand())
+//	}
+func NewCompletionCommand() *cobra.Command {
+	var install bool
+	var noDescriptions bool
+
+	cmd := &cobra.Command{
+		Use:                   "completion [bash|zsh|fish|powershell]",
+		Short:                 "Generate or install the completion script for the specified shell",
+		DisableFlagsInUseLine: true,
+		ValidArgs:             []string{ShellBash, ShellZsh, ShellFish, ShellPowerShell},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shell := args[0]
+			if !install {
+				return GenerateCompletionScript(cmd.Root(), shell, cmd.OutOrStdout(), !noDescriptions)
+			}
+
+			path, err := InstallCompletionScript(cmd.Root(), shell, !noDescriptions)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Completion script is installed to %s\n%s", path, getCompletionInstallHint(shell, path))
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&install, "install", false, "Install the completion script to the user directory of the shell")
+	flags.BoolVar(&noDescriptions, "no-descriptions", false, "Disable descriptions of completions")
+
+	return cmd
+}
+
+// GenerateCompletionScript writes the completion script of root for the
+// specified shell to w
+func GenerateCompletionScript(root *cobra.Command, shell string, w io.Writer, includeDescriptions bool) error {
+	var err error
+	switch shell {
+	case ShellBash:
+		err = root.GenBashCompletionV2(w, includeDescriptions)
+	case ShellZsh:
+		if includeDescriptions {
+			err = root.GenZshCompletion(w)
+		} else {
+			err = root.GenZshCompletionNoDesc(w)
+		}
+	case ShellFish:
+		err = root.GenFishCompletion(w, includeDescriptions)
+	case ShellPowerShell:
+		if includeDescriptions {
+			err = root.GenPowerShellCompletionWithDesc(w)
+		} else {
+			err = root.GenPowerShellCompletion(w)
+		}
+	default:
+		return fmt.Errorf("shell [%s] is not supported", shell)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to generate completion script for %s: %w", shell, err)
+	}
+	return nil
+}
+
+// InstallCompletionScript writes the completion script of root to the user
+// completion directory of the specified shell (see GetCompletionScriptPath)
+// and returns the path of the script
+func InstallCompletionScript(root *cobra.Command, shell string, includeDescriptions bool) (string, error) {
+	path, err := GetCompletionScriptPath(shell, root.Name())
+	if err != nil {
+		return "", err
+	}
+	if err := iohelper.CreateDirectory(filepath.Dir(path)); err != nil {
+		return "", fmt.Errorf("unable to create completion directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", fmt.Errorf("unable to create completion script: %w", err)
+	}
+	if err := GenerateCompletionScript(root, shell, file, includeDescriptions); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("unable to write completion script: %w", err)
+	}
+	return path, nil
+}
+
+// GetCompletionScriptPath returns the path of completion script of the
+// specified application in the user directory of the shell; bash and fish
+// load scripts from their directories automatically while zsh and PowerShell
+// require the directory or the script to be added to their profiles
+func GetCompletionScriptPath(shell string, applicationName string) (string, error) {
+	switch shell {
+	case ShellBash:
+		dataDir, err := getXDGDirectory("XDG_DATA_HOME", ".local", "share")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dataDir, "bash-completion", "completions", applicationName), nil
+	case ShellZsh:
+		dataDir, err := getXDGDirectory("XDG_DATA_HOME", ".local", "share")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dataDir, "zsh", "site-functions", "_"+applicationName), nil
+	case ShellFish:
+		configDir, err := getXDGDirectory("XDG_CONFIG_HOME", ".config")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(configDir, "fish", "completions", applicationName+".fish"), nil
+	case ShellPowerShell:
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to find user config directory: %w", err)
+		}
+		return filepath.Join(configDir, "powershell", "completions", applicationName+".ps1"), nil
+	default:
+		return "", fmt.Errorf("shell [%s] is not supported", shell)
+	}
+}
+
+func getCompletionInstallHint(shell string, path string) string {
+	switch shell {
+	case ShellZsh:
+		return fmt.Sprintf("Add the following lines to ~/.zshrc if the directory is not in fpath:\n\n\tfpath=(%s $fpath)\n\tautoload -U compinit; compinit\n", filepath.Dir(path))
+	case ShellPowerShell:
+		return fmt.Sprintf("Add the following line to $PROFILE:\n\n\t. %s\n", path)
+	default:
+		return "Restart the shell to load the completion script\n"
+	}
+}
+
+// getXDGDirectory returns the directory specified by the XDG environment
+// variable or, if it is not set, the fallback path in the home directory
+func getXDGDirectory(environmentVariable string, fallback ...string) (string, error) {
+	if dir := os.Getenv(environmentVariable); dir != "" {
+		return dir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to find home directory: %w", err)
+	}
+	return filepath.Join(append([]string{homeDir}, fallback...)...), nil
+}
@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type countingProvider struct {
+	calls  int
+	values []string
+	err    error
+}
+
+func (p *countingProvider) provide(_ context.Context, _ []string) ([]string, error) {
+	p.calls++
+	return p.values, p.err
+}
+
+func newTestCompletionRoot() (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "test-app"}
+	sub := &cobra.Command{
+		Use: "list",
+		Run: func(*cobra.Command, []string) {},
+	}
+	sub.Flags().String("branch", "", "Branch")
+	root.AddCommand(sub)
+	return root, sub
+}
+
+func executeCompletion(t *testing.T, root *cobra.Command, args ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+	if err := root.Execute(); err != nil {
+		t.Fatalf("completion returned error: %v", err)
+	}
+	return out.String()
+}
+
+func TestRegisterFlagCompletion(t *testing.T) {
+	root, sub := newTestCompletionRoot()
+	provider := &countingProvider{values: []string{"main", "feature/a\tFeature A", "develop"}}
+	cacheDir := t.TempDir()
+
+	if err := RegisterFlagCompletion(sub, "branch", provider.provide, WithCompletionCacheDirectory(cacheDir)); err != nil {
+		t.Fatalf("RegisterFlagCompletion() error: %v", err)
+	}
+
+	output := executeCompletion(t, root, "list", "--branch", "")
+	want := "main\nfeature/a\tFeature A\ndevelop\n:4\n"
+	if !strings.HasPrefix(output, want) {
+		t.Errorf("output = %q, want prefix %q", output, want)
+	}
+
+	output = executeCompletion(t, root, "list", "--branch", "fe")
+	if !strings.HasPrefix(output, "feature/a\tFeature A\n:4\n") {
+		t.Errorf("output = %q, should contain filtered candidates", output)
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider calls = %d, want 1 as candidates are cached", provider.calls)
+	}
+}
+
+func TestRegisterFlagCompletionUnknownFlag(t *testing.T) {
+	_, sub := newTestCompletionRoot()
+	provider := &countingProvider{}
+
+	if err := RegisterFlagCompletion(sub, "missing", provider.provide); err == nil {
+		t.Error("RegisterFlagCompletion() with unknown flag should return error")
+	}
+}
+
+func TestSetArgsCompletion(t *testing.T) {
+	root, sub := newTestCompletionRoot()
+	var received []string
+	SetArgsCompletion(sub, func(_ context.Context, args []string) ([]string, error) {
+		received = args
+		return []string{"alpha", "beta"}, nil
+	}, WithCompletionCacheTTL(0))
+
+	output := executeCompletion(t, root, "list", "first", "b")
+	if !strings.HasPrefix(output, "beta\n:4\n") {
+		t.Errorf("output = %q, should contain filtered candidates", output)
+	}
+	if len(received) != 1 || received[0] != "first" {
+		t.Errorf("provider args = %v, want [first]", received)
+	}
+}
+
+func TestNewCompletionFuncCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	cmd := &cobra.Command{Use: "test-app"}
+
+	tests := []struct {
+		name      string
+		args      []string
+		expire    bool
+		err       error
+		wantCalls int
+		want      []string
+	}{
+		{"first call", nil, false, nil, 1, []string{"one"}},
+		{"cached", nil, false, nil, 1, []string{"one"}},
+		{"different args", []string{"x"}, false, nil, 2, []string{"one"}},
+		{"expired", nil, true, nil, 3, []string{"one"}},
+		{"expired with provider error", nil, true, errors.New("offline"), 4, []string{"one"}},
+	}
+
+	provider := &countingProvider{values: []string{"one"}}
+	f := NewCompletionFunc("key", provider.provide, WithCompletionCacheDirectory(cacheDir), WithCompletionCacheTTL(time.Minute))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expire {
+				expireCompletionCache(t, cacheDir)
+			}
+			provider.err = tt.err
+			got, directive := f(cmd, tt.args, "")
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("directive = %v, want %v", directive, cobra.ShellCompDirectiveNoFileComp)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("completions = %v, want %v", got, tt.want)
+			}
+			if provider.calls != tt.wantCalls {
+				t.Errorf("provider calls = %d, want %d", provider.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func expireCompletionCache(t *testing.T, cacheDir string) {
+	t.Helper()
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatalf("unable to read cache directory: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	for _, entry := range entries {
+		if err := os.Chtimes(filepath.Join(cacheDir, entry.Name()), past, past); err != nil {
+			t.Fatalf("unable to expire cache: %v", err)
+		}
+	}
+}
+
+func TestNewCompletionFuncProviderError(t *testing.T) {
+	provider := &countingProvider{err: errors.New("offline")}
+	f := NewCompletionFunc("key", provider.provide, WithCompletionCacheDirectory(t.TempDir()))
+
+	got, directive := f(&cobra.Command{Use: "test-app"}, nil, "")
+	if directive != cobra.ShellCompDirectiveError {
+		t.Errorf("directive = %v, want %v", directive, cobra.ShellCompDirectiveError)
+	}
+	if len(got) != 0 {
+		t.Errorf("completions = %v, want none", got)
+	}
+}
+
+func TestNewCompletionFuncTimeout(t *testing.T) {
+	f := NewCompletionFunc("key", func(ctx context.Context, _ []string) ([]string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithCompletionCacheTTL(0), WithCompletionTimeout(10*time.Millisecond))
+
+	if _, directive := f(&cobra.Command{Use: "test-app"}, nil, ""); directive != cobra.ShellCompDirectiveError {
+		t.Errorf("directive = %v, want %v", directive, cobra.ShellCompDirectiveError)
+	}
+}
+
+func TestNewCompletionFuncDirective(t *testing.T) {
+	f := NewCompletionFunc("key", func(context.Context, []string) ([]string, error) {
+		return []string{"a"}, nil
+	}, WithCompletionCacheTTL(0), WithCompletionDirective(cobra.ShellCompDirectiveNoSpace))
+
+	if _, directive := f(&cobra.Command{Use: "test-app"}, nil, ""); directive != cobra.ShellCompDirectiveNoSpace {
+		t.Errorf("directive = %v, want %v", directive, cobra.ShellCompDirectiveNoSpace)
+	}
+}
+
+func TestCompletionCommandGenerate(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{ShellBash, "bash completion V2 for test-app"},
+		{ShellZsh, "#compdef test-app"},
+		{ShellFish, "fish completion for test-app"},
+		{ShellPowerShell, "powershell completion for test-app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			root, _ := newTestCompletionRoot()
+			root.AddCommand(NewCompletionCommand())
+			var out bytes.Buffer
+			root.SetOut(&out)
+			root.SetArgs([]string{"completion", tt.shell})
+			if err := root.Execute(); err != nil {
+				t.Fatalf("completion %s returned error: %v", tt.shell, err)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("script should contain %q", tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletionCommandInstall(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataDir)
+
+	root, _ := newTestCompletionRoot()
+	root.AddCommand(NewCompletionCommand())
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"completion", "bash", "--install"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("completion --install returned error: %v", err)
+	}
+
+	path := filepath.Join(dataDir, "bash-completion", "completions", "test-app")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read installed script: %v", err)
+	}
+	if !strings.Contains(string(content), "bash completion V2 for test-app") {
+		t.Error("installed script should be bash completion script")
+	}
+	if !strings.Contains(out.String(), path) {
+		t.Errorf("output = %q, should contain path of script", out.String())
+	}
+}
+
+func TestCompletionCommandInvalidShell(t *testing.T) {
+	root, _ := newTestCompletionRoot()
+	root.AddCommand(NewCompletionCommand())
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+
+	for _, args := range [][]string{{"completion", "tcsh"}, {"completion"}} {
+		root.SetArgs(args)
+		if err := root.Execute(); err == nil {
+			t.Errorf("%v should return error", args)
+		}
+	}
+}
+
+func TestGetCompletionScriptPath(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/data")
+	t.Setenv("XDG_CONFIG_HOME", "/config")
+
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{ShellBash, "/data/bash-completion/completions/app"},
+		{ShellZsh, "/data/zsh/site-functions/_app"},
+		{ShellFish, "/config/fish/completions/app.fish"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			got, err := GetCompletionScriptPath(tt.shell, "app")
+			if err != nil {
+				t.Fatalf("GetCompletionScriptPath() error: %v", err)
+			}
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("GetCompletionScriptPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := GetCompletionScriptPath("tcsh", "app"); err == nil {
+		t.Error("GetCompletionScriptPath() with unsupported shell should return error")
+	}
+}
+
+func TestGetCompletionScriptPathFallsBackToHome(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("HOME", "/home/user")
+
+	got, err := GetCompletionScriptPath(ShellBash, "app")
+	if err != nil {
+		t.Fatalf("GetCompletionScriptPath() error: %v", err)
+	}
+	if got != filepath.FromSlash("/home/user/.local/share/bash-completion/completions/app") {
+		t.Errorf("GetCompletionScriptPath() = %q", got)
+	}
+}
+
+func BenchmarkCompletionFuncCached(b *testing.B) {
+	values := make([]string, 1000)
+	for i := range values {
+		values[i] = strings.Repeat("x", i%20)
+	}
+	f := NewCompletionFunc("key", func(context.Context, []string) ([]string, error) {
+		return values, nil
+	}, WithCompletionCacheDirectory(b.TempDir()))
+	cmd := &cobra.Command{Use: "bench"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f(cmd, nil, "xxx")
+	}
+}
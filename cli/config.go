@@ -0,0 +1,370 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alexhokl/helper/iohelper"
+	"github.com/fatih/structs"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultConfigExtension = "yaml"
+
+// ValidateConfig validates the configuration loaded by viper against the
+// specified schema, which is a struct whose fields are keyed by their structs
+// (or mapstructure) tags as in BindFlagsAndEnvToViper. A field tagged with
+// required must be set, a value must be convertible to the type of its field
+// and a field tagged with enum (such as enum:"json,yaml") only accepts the
+// listed values. All violations are returned together.
+func ValidateConfig(schema any) error {
+	return errors.Join(validateConfigFields(structs.Fields(schema), "")...)
+}
+
+func validateConfigFields(fields []*structs.Field, keyPrefix string) []error {
+	var errs []error
+	for _, field := range fields {
+		if !field.IsExported() {
+			continue
+		}
+		key := joinKey(keyPrefix, getFieldKey(field))
+		if isNestedStruct(field) {
+			errs = append(errs, validateConfigFields(field.Fields(), key)...)
+			continue
+		}
+
+		value := viper.Get(key)
+		if value == nil || value == "" {
+			if isRequiredField(field) {
+				errs = append(errs, fmt.Errorf("%s is required", key))
+			}
+			continue
+		}
+
+		converted, err := convertConfigValue(field, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		if err := validateEnum(field, converted); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errs
+}
+
+// convertConfigValue converts value, which is either a string from a command
+// line or a value parsed from a configuration file, to the type of field
+func convertConfigValue(field *structs.Field, value any) (any, error) {
+	var converted any
+	var err error
+	switch field.Value().(type) {
+	case string:
+		switch value.(type) {
+		case map[string]any, []any:
+			return nil, fmt.Errorf("expected a string but got %T", value)
+		default:
+			converted, err = cast.ToStringE(value)
+		}
+	case int:
+		converted, err = cast.ToIntE(value)
+	case bool:
+		converted, err = cast.ToBoolE(value)
+	case time.Duration:
+		converted, err = cast.ToDurationE(value)
+	case float64:
+		converted, err = cast.ToFloat64E(value)
+	case []string:
+		if s, ok := value.(string); ok {
+			converted = strings.Split(s, ",")
+		} else {
+			converted, err = cast.ToStringSliceE(value)
+		}
+	default:
+		return value, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value [%v] for type %T", value, field.Value())
+	}
+	return converted, nil
+}
+
+func validateEnum(field *structs.Field, value any) error {
+	enum := field.Tag("enum")
+	if enum == "" {
+		return nil
+	}
+	allowed := strings.Split(enum, ",")
+	values := []string{fmt.Sprint(value)}
+	if list, ok := value.([]string); ok {
+		values = list
+	}
+	for _, v := range values {
+		if !slices.Contains(allowed, v) {
+			return fmt.Errorf("value [%s] is not one of [%s]", v, enum)
+		}
+	}
+	return nil
+}
+
+// findSchemaField returns the field of schema with the specified dotted key
+// or nil if there is no such field
+func findSchemaField(fields []*structs.Field, key string) *structs.Field {
+	head, rest, nested := strings.Cut(key, ".")
+	for _, field := range fields {
+		if !field.IsExported() || !strings.EqualFold(getFieldKey(field), head) {
+			continue
+		}
+		if !nested {
+			return field
+		}
+		if isNestedStruct(field) {
+			return findSchemaField(field.Fields(), rest)
+		}
+	}
+	return nil
+}
+
+// GetConfigFilePath returns the path of the configuration file used by viper
+// or, if no file has been loaded, the default path of configuration file of
+// the specified application
+func GetConfigFilePath(applicationName string) (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	configDir, err := GetConfigDirectory(applicationName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, defaultConfigName+"."+defaultConfigExtension), nil
+}
+
+// NewConfigCommand returns a config command with subcommands get, set, list,
+// path, edit and validate which operates on the configuration file of the
+// specified application; schema is an optional struct used to convert values
+// in set and to validate configuration in set, edit and validate
+//
+// Example:
+//
+//	func init() {
+//		rootCmd.AddCommand(cli.NewConfigCommand("my-app", config{}))
+//	}
+func NewConfigCommand(applicationName string, schema any) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage configuration",
+	}
+	cmd.AddCommand(
+		newConfigGetCommand(),
+		newConfigSetCommand(applicationName, schema),
+		newConfigListCommand(),
+		newConfigPathCommand(applicationName),
+		newConfigEditCommand(applicationName, schema),
+		newConfigValidateCommand(schema),
+	)
+	return cmd
+}
+
+func newConfigGetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a configuration key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !viper.IsSet(args[0]) {
+				return fmt.Errorf("key [%s] is not set", args[0])
+			}
+			return printConfigValue(cmd, viper.Get(args[0]))
+		},
+	}
+}
+
+func newConfigSetCommand(applicationName string, schema any) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set the value of a configuration key in the configuration file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+			var value any = args[1]
+			if schema != nil {
+				field := findSchemaField(structs.Fields(schema), key)
+				if field == nil {
+					return fmt.Errorf("key [%s] is not a valid configuration key", key)
+				}
+				converted, err := convertConfigValue(field, value)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				if err := validateEnum(field, converted); err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				value = converted
+			}
+
+			path, err := GetConfigFilePath(applicationName)
+			if err != nil {
+				return err
+			}
+			return writeConfigValue(path, key, value)
+		},
+	}
+}
+
+func newConfigListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all configuration in effect",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			keys := viper.AllKeys()
+			slices.Sort(keys)
+			for _, key := range keys {
+				value, err := formatConfigValue(viper.Get(key))
+				if err != nil {
+					return err
+				}
+				if _, err := fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", key, value); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newConfigPathCommand(applicationName string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Print the path of the configuration file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := GetConfigFilePath(applicationName)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), path)
+			return err
+		},
+	}
+}
+
+func newConfigEditCommand(applicationName string, schema any) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the configuration file with the default editor",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := GetConfigFilePath(applicationName)
+			if err != nil {
+				return err
+			}
+			if err := ensureConfigFile(path); err != nil {
+				return err
+			}
+
+			editor := getEditorCommand()
+			editorCmd := exec.Command(editor[0], append(editor[1:], path)...) // #nosec G204
+			editorCmd.Stdin = os.Stdin
+			editorCmd.Stdout = cmd.OutOrStdout()
+			editorCmd.Stderr = cmd.ErrOrStderr()
+			if err := editorCmd.Run(); err != nil {
+				return fmt.Errorf("unable to complete command [%s] %w", strings.Join(editorCmd.Args, " "), err)
+			}
+
+			viper.SetConfigFile(path)
+			if err := viper.ReadInConfig(); err != nil {
+				return fmt.Errorf("unable to read config file: %w", err)
+			}
+			if schema != nil {
+				return ValidateConfig(schema)
+			}
+			return nil
+		},
+	}
+}
+
+func newConfigValidateCommand(schema any) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Validate configuration in effect",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if schema == nil {
+				return fmt.Errorf("no schema is available for validation")
+			}
+			if err := ValidateConfig(schema); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintln(cmd.OutOrStdout(), "configuration is valid")
+			return err
+		},
+	}
+}
+
+// writeConfigValue sets key in the configuration file in the specified path
+// without writing values from flags or environment variables
+func writeConfigValue(path string, key string, value any) error {
+	if err := ensureConfigFile(path); err != nil {
+		return err
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("unable to read config file: %w", err)
+	}
+	v.Set(key, value)
+	if err := v.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("unable to write config file: %w", err)
+	}
+	return nil
+}
+
+func ensureConfigFile(path string) error {
+	if iohelper.IsFileExist(path) {
+		return nil
+	}
+	if err := iohelper.CreateDirectory(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("unable to create config directory: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to create config file: %w", err)
+	}
+	return file.Close()
+}
+
+func printConfigValue(cmd *cobra.Command, value any) error {
+	formatted, err := formatConfigValue(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), formatted)
+	return err
+}
+
+func formatConfigValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int, int64, float64, bool, time.Duration:
+		return fmt.Sprint(v), nil
+	case []string:
+		return strings.Join(v, ","), nil
+	default:
+		bytes, err := yaml.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(bytes)), nil
+	}
+}
@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type serverConfigSchema struct {
+	Host string `mapstructure:"host" structs:"host" required:"true"`
+	Port int    `mapstructure:"port" structs:"port"`
+}
+
+type configSchema struct {
+	Name     string             `mapstructure:"name" structs:"name" required:"true"`
+	Format   string             `mapstructure:"format" structs:"format" enum:"table,json,yaml"`
+	Timeout  time.Duration      `mapstructure:"timeout" structs:"timeout"`
+	Verbose  bool               `mapstructure:"verbose" structs:"verbose"`
+	Scopes   []string           `mapstructure:"scopes" structs:"scopes" enum:"read,write"`
+	Server   serverConfigSchema `mapstructure:"server" structs:"server"`
+	internal string
+}
+
+func loadTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+	if err := ConfigureViper(configFile, "test-app", false, "TEST_CONFIG"); err != nil {
+		t.Fatalf("ConfigureViper() returned error: %v", err)
+	}
+	return configFile
+}
+
+func executeConfigCommand(t *testing.T, schema any, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewConfigCommand("test-app", schema)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestValidateConfig(t *testing.T) {
+	loadTestConfig(t, `name: test
+format: json
+timeout: 30s
+verbose: true
+scopes: [read, write]
+server:
+  host: localhost
+  port: 8080
+`)
+
+	if err := ValidateConfig(configSchema{}); err != nil {
+		t.Errorf("ValidateConfig() returned error: %v", err)
+	}
+}
+
+func TestValidateConfigViolations(t *testing.T) {
+	loadTestConfig(t, `format: xml
+timeout: soon
+verbose: maybe
+scopes: [read, delete]
+server:
+  port: abc
+`)
+
+	err := ValidateConfig(configSchema{})
+	if err == nil {
+		t.Fatal("ValidateConfig() should return error")
+	}
+	for _, want := range []string{
+		"name is required",
+		"format: value [xml] is not one of [table,json,yaml]",
+		"timeout: invalid value [soon]",
+		"verbose: invalid value [maybe]",
+		"scopes: value [delete] is not one of [read,write]",
+		"server.host is required",
+		"server.port: invalid value [abc]",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateConfig() error = %v, should contain %q", err, want)
+		}
+	}
+}
+
+func TestValidateConfigTypeMismatchOfString(t *testing.T) {
+	loadTestConfig(t, `name:
+  nested: value
+server:
+  host: localhost
+`)
+
+	err := ValidateConfig(configSchema{})
+	if err == nil || !strings.Contains(err.Error(), "expected a string") {
+		t.Errorf("ValidateConfig() error = %v, should report type mismatch", err)
+	}
+}
+
+func TestValidateConfigFromEnvironment(t *testing.T) {
+	loadTestConfig(t, "server:\n  host: localhost\n")
+	t.Setenv("TEST_CONFIG_NAME", "from-env")
+
+	if err := ValidateConfig(configSchema{}); err != nil {
+		t.Errorf("ValidateConfig() returned error: %v", err)
+	}
+}
+
+func TestConfigCommandGet(t *testing.T) {
+	loadTestConfig(t, "name: test\nserver:\n  host: localhost\n  port: 8080\n")
+
+	output, err := executeConfigCommand(t, nil, "get", "server.port")
+	if err != nil {
+		t.Fatalf("config get returned error: %v", err)
+	}
+	if output != "8080\n" {
+		t.Errorf("output = %q, want %q", output, "8080\n")
+	}
+
+	output, err = executeConfigCommand(t, nil, "get", "server")
+	if err != nil {
+		t.Fatalf("config get returned error: %v", err)
+	}
+	if !strings.Contains(output, "host: localhost") {
+		t.Errorf("output = %q, should contain nested values", output)
+	}
+
+	if _, err := executeConfigCommand(t, nil, "get", "missing"); err == nil {
+		t.Error("config get of missing key should return error")
+	}
+}
+
+func TestConfigCommandSet(t *testing.T) {
+	configFile := loadTestConfig(t, "name: test\n")
+	t.Setenv("TEST_CONFIG_FORMAT", "yaml")
+
+	if _, err := executeConfigCommand(t, configSchema{}, "set", "server.port", "9090"); err != nil {
+		t.Fatalf("config set returned error: %v", err)
+	}
+	if _, err := executeConfigCommand(t, configSchema{}, "set", "scopes", "read,write"); err != nil {
+		t.Fatalf("config set returned error: %v", err)
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("unable to read config file: %v", err)
+	}
+	for _, want := range []string{"name: test", "port: 9090", "- read"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("config file = %q, should contain %q", content, want)
+		}
+	}
+	if strings.Contains(string(content), "format") {
+		t.Errorf("config file = %q, should not contain values from environment", content)
+	}
+}
+
+func TestConfigCommandSetInvalidValues(t *testing.T) {
+	loadTestConfig(t, "name: test\n")
+
+	tests := [][]string{
+		{"set", "server.port", "abc"},
+		{"set", "format", "xml"},
+		{"set", "unknown", "value"},
+		{"set", "name"},
+	}
+	for _, args := range tests {
+		if _, err := executeConfigCommand(t, configSchema{}, args...); err == nil {
+			t.Errorf("config %v should return error", args)
+		}
+	}
+}
+
+func TestConfigCommandSetCreatesConfigFile(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	configFile := filepath.Join(t.TempDir(), "nested", "config.yaml")
+	viper.SetConfigFile(configFile)
+
+	if _, err := executeConfigCommand(t, nil, "set", "name", "created"); err != nil {
+		t.Fatalf("config set returned error: %v", err)
+	}
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("unable to read config file: %v", err)
+	}
+	if strings.TrimSpace(string(content)) != "name: created" {
+		t.Errorf("config file = %q", content)
+	}
+}
+
+func TestConfigCommandList(t *testing.T) {
+	loadTestConfig(t, "name: test\nserver:\n  host: localhost\n")
+
+	output, err := executeConfigCommand(t, nil, "list")
+	if err != nil {
+		t.Fatalf("config list returned error: %v", err)
+	}
+	if output != "name=test\nserver.host=localhost\n" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestConfigCommandPath(t *testing.T) {
+	configFile := loadTestConfig(t, "name: test\n")
+
+	output, err := executeConfigCommand(t, nil, "path")
+	if err != nil {
+		t.Fatalf("config path returned error: %v", err)
+	}
+	if output != configFile+"\n" {
+		t.Errorf("output = %q, want %q", output, configFile+"\n")
+	}
+}
+
+func TestGetConfigFilePathDefault(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	configDir, err := GetConfigDirectory("test-app")
+	if err != nil {
+		t.Skipf("GetConfigDirectory() returned error: %v", err)
+	}
+	path, err := GetConfigFilePath("test-app")
+	if err != nil {
+		t.Fatalf("GetConfigFilePath() returned error: %v", err)
+	}
+	if path != filepath.Join(configDir, "config.yaml") {
+		t.Errorf("GetConfigFilePath() = %q", path)
+	}
+}
+
+func TestConfigCommandEdit(t *testing.T) {
+	configFile := loadTestConfig(t, "name: test\n")
+
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho 'name: edited' > \"$1\"\n"), 0700); err != nil {
+		t.Fatalf("unable to create editor script: %v", err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", script)
+
+	if _, err := executeConfigCommand(t, nil, "edit"); err != nil {
+		t.Fatalf("config edit returned error: %v", err)
+	}
+	if viper.GetString("name") != "edited" {
+		t.Errorf("name = %q, want edited config to be reloaded", viper.GetString("name"))
+	}
+	content, _ := os.ReadFile(configFile)
+	if strings.TrimSpace(string(content)) != "name: edited" {
+		t.Errorf("config file = %q", content)
+	}
+
+	// validation runs after editing when schema is given
+	if _, err := executeConfigCommand(t, configSchema{}, "edit"); err == nil {
+		t.Error("config edit should return validation error")
+	}
+}
+
+func TestConfigCommandEditFailure(t *testing.T) {
+	loadTestConfig(t, "name: test\n")
+	t.Setenv("VISUAL", "false")
+
+	_, err := executeConfigCommand(t, nil, "edit")
+	if err == nil || !strings.Contains(err.Error(), "unable to complete command") {
+		t.Errorf("config edit error = %v, should report editor failure", err)
+	}
+}
+
+func TestConfigCommandValidate(t *testing.T) {
+	loadTestConfig(t, "name: test\nserver:\n  host: localhost\n")
+
+	output, err := executeConfigCommand(t, configSchema{}, "validate")
+	if err != nil {
+		t.Fatalf("config validate returned error: %v", err)
+	}
+	if output != "configuration is valid\n" {
+		t.Errorf("output = %q", output)
+	}
+
+	if _, err := executeConfigCommand(t, nil, "validate"); err == nil {
+		t.Error("config validate without schema should return error")
+	}
+}
+
+func TestNewConfigCommandSubcommands(t *testing.T) {
+	cmd := NewConfigCommand("test-app", nil)
+
+	var names []string
+	for _, c := range cmd.Commands() {
+		names = append(names, c.Name())
+	}
+	want := "edit get list path set validate"
+	if strings.Join(names, " ") != want {
+		t.Errorf("subcommands = %v, want %s", names, want)
+	}
+}
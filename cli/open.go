@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const wslReleaseFile = "/proc/sys/kernel/osrelease"
+
+// ErrNoBrowser is returned by LaunchBrowser when no browser can be launched
+// in the current environment
+var ErrNoBrowser = errors.New("no browser is available in the current environment")
+
+// GetOpenCommand returns the command and its required arguments according to
+// the current operation system
+func GetOpenCommand(args ...string) (string, []string) {
+	switch runtime.GOOS {
+	case "windows":
+		cmdArgs := []string{"/C", "start"}
+		cmdArgs = append(cmdArgs, args...)
+		return "cmd", cmdArgs
+	case "darwin":
+		return "open", args
+	default:
+		return "xdg-open", args
+	}
+}
+
+// OpenInBrowser opens url with LaunchBrowser and, if no browser can be
+// launched (such as in a container or an SSH session), prints url to
+// standard error so that it can be opened manually
+func OpenInBrowser(url string) error {
+	return openInBrowser(url, os.Stderr)
+}
+
+func openInBrowser(url string, fallback io.Writer) error {
+	errLaunch := LaunchBrowser(url)
+	if errLaunch == nil {
+		return nil
+	}
+	if _, err := fmt.Fprintf(fallback, "Open the following URL in a browser:\n\n\t%s\n\n", url); err != nil {
+		return errors.Join(errLaunch, err)
+	}
+	return nil
+}
+
+// LaunchBrowser opens url with the browsers listed in $BROWSER (separated by
+// colons, where %s in a command is replaced by url) or, if it is not set,
+// with the default browser of the operation system. ErrNoBrowser is returned
+// in a headless environment.
+func LaunchBrowser(url string) error {
+	if browsers := os.Getenv("BROWSER"); browsers != "" {
+		var errs []error
+		for _, browser := range strings.Split(browsers, string(os.PathListSeparator)) {
+			cmdParts := getBrowserCommand(browser, url)
+			if len(cmdParts) == 0 {
+				continue
+			}
+			browserCmd := exec.Command(cmdParts[0], cmdParts[1:]...) // #nosec G204
+			browserCmd.Stdin = os.Stdin
+			browserCmd.Stdout = os.Stdout
+			browserCmd.Stderr = os.Stderr
+			err := browserCmd.Run()
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, fmt.Errorf("unable to complete command [%s] %w", strings.Join(cmdParts, " "), err))
+		}
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+
+	if IsHeadless() {
+		return ErrNoBrowser
+	}
+
+	cmdName, cmdArgs := GetOpenCommand(url)
+	if IsWSL() {
+		cmdName, cmdArgs = getWSLOpenCommand(url)
+	}
+	_, errOpen := exec.Command(cmdName, cmdArgs...).Output() // #nosec G204
+	if errOpen != nil {
+		cmdParts := []string{cmdName}
+		cmdParts = append(cmdParts, cmdArgs...)
+		return fmt.Errorf("unable to complete command [%s] %w", strings.Join(cmdParts, " "), errOpen)
+	}
+	return nil
+}
+
+// getBrowserCommand returns the command of an entry of $BROWSER with url
+// substituted for %s or, if there is none, appended as the last argument
+func getBrowserCommand(browser string, url string) []string {
+	cmdParts := strings.Fields(browser)
+	if len(cmdParts) == 0 {
+		return nil
+	}
+	substituted := false
+	for i, part := range cmdParts {
+		if strings.Contains(part, "%s") {
+			cmdParts[i] = strings.ReplaceAll(part, "%s", url)
+			substituted = true
+		}
+	}
+	if !substituted {
+		cmdParts = append(cmdParts, url)
+	}
+	return cmdParts
+}
+
+func getWSLOpenCommand(url string) (string, []string) {
+	if _, err := exec.LookPath("wslview"); err == nil {
+		return "wslview", []string{url}
+	}
+	return "rundll32.exe", []string{"url.dll,FileProtocolHandler", url}
+}
+
+// IsHeadless returns true if a graphical browser cannot be opened, which is
+// the case in an SSH session without X11 forwarding or, on systems other than
+// Windows and macOS, when neither X11 nor Wayland is available (such as in a
+// container); Windows Subsystem for Linux is not considered headless
+func IsHeadless() bool {
+	hasDisplay := os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	if IsSSHSession() {
+		return !hasDisplay
+	}
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return false
+	default:
+		return !hasDisplay && !IsWSL()
+	}
+}
+
+// IsSSHSession returns true if the current process runs in an SSH session
+func IsSSHSession() bool {
+	for _, name := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWSL returns true if the current process runs in Windows Subsystem for
+// Linux
+func IsWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if os.Getenv("WSL_DISTRO_NAME") != "" || os.Getenv("WSL_INTEROP") != "" {
+		return true
+	}
+	release, err := os.ReadFile(wslReleaseFile)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+// EditorOptions configures the temporary file and the standard streams of
+// the editor started by OpenInEditor
+type EditorOptions struct {
+	filePattern string
+	stdin       io.Reader
+	stdout      io.Writer
+	stderr      io.Writer
+}
+
+// EditorOption is a functional option for OpenInEditor
+type EditorOption func(*EditorOptions)
+
+// WithEditorFilePattern sets the pattern of name of the temporary file (see
+// os.CreateTemp) so that an editor can detect the type of content with its
+// extension (such as "*.md")
+func WithEditorFilePattern(pattern string) EditorOption {
+	return func(o *EditorOptions) {
+		o.filePattern = pattern
+	}
+}
+
+// WithEditorIO sets the standard input, output and error of the editor
+func WithEditorIO(stdin io.Reader, stdout io.Writer, stderr io.Writer) EditorOption {
+	return func(o *EditorOptions) {
+		o.stdin = stdin
+		o.stdout = stdout
+		o.stderr = stderr
+	}
+}
+
+func defaultEditorOptions() *EditorOptions {
+	return &EditorOptions{
+		filePattern: "*.txt",
+		stdin:       os.Stdin,
+		stdout:      os.Stdout,
+		stderr:      os.Stderr,
+	}
+}
+
+// OpenInEditor writes content to a temporary file, opens it with the editor
+// specified by $VISUAL or $EDITOR (vi or notepad if neither is set), waits for
+// the editor to exit and returns the edited content. The temporary file is
+// removed afterwards.
+func OpenInEditor(content string, opts ...EditorOption) (string, error) {
+	options := defaultEditorOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	file, err := os.CreateTemp("", options.filePattern)
+	if err != nil {
+		return "", fmt.Errorf("unable to create temporary file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+
+	if _, err := file.WriteString(content); err != nil {
+		_ = file.Close()
+		return "", fmt.Errorf("unable to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("unable to write temporary file: %w", err)
+	}
+
+	editor := getEditorCommand()
+	editorCmd := exec.Command(editor[0], append(editor[1:], path)...) // #nosec G204
+	editorCmd.Stdin = options.stdin
+	editorCmd.Stdout = options.stdout
+	editorCmd.Stderr = options.stderr
+	if err := editorCmd.Run(); err != nil {
+		return "", fmt.Errorf("unable to complete command [%s] %w", strings.Join(editorCmd.Args, " "), err)
+	}
+
+	edited, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("unable to read temporary file: %w", err)
+	}
+	return string(edited), nil
+}
+
+// getEditorCommand returns the command of editor specified by $VISUAL or
+// $EDITOR or the default editor of the operation system
+func getEditorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(name)); len(editor) > 0 {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeTestScript creates an executable shell script and returns its path
+func writeTestScript(t *testing.T, name string, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0700); err != nil {
+		t.Fatalf("unable to create script: %v", err)
+	}
+	return path
+}
+
+func clearDisplayEnvironment(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"BROWSER", "DISPLAY", "WAYLAND_DISPLAY", "SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY", "WSL_DISTRO_NAME", "WSL_INTEROP"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestGetBrowserCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		browser string
+		want    []string
+	}{
+		{"command only", "firefox", []string{"firefox", "https://example.com"}},
+		{"command with arguments", "firefox --new-window", []string{"firefox", "--new-window", "https://example.com"}},
+		{"placeholder", "lynx -dump %s", []string{"lynx", "-dump", "https://example.com"}},
+		{"placeholder within argument", "browser --url=%s", []string{"browser", "--url=https://example.com"}},
+		{"empty", " ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getBrowserCommand(tt.browser, "https://example.com")
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("getBrowserCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLaunchBrowserFromEnvironment(t *testing.T) {
+	clearDisplayEnvironment(t)
+	output := filepath.Join(t.TempDir(), "url")
+	script := writeTestScript(t, "browser", `echo "$1" > "`+output+`"`)
+	t.Setenv("BROWSER", "false"+string(os.PathListSeparator)+script)
+
+	if err := LaunchBrowser("https://example.com/?a=1&b=2"); err != nil {
+		t.Fatalf("LaunchBrowser() error: %v", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("browser should be launched: %v", err)
+	}
+	if strings.TrimSpace(string(content)) != "https://example.com/?a=1&b=2" {
+		t.Errorf("browser received %q", content)
+	}
+}
+
+func TestLaunchBrowserFromEnvironmentFailure(t *testing.T) {
+	clearDisplayEnvironment(t)
+	t.Setenv("BROWSER", "false")
+
+	err := LaunchBrowser("https://example.com")
+	if err == nil || !strings.Contains(err.Error(), "unable to complete command [false https://example.com]") {
+		t.Errorf("LaunchBrowser() error = %v", err)
+	}
+}
+
+func TestLaunchBrowserHeadless(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("display environment variables are not used on " + runtime.GOOS)
+	}
+	clearDisplayEnvironment(t)
+	if IsWSL() {
+		t.Skip("running in WSL")
+	}
+
+	if err := LaunchBrowser("https://example.com"); !errors.Is(err, ErrNoBrowser) {
+		t.Errorf("LaunchBrowser() error = %v, want %v", err, ErrNoBrowser)
+	}
+}
+
+func TestOpenInBrowserFallsBackToPrintingURL(t *testing.T) {
+	clearDisplayEnvironment(t)
+	t.Setenv("SSH_TTY", "/dev/pts/0")
+
+	var buf bytes.Buffer
+	if err := openInBrowser("https://example.com/login", &buf); err != nil {
+		t.Fatalf("openInBrowser() error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\thttps://example.com/login\n") {
+		t.Errorf("output = %q, should contain URL", buf.String())
+	}
+}
+
+func TestIsHeadless(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"ssh without display", map[string]string{"SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22"}, true},
+		{"ssh with X11 forwarding", map[string]string{"SSH_TTY": "/dev/pts/0", "DISPLAY": "localhost:10.0"}, false},
+		{"X11", map[string]string{"DISPLAY": ":0"}, false},
+		{"wayland", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, false},
+	}
+	if runtime.GOOS == "linux" {
+		tests = append(tests, struct {
+			name string
+			env  map[string]string
+			want bool
+		}{"WSL", map[string]string{"WSL_DISTRO_NAME": "Ubuntu"}, false})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDisplayEnvironment(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if got := IsHeadless(); got != tt.want {
+				t.Errorf("IsHeadless() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSSHSession(t *testing.T) {
+	clearDisplayEnvironment(t)
+	if IsSSHSession() {
+		t.Error("IsSSHSession() = true without SSH environment variables")
+	}
+	t.Setenv("SSH_CLIENT", "10.0.0.1 50000 22")
+	if !IsSSHSession() {
+		t.Error("IsSSHSession() = false with SSH_CLIENT")
+	}
+}
+
+func TestIsWSL(t *testing.T) {
+	clearDisplayEnvironment(t)
+	t.Setenv("WSL_DISTRO_NAME", "Ubuntu")
+
+	if got := IsWSL(); got != (runtime.GOOS == "linux") {
+		t.Errorf("IsWSL() = %v with WSL_DISTRO_NAME on %s", got, runtime.GOOS)
+	}
+}
+
+func TestOpenInEditor(t *testing.T) {
+	names := filepath.Join(t.TempDir(), "names")
+	script := writeTestScript(t, "editor", `basename "$1" >> "`+names+`"; echo "edited" >> "$1"`)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", script)
+
+	got, err := OpenInEditor("original\n", WithEditorFilePattern("*.md"), WithEditorIO(nil, nil, nil))
+	if err != nil {
+		t.Fatalf("OpenInEditor() error: %v", err)
+	}
+	if got != "original\nedited\n" {
+		t.Errorf("OpenInEditor() = %q, want %q", got, "original\nedited\n")
+	}
+
+	content, _ := os.ReadFile(names)
+	name := strings.TrimSpace(string(content))
+	if !strings.HasSuffix(name, ".md") {
+		t.Errorf("temporary file = %q, should match pattern", name)
+	}
+	if _, err := os.Stat(filepath.Join(os.TempDir(), name)); !os.IsNotExist(err) {
+		t.Error("temporary file should be removed")
+	}
+}
+
+func TestOpenInEditorPrefersVisual(t *testing.T) {
+	visual := writeTestScript(t, "visual", `echo "visual" > "$1"`)
+	t.Setenv("VISUAL", visual)
+	t.Setenv("EDITOR", "false")
+
+	got, err := OpenInEditor("", WithEditorIO(nil, nil, nil))
+	if err != nil {
+		t.Fatalf("OpenInEditor() error: %v", err)
+	}
+	if got != "visual\n" {
+		t.Errorf("OpenInEditor() = %q, want content from $VISUAL", got)
+	}
+}
+
+func TestOpenInEditorFailure(t *testing.T) {
+	t.Setenv("VISUAL", "false")
+
+	_, err := OpenInEditor("content", WithEditorIO(nil, nil, nil))
+	if err == nil || !strings.Contains(err.Error(), "unable to complete command [false ") {
+		t.Errorf("OpenInEditor() error = %v, should report editor failure", err)
+	}
+}
+
+func TestGetEditorCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		visual string
+		editor string
+		want   string
+	}{
+		{"visual", "code --wait", "vim", "code --wait"},
+		{"editor", "", "nano", "nano"},
+		{"whitespace only", " ", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+			want := tt.want
+			if want == "" {
+				want = "vi"
+				if runtime.GOOS == "windows" {
+					want = "notepad"
+				}
+			}
+			if got := strings.Join(getEditorCommand(), " "); got != want {
+				t.Errorf("getEditorCommand() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkIsHeadless(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		IsHeadless()
+	}
+}
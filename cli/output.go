@@ -0,0 +1,610 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/alexhokl/helper/database/sqldata"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats supported by RenderOutput
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatMarkdown = "markdown"
+	FormatTemplate = "template"
+)
+
+// OutputFormats lists all the supported output formats
+var OutputFormats = []string{
+	FormatTable,
+	FormatJSON,
+	FormatYAML,
+	FormatCSV,
+	FormatTSV,
+	FormatMarkdown,
+	FormatTemplate,
+}
+
+// OutputOptions contains the options of rendering output of a command
+type OutputOptions struct {
+	Format   string   `mapstructure:"format" structs:"format" env:"OUTPUT_FORMAT"`
+	Columns  []string `mapstructure:"columns" structs:"columns" env:"OUTPUT_COLUMNS"`
+	SortBy   string   `mapstructure:"sort-by" structs:"sort-by" env:"OUTPUT_SORT_BY"`
+	Template string   `mapstructure:"template" structs:"template" env:"OUTPUT_TEMPLATE"`
+}
+
+// AddOutputFlags registers the output flags (format, columns, sort-by and
+// template) to the specified command; the flags are read back with
+// GetOutputOptions of the same command so that sibling commands can have
+// different defaults
+//
+// Example:
+//
+//	func init() {
+//		rootCmd.AddCommand(listCmd)
+//		if err := cli.AddOutputFlags(listCmd, cli.FormatTable); err != nil {
+//			slog.Error("unable to add output flags", slog.String("error", err.Error()))
+//		}
+//	}
+//
+//	func runList(cmd *cobra.Command, _ []string) error {
+//		items, err := list()
+//		if err != nil {
+//			return err
+//		}
+//		return cli.RenderOutput(cmd.OutOrStdout(), items, cli.GetOutputOptions(cmd))
+//	}
+func AddOutputFlags(cmd *cobra.Command, defaultFormat string) error {
+	if defaultFormat == "" {
+		defaultFormat = FormatTable
+	}
+	flags := cmd.Flags()
+	for _, name := range []string{"format", "columns", "sort-by", "template"} {
+		if flags.Lookup(name) != nil {
+			return fmt.Errorf("flag [%s] has already been defined in command [%s]", name, cmd.Name())
+		}
+	}
+	flags.String("format", defaultFormat, fmt.Sprintf("Output format (%s)", strings.Join(OutputFormats, ", ")))
+	flags.StringSlice("columns", nil, "Columns to be included in output")
+	flags.String("sort-by", "", "Column to sort output by (prefix with - for descending order)")
+	flags.String("template", "", "Go template applied to each item when format is template")
+	return nil
+}
+
+// GetOutputOptions returns the output options of the specified command
+// registered by AddOutputFlags; a flag set on the command line takes
+// precedence over its environment variable (see the env tags of
+// OutputOptions), which takes precedence over the default of the flag
+func GetOutputOptions(cmd *cobra.Command) *OutputOptions {
+	flags := cmd.Flags()
+	var columns []string
+	for _, c := range getOutputFlagValues(flags, "columns", "OUTPUT_COLUMNS") {
+		for _, name := range strings.Split(c, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				columns = append(columns, name)
+			}
+		}
+	}
+	return &OutputOptions{
+		Format:   getOutputFlagValue(flags, "format", "OUTPUT_FORMAT"),
+		Columns:  columns,
+		SortBy:   getOutputFlagValue(flags, "sort-by", "OUTPUT_SORT_BY"),
+		Template: getOutputFlagValue(flags, "template", "OUTPUT_TEMPLATE"),
+	}
+}
+
+func getOutputFlagValue(flags *pflag.FlagSet, name string, env string) string {
+	flag := flags.Lookup(name)
+	if flag == nil {
+		return os.Getenv(env)
+	}
+	if !flag.Changed {
+		if value, ok := os.LookupEnv(env); ok {
+			return value
+		}
+	}
+	return flag.Value.String()
+}
+
+func getOutputFlagValues(flags *pflag.FlagSet, name string, env string) []string {
+	flag := flags.Lookup(name)
+	if flag == nil || !flag.Changed {
+		if value, ok := os.LookupEnv(env); ok {
+			return []string{value}
+		}
+	}
+	values, _ := flags.GetStringSlice(name)
+	return values
+}
+
+// RenderOutput writes data in the format of the specified options; data can
+// be a slice (or an array) of structs or pointers to structs, a single
+// struct, sqldata.TableData or *sqldata.TableData
+func RenderOutput(w io.Writer, data any, opts *OutputOptions) error {
+	if opts == nil {
+		opts = &OutputOptions{}
+	}
+	table, err := newOutputTable(data)
+	if err != nil {
+		return err
+	}
+	if err := table.sort(opts.SortBy); err != nil {
+		return err
+	}
+	if err := table.selectColumns(opts.Columns); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(opts.Format) {
+	case "", FormatTable:
+		return table.writeTable(w)
+	case FormatJSON:
+		return table.writeJSON(w)
+	case FormatYAML:
+		return table.writeYAML(w)
+	case FormatCSV:
+		return table.writeDelimited(w, ',')
+	case FormatTSV:
+		return table.writeDelimited(w, '\t')
+	case FormatMarkdown:
+		return table.writeMarkdown(w)
+	case FormatTemplate:
+		return table.writeTemplate(w, opts.Template)
+	default:
+		return fmt.Errorf("unsupported output format [%s]", opts.Format)
+	}
+}
+
+type outputRow struct {
+	values []any
+	record any
+}
+
+type outputTable struct {
+	columns []string
+	rows    []outputRow
+
+	// structured is true if rows are built from structs which can be
+	// marshalled as they are when no columns are selected
+	structured bool
+	selected   bool
+}
+
+func newOutputTable(data any) (*outputTable, error) {
+	switch d := data.(type) {
+	case *sqldata.TableData:
+		return newOutputTableFromTableData(d), nil
+	case sqldata.TableData:
+		return newOutputTableFromTableData(&d), nil
+	}
+
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Struct:
+		return newOutputTableFromStructs(reflect.ValueOf([]any{value.Interface()}), value.Type())
+	default:
+		return nil, fmt.Errorf("unsupported output data type %T", data)
+	}
+
+	elementType := value.Type().Elem()
+	for elementType.Kind() == reflect.Pointer {
+		elementType = elementType.Elem()
+	}
+	if elementType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported output data type %T", data)
+	}
+	return newOutputTableFromStructs(value, elementType)
+}
+
+func newOutputTableFromTableData(data *sqldata.TableData) *outputTable {
+	table := &outputTable{}
+	if data == nil {
+		return table
+	}
+	table.columns = append(table.columns, data.Columns...)
+	for _, r := range data.Rows {
+		values := make([]any, len(r))
+		for i, c := range r {
+			if p, ok := c.(*interface{}); ok && p != nil {
+				values[i] = *p
+			} else {
+				values[i] = c
+			}
+		}
+		table.rows = append(table.rows, outputRow{values: values})
+	}
+	return table
+}
+
+func newOutputTableFromStructs(list reflect.Value, elementType reflect.Type) (*outputTable, error) {
+	fields := getOutputFields(elementType)
+	table := &outputTable{structured: true}
+	for _, f := range fields {
+		table.columns = append(table.columns, f.name)
+	}
+
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i)
+		for item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface {
+			if item.IsNil() {
+				break
+			}
+			item = item.Elem()
+		}
+		row := outputRow{values: make([]any, len(fields))}
+		if item.Kind() == reflect.Struct {
+			row.record = item.Interface()
+			for j, f := range fields {
+				row.values[j] = getFieldValue(item, f.index)
+			}
+		}
+		table.rows = append(table.rows, row)
+	}
+	return table, nil
+}
+
+type outputField struct {
+	name  string
+	index []int
+}
+
+// getOutputFields returns exported fields of the specified struct type named
+// by their json tags, falling back to field names
+func getOutputFields(t reflect.Type) []outputField {
+	var fields []outputField
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields = append(fields, outputField{name: name, index: f.Index})
+	}
+	return fields
+}
+
+func getFieldValue(v reflect.Value, index []int) any {
+	field, err := v.FieldByIndexErr(index)
+	if err != nil {
+		return nil
+	}
+	for field.Kind() == reflect.Pointer || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	return field.Interface()
+}
+
+func (t *outputTable) columnIndex(name string) (int, error) {
+	for i, c := range t.columns {
+		if strings.EqualFold(c, name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("column [%s] does not exist (available columns: %s)", name, strings.Join(t.columns, ", "))
+}
+
+func (t *outputTable) sort(sortBy string) error {
+	if sortBy == "" {
+		return nil
+	}
+	descending := strings.HasPrefix(sortBy, "-")
+	index, err := t.columnIndex(strings.TrimPrefix(sortBy, "-"))
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(t.rows, func(a, b outputRow) int {
+		result := compareOutputValues(a.values[index], b.values[index])
+		if descending {
+			return -result
+		}
+		return result
+	})
+	return nil
+}
+
+func (t *outputTable) selectColumns(columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	indices := make([]int, len(columns))
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		index, err := t.columnIndex(c)
+		if err != nil {
+			return err
+		}
+		indices[i] = index
+		names[i] = t.columns[index]
+	}
+	for i, r := range t.rows {
+		values := make([]any, len(indices))
+		for j, index := range indices {
+			values[j] = r.values[index]
+		}
+		t.rows[i].values = values
+	}
+	t.columns = names
+	t.selected = true
+	return nil
+}
+
+func (t *outputTable) stringRows() [][]string {
+	rows := make([][]string, len(t.rows))
+	for i, r := range t.rows {
+		rows[i] = make([]string, len(r.values))
+		for j, v := range r.values {
+			rows[i][j] = formatOutputValue(v)
+		}
+	}
+	return rows
+}
+
+func (t *outputTable) writeTable(w io.Writer) error {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(t.columns)
+	table.AppendBulk(t.stringRows())
+	table.Render()
+	return nil
+}
+
+func (t *outputTable) writeDelimited(w io.Writer, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+	if err := writer.Write(t.columns); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(t.stringRows()); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func (t *outputTable) writeMarkdown(w io.Writer) error {
+	var builder strings.Builder
+	writeMarkdownRow(&builder, t.columns)
+	separators := make([]string, len(t.columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeMarkdownRow(&builder, separators)
+	for _, r := range t.stringRows() {
+		writeMarkdownRow(&builder, r)
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// writeMarkdownRow escapes cells in the same way as the Markdown exporter of
+// package database
+func writeMarkdownRow(builder *strings.Builder, cells []string) {
+	builder.WriteString("|")
+	for _, c := range cells {
+		builder.WriteString(" ")
+		builder.WriteString(sqldata.EscapeMarkdown(c))
+		builder.WriteString(" |")
+	}
+	builder.WriteString("\n")
+}
+
+func (t *outputTable) records() []any {
+	records := make([]any, len(t.rows))
+	for i, r := range t.rows {
+		records[i] = r.record
+	}
+	return records
+}
+
+func (t *outputTable) writeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if t.structured && !t.selected {
+		return encoder.Encode(t.records())
+	}
+
+	// objects are written manually to preserve the order of columns
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, r := range t.rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("{")
+		for j, c := range t.columns {
+			if j > 0 {
+				buf.WriteString(",")
+			}
+			key, err := json.Marshal(c)
+			if err != nil {
+				return err
+			}
+			value, err := json.Marshal(normalizeOutputValue(r.values[j]))
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteString(":")
+			buf.Write(value)
+		}
+		buf.WriteString("}")
+	}
+	buf.WriteString("]")
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	indented.WriteString("\n")
+	_, err := indented.WriteTo(w)
+	return err
+}
+
+func (t *outputTable) writeYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	defer encoder.Close()
+
+	// nodes are built from columns, rather than encoding records, so that
+	// keys follow json tags and the order of columns as the other formats do
+	list := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, r := range t.rows {
+		item := &yaml.Node{Kind: yaml.MappingNode}
+		for j, c := range t.columns {
+			value := &yaml.Node{}
+			if err := value.Encode(normalizeOutputValue(r.values[j])); err != nil {
+				return err
+			}
+			item.Content = append(item.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: c}, value)
+		}
+		list.Content = append(list.Content, item)
+	}
+	return encoder.Encode(list)
+}
+
+func (t *outputTable) writeTemplate(w io.Writer, text string) error {
+	if text == "" {
+		return fmt.Errorf("template is required when output format is %s", FormatTemplate)
+	}
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("unable to parse template: %w", err)
+	}
+	for _, r := range t.rows {
+		var data any = r.record
+		if !t.structured || t.selected {
+			m := make(map[string]any, len(t.columns))
+			for j, c := range t.columns {
+				m[c] = normalizeOutputValue(r.values[j])
+			}
+			data = m
+		}
+		if err := tmpl.Execute(w, data); err != nil {
+			return fmt.Errorf("unable to execute template: %w", err)
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeOutputValue converts byte slices to strings so that they are not
+// encoded as base64 in JSON and YAML
+func normalizeOutputValue(value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+func formatOutputValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareOutputValues compares values with awareness of numbers and time;
+// nil values are sorted before any other values
+func compareOutputValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	x, y := reflect.ValueOf(a), reflect.ValueOf(b)
+	if isNumber(x) && isNumber(y) {
+		xf, yf := toFloat64(x), toFloat64(y)
+		switch {
+		case xf < yf:
+			return -1
+		case xf > yf:
+			return 1
+		default:
+			return 0
+		}
+	}
+	if xt, ok := a.(time.Time); ok {
+		if yt, ok := b.(time.Time); ok {
+			return xt.Compare(yt)
+		}
+	}
+	if xb, ok := a.(bool); ok {
+		if yb, ok := b.(bool); ok {
+			switch {
+			case xb == yb:
+				return 0
+			case yb:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+	return strings.Compare(formatOutputValue(a), formatOutputValue(b))
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func toFloat64(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
@@ -0,0 +1,372 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexhokl/helper/database"
+	"github.com/spf13/cobra"
+)
+
+type testOutputItem struct {
+	Name    string    `json:"name"`
+	Count   int       `json:"count"`
+	Created time.Time `json:"created"`
+	Note    *string   `json:"note,omitempty"`
+	Secret  string    `json:"-"`
+	hidden  string
+}
+
+func newTestOutputItems() []testOutputItem {
+	note := "first"
+	return []testOutputItem{
+		{Name: "bravo", Count: 10, Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Note: &note, Secret: "s", hidden: "h"},
+		{Name: "alpha", Count: 2, Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+func newTestOutputTableData() *database.TableData {
+	data := &database.TableData{Columns: []string{"id", "title", "body"}}
+	for _, r := range [][]interface{}{
+		{int64(2), "second", []byte("b|c")},
+		{int64(1), "first", nil},
+	} {
+		var row []interface{}
+		for _, c := range r {
+			cell := new(interface{})
+			*cell = c
+			row = append(row, cell)
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	return data
+}
+
+func renderToString(t *testing.T, data any, opts *OutputOptions) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := RenderOutput(&buf, data, opts); err != nil {
+		t.Fatalf("RenderOutput() error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRenderOutputTable(t *testing.T) {
+	output := renderToString(t, newTestOutputItems(), &OutputOptions{Format: FormatTable})
+
+	for _, want := range []string{"NAME", "COUNT", "CREATED", "NOTE", "bravo", "2024-01-02T03:04:05Z", "first"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output = %q, should contain %q", output, want)
+		}
+	}
+	if strings.Contains(output, "SECRET") || strings.Contains(output, "HIDDEN") {
+		t.Errorf("output = %q, should not contain ignored fields", output)
+	}
+}
+
+func TestRenderOutputDefaultFormatIsTable(t *testing.T) {
+	output := renderToString(t, newTestOutputItems(), nil)
+
+	if !strings.Contains(output, "+") || !strings.Contains(output, "NAME") {
+		t.Errorf("output = %q, should be a table", output)
+	}
+}
+
+func TestRenderOutputJSON(t *testing.T) {
+	output := renderToString(t, newTestOutputItems(), &OutputOptions{Format: FormatJSON})
+
+	if !strings.Contains(output, `"name": "bravo"`) {
+		t.Errorf("output = %q, should contain marshalled struct", output)
+	}
+	if strings.Contains(output, `"note": null`) {
+		t.Errorf("output = %q, should respect omitempty of json tags", output)
+	}
+}
+
+func TestRenderOutputJSONWithColumns(t *testing.T) {
+	output := renderToString(t, newTestOutputItems(), &OutputOptions{Format: FormatJSON, Columns: []string{"count", "NAME"}})
+
+	want := `[
+  {
+    "count": 10,
+    "name": "bravo"
+  },
+  {
+    "count": 2,
+    "name": "alpha"
+  }
+]
+`
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+func TestRenderOutputJSONTableData(t *testing.T) {
+	output := renderToString(t, newTestOutputTableData(), &OutputOptions{Format: FormatJSON})
+
+	want := `[
+  {
+    "id": 2,
+    "title": "second",
+    "body": "b|c"
+  },
+  {
+    "id": 1,
+    "title": "first",
+    "body": null
+  }
+]
+`
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+func TestRenderOutputYAML(t *testing.T) {
+	output := renderToString(t, newTestOutputTableData(), &OutputOptions{Format: FormatYAML, SortBy: "id"})
+
+	want := `- id: 1
+  title: first
+  body: null
+- id: 2
+  title: second
+  body: b|c
+`
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+func TestRenderOutputYAMLStructs(t *testing.T) {
+	output := renderToString(t, newTestOutputItems(), &OutputOptions{Format: FormatYAML})
+
+	if !strings.Contains(output, "name: bravo") {
+		t.Errorf("output = %q, should contain marshalled struct", output)
+	}
+}
+
+func TestRenderOutputYAMLJSONTags(t *testing.T) {
+	type item struct {
+		DisplayName string `json:"display_name"`
+		ItemCount   int    `json:"item_count"`
+		Internal    string `json:"-"`
+	}
+	output := renderToString(t, []item{{DisplayName: "bravo", ItemCount: 10, Internal: "x"}}, &OutputOptions{Format: FormatYAML})
+
+	want := `- display_name: bravo
+  item_count: 10
+`
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+func TestRenderOutputCSV(t *testing.T) {
+	output := renderToString(t, newTestOutputItems(), &OutputOptions{Format: FormatCSV, Columns: []string{"name", "count"}, SortBy: "name"})
+
+	want := "name,count\nalpha,2\nbravo,10\n"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+func TestRenderOutputTSV(t *testing.T) {
+	output := renderToString(t, newTestOutputTableData(), &OutputOptions{Format: FormatTSV})
+
+	want := "id\ttitle\tbody\n2\tsecond\tb|c\n1\tfirst\t\n"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+func TestRenderOutputMarkdown(t *testing.T) {
+	output := renderToString(t, newTestOutputTableData(), &OutputOptions{Format: FormatMarkdown})
+
+	want := "| id | title | body |\n| --- | --- | --- |\n| 2 | second | b\\|c |\n| 1 | first |  |\n"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+func TestRenderOutputTemplate(t *testing.T) {
+	output := renderToString(t, newTestOutputItems(), &OutputOptions{Format: FormatTemplate, Template: "{{.Name}}={{.Count}}"})
+
+	if output != "bravo=10\nalpha=2\n" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestRenderOutputTemplateTableData(t *testing.T) {
+	output := renderToString(t, newTestOutputTableData(), &OutputOptions{Format: FormatTemplate, Template: "{{.title}}:{{.id}}"})
+
+	if output != "second:2\nfirst:1\n" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestRenderOutputSortDescending(t *testing.T) {
+	output := renderToString(t, newTestOutputItems(), &OutputOptions{Format: FormatCSV, Columns: []string{"count"}, SortBy: "-count"})
+
+	if output != "count\n10\n2\n" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestRenderOutputSingleStructAndPointers(t *testing.T) {
+	items := newTestOutputItems()
+
+	output := renderToString(t, &items[1], &OutputOptions{Format: FormatCSV, Columns: []string{"name"}})
+	if output != "name\nalpha\n" {
+		t.Errorf("output of single struct = %q", output)
+	}
+
+	output = renderToString(t, []*testOutputItem{&items[0], nil}, &OutputOptions{Format: FormatCSV, Columns: []string{"name"}})
+	if output != "name\nbravo\n\n" {
+		t.Errorf("output of pointers = %q", output)
+	}
+}
+
+func TestRenderOutputErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data any
+		opts *OutputOptions
+	}{
+		{"unsupported format", newTestOutputItems(), &OutputOptions{Format: "xml"}},
+		{"unknown column", newTestOutputItems(), &OutputOptions{Columns: []string{"missing"}}},
+		{"unknown sort column", newTestOutputItems(), &OutputOptions{SortBy: "missing"}},
+		{"missing template", newTestOutputItems(), &OutputOptions{Format: FormatTemplate}},
+		{"invalid template", newTestOutputItems(), &OutputOptions{Format: FormatTemplate, Template: "{{"}},
+		{"unsupported data", 42, nil},
+		{"slice of non-struct", []string{"a"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderOutput(&buf, tt.data, tt.opts); err == nil {
+				t.Error("RenderOutput() should return error")
+			}
+		})
+	}
+}
+
+func TestAddOutputFlags(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	if err := AddOutputFlags(cmd, FormatJSON); err != nil {
+		t.Fatalf("AddOutputFlags() error: %v", err)
+	}
+
+	opts := GetOutputOptions(cmd)
+	if opts.Format != FormatJSON {
+		t.Errorf("Format = %q, want %q", opts.Format, FormatJSON)
+	}
+
+	if err := cmd.Flags().Set("columns", "name,count"); err != nil {
+		t.Fatalf("unable to set flag: %v", err)
+	}
+	if err := cmd.Flags().Set("sort-by", "-count"); err != nil {
+		t.Fatalf("unable to set flag: %v", err)
+	}
+	t.Setenv("OUTPUT_FORMAT", "yaml")
+
+	opts = GetOutputOptions(cmd)
+	if opts.Format != FormatYAML {
+		t.Errorf("Format = %q, want %q from environment", opts.Format, FormatYAML)
+	}
+	if len(opts.Columns) != 2 || opts.Columns[1] != "count" {
+		t.Errorf("Columns = %v, want [name count]", opts.Columns)
+	}
+	if opts.SortBy != "-count" {
+		t.Errorf("SortBy = %q, want %q", opts.SortBy, "-count")
+	}
+}
+
+func TestGetOutputOptionsColumnsFromEnvironment(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	if err := AddOutputFlags(cmd, ""); err != nil {
+		t.Fatalf("AddOutputFlags() error: %v", err)
+	}
+	t.Setenv("OUTPUT_COLUMNS", "name, count")
+
+	opts := GetOutputOptions(cmd)
+	if opts.Format != FormatTable {
+		t.Errorf("Format = %q, want %q", opts.Format, FormatTable)
+	}
+	if len(opts.Columns) != 2 || opts.Columns[0] != "name" || opts.Columns[1] != "count" {
+		t.Errorf("Columns = %v, want [name count]", opts.Columns)
+	}
+}
+
+func TestAddOutputFlagsAlreadyDefined(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("format", "", "format")
+	if err := AddOutputFlags(cmd, ""); err == nil {
+		t.Error("AddOutputFlags() should return error")
+	}
+}
+
+func TestGetOutputOptionsSiblingCommands(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	var listOpts, getOpts *OutputOptions
+	listCmd := &cobra.Command{
+		Use: "list",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			listOpts = GetOutputOptions(cmd)
+			return nil
+		},
+	}
+	getCmd := &cobra.Command{
+		Use: "get",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			getOpts = GetOutputOptions(cmd)
+			return nil
+		},
+	}
+	root.AddCommand(listCmd, getCmd)
+	if err := AddOutputFlags(listCmd, FormatTable); err != nil {
+		t.Fatalf("AddOutputFlags() error: %v", err)
+	}
+	if err := AddOutputFlags(getCmd, FormatYAML); err != nil {
+		t.Fatalf("AddOutputFlags() error: %v", err)
+	}
+
+	root.SetArgs([]string{"list", "--format", "json", "--columns", "name"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	if listOpts == nil || listOpts.Format != FormatJSON {
+		t.Errorf("list Format = %v, want %q", listOpts, FormatJSON)
+	}
+	if len(listOpts.Columns) != 1 || listOpts.Columns[0] != "name" {
+		t.Errorf("list Columns = %v, want [name]", listOpts.Columns)
+	}
+
+	root.SetArgs([]string{"get"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	if getOpts == nil || getOpts.Format != FormatYAML {
+		t.Errorf("get Format = %v, want %q", getOpts, FormatYAML)
+	}
+	if len(getOpts.Columns) != 0 {
+		t.Errorf("get Columns = %v, want none", getOpts.Columns)
+	}
+}
+
+func BenchmarkRenderOutputCSV(b *testing.B) {
+	items := make([]testOutputItem, 1000)
+	for i := range items {
+		items[i] = testOutputItem{Name: "name", Count: i}
+	}
+	opts := &OutputOptions{Format: FormatCSV, SortBy: "-count"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		_ = RenderOutput(&buf, items, opts)
+	}
+}
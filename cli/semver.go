@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemanticVersion is a version in format of semantic versioning 2.0.0
+// (https://semver.org)
+type SemanticVersion struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+	Build      string
+}
+
+// ParseSemanticVersion parses a version such as v1.2.3, 1.2.3-rc.1 or
+// 1.2.3+build.5; the leading v and missing minor or patch numbers are allowed
+func ParseSemanticVersion(version string) (*SemanticVersion, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if s == "" {
+		return nil, fmt.Errorf("invalid semantic version [%s]", version)
+	}
+
+	var v SemanticVersion
+	s, v.Build, _ = strings.Cut(s, "+")
+	s, prerelease, hasPrerelease := strings.Cut(s, "-")
+	if hasPrerelease {
+		v.Prerelease = strings.Split(prerelease, ".")
+		for _, identifier := range v.Prerelease {
+			if identifier == "" {
+				return nil, fmt.Errorf("invalid semantic version [%s]", version)
+			}
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid semantic version [%s]", version)
+	}
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid semantic version [%s]", version)
+		}
+		*numbers[i] = n
+	}
+	return &v, nil
+}
+
+// String returns the version without the leading v
+func (v SemanticVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v has a lower, the same or a higher
+// precedence than other; build metadata is ignored
+func (v SemanticVersion) Compare(other SemanticVersion) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if c := compareInt(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+
+	// a pre-release version has a lower precedence than a normal version
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := comparePrereleaseIdentifier(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(v.Prerelease), len(other.Prerelease))
+}
+
+// CompareVersions parses and compares two semantic versions (see
+// SemanticVersion.Compare)
+func CompareVersions(a string, b string) (int, error) {
+	va, err := ParseSemanticVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseSemanticVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(*vb), nil
+}
+
+// comparePrereleaseIdentifier compares identifiers numerically if both are
+// numeric and lexically otherwise; numeric identifiers have a lower
+// precedence than alphanumeric ones
+func comparePrereleaseIdentifier(a string, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInt(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInt(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import "testing"
+
+func TestParseSemanticVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"v1.2.3", "1.2.3", false},
+		{"1.2.3", "1.2.3", false},
+		{"1.2", "1.2.0", false},
+		{"v2", "2.0.0", false},
+		{"1.2.3-rc.1", "1.2.3-rc.1", false},
+		{"1.2.3-beta+exp.sha.5114f85", "1.2.3-beta+exp.sha.5114f85", false},
+		{"1.2.3+build", "1.2.3+build", false},
+		{"", "", true},
+		{"dev", "", true},
+		{"(devel)", "", true},
+		{"1.2.3.4", "", true},
+		{"1.-2.3", "", true},
+		{"1.2.3-", "", true},
+		{"1.2.3-rc..1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := ParseSemanticVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSemanticVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseSemanticVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"2.1.0", "2.0.9", 1},
+		{"1.0.10", "1.0.9", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc.1", 1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got, err := CompareVersions(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("CompareVersions() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := CompareVersions("dev", "1.0.0"); err == nil {
+		t.Error("CompareVersions() with invalid version should return error")
+	}
+	if _, err := CompareVersions("1.0.0", "latest"); err == nil {
+		t.Error("CompareVersions() with invalid version should return error")
+	}
+}
+
+func BenchmarkCompareVersions(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = CompareVersions("v1.2.3-rc.1", "v1.2.3-beta.11")
+	}
+}
@@ -0,0 +1,651 @@
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/alexhokl/helper/httphelper"
+	"github.com/spf13/cobra"
+)
+
+const defaultGitHubAPIURL = "https://api.github.com"
+
+// suffixes of release assets which are never executables
+var nonExecutableAssetSuffixes = []string{
+	".txt", ".sha256", ".sha512", ".sig", ".asc", ".pem", ".sbom", ".json",
+	".deb", ".rpm", ".apk", ".msi", ".pkg", ".dmg",
+}
+
+// aliases of GOOS and GOARCH used in names of release assets
+var (
+	osAliases = map[string][]string{
+		"darwin":  {"darwin", "macos", "osx"},
+		"windows": {"windows", "win"},
+	}
+	archAliases = map[string][]string{
+		"amd64": {"amd64", "x64"},
+		"386":   {"386", "i386", "x86"},
+		"arm64": {"arm64", "aarch64"},
+		"arm":   {"arm", "armv6", "armv7"},
+	}
+)
+
+// ErrChecksumMismatch is returned when the checksum of a downloaded release
+// asset does not match the one in the checksum file of the release
+var ErrChecksumMismatch = errors.New("checksum of downloaded asset does not match")
+
+// Release is a release of a GitHub repository
+type Release struct {
+	TagName    string         `json:"tag_name"`
+	Name       string         `json:"name"`
+	HTMLURL    string         `json:"html_url"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a file attached to a GitHub release
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	// URL is the API URL of the asset which is used for downloading
+	URL                string `json:"url"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
+}
+
+// SignatureVerifier verifies signature of message
+type SignatureVerifier func(message []byte, signature []byte) error
+
+// UpdateOptions configures how GetLatestRelease, CheckForUpdate, SelfUpdate
+// and NewUpdateCommand find, download and verify releases
+type UpdateOptions struct {
+	httpClient        *http.Client
+	apiURL            string
+	token             string
+	includePrerelease bool
+	executablePath    string
+	binaryName        string
+	assetName         string
+	checksumFileName  string
+	signatureSuffix   string
+	signatureVerifier SignatureVerifier
+}
+
+// UpdateOption is a functional option for GetLatestRelease, CheckForUpdate,
+// SelfUpdate and NewUpdateCommand
+type UpdateOption func(*UpdateOptions)
+
+// WithUpdateHTTPClient sets the HTTP client used to call GitHub
+func WithUpdateHTTPClient(client *http.Client) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithGitHubAPIURL sets the base URL of GitHub API, which is useful for
+// GitHub Enterprise Server
+func WithGitHubAPIURL(url string) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.apiURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithGitHubToken sets the token used to call GitHub API, which is required
+// for private repositories and raises the rate limit
+func WithGitHubToken(token string) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.token = token
+	}
+}
+
+// WithPrerelease includes pre-releases when looking for the latest release
+func WithPrerelease() UpdateOption {
+	return func(o *UpdateOptions) {
+		o.includePrerelease = true
+	}
+}
+
+// WithExecutablePath sets the path of executable to be replaced; it defaults
+// to the path of the running executable
+func WithExecutablePath(path string) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.executablePath = path
+	}
+}
+
+// WithBinaryName sets the name of executable in an archived release asset; it
+// defaults to the name of the executable to be replaced
+func WithBinaryName(name string) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.binaryName = name
+	}
+}
+
+// WithAssetName sets the name of release asset to be downloaded instead of
+// the one matching the current operation system and architecture
+func WithAssetName(name string) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.assetName = name
+	}
+}
+
+// WithChecksumFileName sets the name of the checksum file in a release; it
+// defaults to the asset with name ending with checksums.txt or named
+// SHA256SUMS
+func WithChecksumFileName(name string) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.checksumFileName = name
+	}
+}
+
+// WithSignatureVerification requires the checksum file to be signed; the
+// signature is the release asset named as the checksum file with the
+// specified suffix (such as ".sig")
+func WithSignatureVerification(suffix string, verifier SignatureVerifier) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.signatureSuffix = suffix
+		o.signatureVerifier = verifier
+	}
+}
+
+func defaultUpdateOptions() *UpdateOptions {
+	return &UpdateOptions{
+		httpClient: http.DefaultClient,
+		apiURL:     defaultGitHubAPIURL,
+	}
+}
+
+// NewEd25519SignatureVerifier returns a SignatureVerifier of Ed25519
+// signatures in raw or base64 encoded form
+func NewEd25519SignatureVerifier(publicKey ed25519.PublicKey) SignatureVerifier {
+	return func(message []byte, signature []byte) error {
+		if len(signature) != ed25519.SignatureSize {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+			if err != nil {
+				return fmt.Errorf("unable to decode signature: %w", err)
+			}
+			signature = decoded
+		}
+		if !ed25519.Verify(publicKey, message, signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+}
+
+// GetLatestRelease returns the latest release of the specified GitHub
+// repository; drafts are ignored and pre-releases are ignored unless
+// WithPrerelease is specified
+func GetLatestRelease(ctx context.Context, owner string, repo string, opts ...UpdateOption) (*Release, error) {
+	options := defaultUpdateOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return getLatestRelease(ctx, owner, repo, options)
+}
+
+func getLatestRelease(ctx context.Context, owner string, repo string, options *UpdateOptions) (*Release, error) {
+	if !options.includePrerelease {
+		var release Release
+		if err := getGitHubJSON(ctx, options, fmt.Sprintf("/repos/%s/%s/releases/latest", owner, repo), &release); err != nil {
+			return nil, err
+		}
+		return &release, nil
+	}
+
+	var releases []Release
+	if err := getGitHubJSON(ctx, options, fmt.Sprintf("/repos/%s/%s/releases?per_page=100", owner, repo), &releases); err != nil {
+		return nil, err
+	}
+	var latest *Release
+	var latestVersion *SemanticVersion
+	for i, release := range releases {
+		if release.Draft {
+			continue
+		}
+		version, err := ParseSemanticVersion(release.TagName)
+		if err != nil {
+			continue
+		}
+		if latest == nil || version.Compare(*latestVersion) > 0 {
+			latest = &releases[i]
+			latestVersion = version
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no release is found in %s/%s", owner, repo)
+	}
+	return latest, nil
+}
+
+// CheckForUpdate returns the latest release of the specified GitHub
+// repository and whether its version is newer than currentVersion
+func CheckForUpdate(ctx context.Context, owner string, repo string, currentVersion string, opts ...UpdateOption) (*Release, bool, error) {
+	options := defaultUpdateOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return checkForUpdate(ctx, owner, repo, currentVersion, options)
+}
+
+func checkForUpdate(ctx context.Context, owner string, repo string, currentVersion string, options *UpdateOptions) (*Release, bool, error) {
+	current, err := ParseSemanticVersion(currentVersion)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to parse current version: %w", err)
+	}
+	release, err := getLatestRelease(ctx, owner, repo, options)
+	if err != nil {
+		return nil, false, err
+	}
+	latest, err := ParseSemanticVersion(release.TagName)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to parse version of release: %w", err)
+	}
+	return release, latest.Compare(*current) > 0, nil
+}
+
+// SelfUpdate replaces the running executable with the matching asset of the
+// latest release of the specified GitHub repository if its version is newer
+// than currentVersion. The asset is verified against the checksum file (and
+// its signature if WithSignatureVerification is specified) of the release
+// before the executable is replaced atomically. It returns the latest release
+// and whether the executable has been replaced.
+func SelfUpdate(ctx context.Context, owner string, repo string, currentVersion string, opts ...UpdateOption) (*Release, bool, error) {
+	options := defaultUpdateOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	release, isNewer, err := checkForUpdate(ctx, owner, repo, currentVersion, options)
+	if err != nil || !isNewer {
+		return release, false, err
+	}
+
+	executablePath := options.executablePath
+	if executablePath == "" {
+		executablePath, err = os.Executable()
+		if err != nil {
+			return release, false, fmt.Errorf("unable to find path of executable: %w", err)
+		}
+	}
+	executablePath, err = filepath.EvalSymlinks(executablePath)
+	if err != nil {
+		return release, false, fmt.Errorf("unable to resolve path of executable: %w", err)
+	}
+
+	asset, err := findReleaseAsset(release, options.assetName)
+	if err != nil {
+		return release, false, err
+	}
+	content, err := downloadVerifiedAsset(ctx, release, asset, options)
+	if err != nil {
+		return release, false, err
+	}
+
+	binaryName := options.binaryName
+	if binaryName == "" {
+		binaryName = strings.TrimSuffix(filepath.Base(executablePath), ".exe")
+	}
+	binary, err := extractExecutable(asset.Name, content, binaryName)
+	if err != nil {
+		return release, false, err
+	}
+	if err := replaceExecutable(executablePath, binary); err != nil {
+		return release, false, err
+	}
+	return release, true, nil
+}
+
+func findReleaseAsset(release *Release, assetName string) (*ReleaseAsset, error) {
+	if assetName != "" {
+		for i, asset := range release.Assets {
+			if asset.Name == assetName {
+				return &release.Assets[i], nil
+			}
+		}
+		return nil, fmt.Errorf("asset [%s] is not found in release %s", assetName, release.TagName)
+	}
+	return SelectReleaseAsset(release, runtime.GOOS, runtime.GOARCH)
+}
+
+// SelectReleaseAsset returns the asset of release built for the specified
+// operation system and architecture according to its name (such as
+// app_linux_amd64.tar.gz or app-Darwin-x86_64.zip); checksum, signature and
+// package files are not considered
+func SelectReleaseAsset(release *Release, goos string, goarch string) (*ReleaseAsset, error) {
+	osNames := osAliases[goos]
+	if osNames == nil {
+		osNames = []string{goos}
+	}
+	archNames := archAliases[goarch]
+	if archNames == nil {
+		archNames = []string{goarch}
+	}
+	if goos == "darwin" {
+		archNames = append(archNames, "all", "universal")
+	}
+
+	for i, asset := range release.Assets {
+		name := strings.ToLower(asset.Name)
+		if hasAnySuffix(name, nonExecutableAssetSuffixes) {
+			continue
+		}
+		name = strings.NewReplacer("x86_64", "amd64", "x86-64", "amd64").Replace(name)
+		tokens := strings.FieldsFunc(name, func(r rune) bool {
+			return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+		})
+		if containsAny(tokens, osNames) && containsAny(tokens, archNames) {
+			return &release.Assets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no asset of release %s is built for %s/%s", release.TagName, goos, goarch)
+}
+
+func downloadVerifiedAsset(ctx context.Context, release *Release, asset *ReleaseAsset, options *UpdateOptions) ([]byte, error) {
+	checksumAsset, err := findChecksumAsset(release, asset, options.checksumFileName)
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := downloadAsset(ctx, options, checksumAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.signatureVerifier != nil {
+		signatureAsset, err := findReleaseAsset(release, checksumAsset.Name+options.signatureSuffix)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find signature of checksum file: %w", err)
+		}
+		signature, err := downloadAsset(ctx, options, signatureAsset)
+		if err != nil {
+			return nil, err
+		}
+		if err := options.signatureVerifier(checksums, signature); err != nil {
+			return nil, fmt.Errorf("unable to verify signature of checksum file: %w", err)
+		}
+	}
+
+	expected, err := findChecksum(checksums, asset.Name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := downloadAsset(ctx, options, asset)
+	if err != nil {
+		return nil, err
+	}
+	actual := sha256.Sum256(content)
+	if !strings.EqualFold(hex.EncodeToString(actual[:]), expected) {
+		return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, asset.Name)
+	}
+	return content, nil
+}
+
+func findChecksumAsset(release *Release, asset *ReleaseAsset, checksumFileName string) (*ReleaseAsset, error) {
+	if checksumFileName != "" {
+		return findReleaseAsset(release, checksumFileName)
+	}
+	for i, a := range release.Assets {
+		name := strings.ToLower(a.Name)
+		if strings.HasSuffix(name, "checksums.txt") || name == "sha256sums" || name == strings.ToLower(asset.Name)+".sha256" {
+			return &release.Assets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no checksum file is found in release %s", release.TagName)
+}
+
+// findChecksum returns the SHA-256 checksum of the specified file from the
+// content of a checksum file in format of sha256sum, which may contain only
+// the checksum of a single file
+func findChecksum(checksums []byte, fileName string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	var lines [][]string
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			lines = append(lines, fields)
+		}
+	}
+	for _, fields := range lines {
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == fileName {
+			return fields[0], nil
+		}
+	}
+	if len(lines) == 1 && len(lines[0]) == 1 {
+		return lines[0][0], nil
+	}
+	return "", fmt.Errorf("checksum of [%s] is not found", fileName)
+}
+
+// extractExecutable returns the content of the executable with the specified
+// name from a tar.gz or zip asset, or the asset itself if it is not an
+// archive
+func extractExecutable(assetName string, content []byte, binaryName string) ([]byte, error) {
+	name := strings.ToLower(assetName)
+	isBinary := func(p string) bool {
+		base := path.Base(strings.ReplaceAll(p, "\\", "/"))
+		return base == binaryName || base == binaryName+".exe"
+	}
+
+	switch {
+	case strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decompress %s: %w", assetName, err)
+		}
+		defer gz.Close()
+		tr := tar.NewReader(gz)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unable to read %s: %w", assetName, err)
+			}
+			if header.Typeflag == tar.TypeReg && isBinary(header.Name) {
+				return io.ReadAll(tr)
+			}
+		}
+	case strings.HasSuffix(name, ".zip"):
+		zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", assetName, err)
+		}
+		for _, file := range zr.File {
+			if file.Mode().IsRegular() && isBinary(file.Name) {
+				rc, err := file.Open()
+				if err != nil {
+					return nil, fmt.Errorf("unable to read %s: %w", assetName, err)
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+	default:
+		return content, nil
+	}
+	return nil, fmt.Errorf("executable [%s] is not found in %s", binaryName, assetName)
+}
+
+// replaceExecutable writes content to a temporary file in the directory of
+// the executable and renames it to the executable so that the executable is
+// never partially written; on Windows, the running executable is renamed
+// before it is replaced as it cannot be overwritten
+func replaceExecutable(executablePath string, content []byte) error {
+	info, err := os.Stat(executablePath)
+	if err != nil {
+		return fmt.Errorf("unable to read executable: %w", err)
+	}
+
+	directory := filepath.Dir(executablePath)
+	file, err := os.CreateTemp(directory, "."+filepath.Base(executablePath)+".new-*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file: %w", err)
+	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath)
+
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("unable to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("unable to write temporary file: %w", err)
+	}
+	if err := os.Chmod(tempPath, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("unable to set permission of executable: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		oldPath := executablePath + ".old"
+		_ = os.Remove(oldPath)
+		if err := os.Rename(executablePath, oldPath); err != nil {
+			return fmt.Errorf("unable to move executable: %w", err)
+		}
+		if err := os.Rename(tempPath, executablePath); err != nil {
+			_ = os.Rename(oldPath, executablePath)
+			return fmt.Errorf("unable to replace executable: %w", err)
+		}
+		return nil
+	}
+	if err := os.Rename(tempPath, executablePath); err != nil {
+		return fmt.Errorf("unable to replace executable: %w", err)
+	}
+	return nil
+}
+
+func getGitHubJSON(ctx context.Context, options *UpdateOptions, apiPath string, v any) error {
+	body, err := sendGitHubRequest(ctx, options, options.apiURL+apiPath, "application/vnd.github+json")
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("unable to parse response of %s: %w", apiPath, err)
+	}
+	return nil
+}
+
+// downloadAsset downloads asset from its API URL as browser download URLs
+// do not accept tokens of private repositories; the API redirects to the
+// storage of GitHub and the token is not sent to other hosts on redirects
+func downloadAsset(ctx context.Context, options *UpdateOptions, asset *ReleaseAsset) ([]byte, error) {
+	url := asset.URL
+	if url == "" {
+		url = asset.BrowserDownloadURL
+	}
+	content, err := sendGitHubRequest(ctx, options, url, "application/octet-stream")
+	if err != nil {
+		return nil, fmt.Errorf("unable to download %s: %w", asset.Name, err)
+	}
+	return content, nil
+}
+
+func sendGitHubRequest(ctx context.Context, options *UpdateOptions, url string, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	if options.token != "" {
+		httphelper.SetBearerTokenHeader(req, options.token)
+	}
+
+	resp, err := options.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send request to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if !httphelper.IsSuccessResponse(resp) {
+		return nil, fmt.Errorf("unexpected status [%s] from %s", resp.Status, url)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response from %s: %w", url, err)
+	}
+	return body, nil
+}
+
+// NewUpdateCommand returns an update command which replaces the running
+// executable with the latest release of the specified GitHub repository, or
+// only reports whether a newer release is available with flag --check
+//
+// Example:
+//
+//	func init() {
+//		rootCmd.AddCommand(cli.NewUpdateCommand("alexhokl", "my-app", version))
+//	}
+func NewUpdateCommand(owner string, repo string, currentVersion string, opts ...UpdateOption) *cobra.Command {
+	var checkOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update to the latest release",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			if checkOnly {
+				release, isNewer, err := CheckForUpdate(cmd.Context(), owner, repo, currentVersion, opts...)
+				if err != nil {
+					return err
+				}
+				if !isNewer {
+					_, err = fmt.Fprintf(out, "Already up to date (%s)\n", currentVersion)
+					return err
+				}
+				_, err = fmt.Fprintf(out, "A new version %s is available: %s\n", release.TagName, release.HTMLURL)
+				return err
+			}
+
+			release, isUpdated, err := SelfUpdate(cmd.Context(), owner, repo, currentVersion, opts...)
+			if err != nil {
+				return err
+			}
+			if !isUpdated {
+				_, err = fmt.Fprintf(out, "Already up to date (%s)\n", currentVersion)
+				return err
+			}
+			_, err = fmt.Fprintf(out, "Updated from %s to %s\n", currentVersion, release.TagName)
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&checkOnly, "check", false, "Check for a newer release without updating")
+
+	return cmd
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, v := range values {
+		for _, c := range candidates {
+			if v == c {
+				return true
+			}
+		}
+	}
+	return false
+}
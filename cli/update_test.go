@@ -0,0 +1,562 @@
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type testReleaseServer struct {
+	*httptest.Server
+	releases      []Release
+	files         map[string][]byte
+	authorization string
+	// token makes the repository private; API requests require the token
+	// and browser download URLs are not found as in GitHub
+	token string
+}
+
+// newTestReleaseServer serves releases of repository owner/repo with assets
+// of the specified files in the first release
+func newTestReleaseServer(t *testing.T, tags []string, files map[string][]byte) *testReleaseServer {
+	t.Helper()
+	s := &testReleaseServer{files: files}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/releases/assets/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/octet-stream" {
+			http.Error(w, "metadata of assets is not served", http.StatusNotAcceptable)
+			return
+		}
+		http.Redirect(w, r, "/storage/"+strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/releases/assets/"), http.StatusFound)
+	})
+	mux.HandleFunc("/storage/", func(w http.ResponseWriter, r *http.Request) {
+		s.serveFile(w, r, strings.TrimPrefix(r.URL.Path, "/storage/"))
+	})
+	mux.HandleFunc("/repos/owner/repo/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		s.authorization = r.Header.Get("Authorization")
+		for _, release := range s.releases {
+			if !release.Prerelease && !release.Draft {
+				_ = json.NewEncoder(w).Encode(release)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/repos/owner/repo/releases", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(s.releases)
+	})
+	mux.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			http.NotFound(w, r)
+			return
+		}
+		s.serveFile(w, r, strings.TrimPrefix(r.URL.Path, "/download/"))
+	})
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" && strings.HasPrefix(r.URL.Path, "/repos/") && r.Header.Get("Authorization") != "Bearer "+s.token {
+			http.NotFound(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	for i, tag := range tags {
+		release := Release{
+			TagName:    tag,
+			HTMLURL:    "https://github.com/owner/repo/releases/tag/" + tag,
+			Prerelease: strings.Contains(tag, "-"),
+		}
+		if i == 0 {
+			for name, content := range files {
+				release.Assets = append(release.Assets, ReleaseAsset{
+					Name:               name,
+					URL:                s.URL + "/repos/owner/repo/releases/assets/" + name,
+					BrowserDownloadURL: s.URL + "/download/" + name,
+					Size:               int64(len(content)),
+				})
+			}
+		}
+		s.releases = append(s.releases, release)
+	}
+	return s
+}
+
+func (s *testReleaseServer) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	content, ok := s.files[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = w.Write(content)
+}
+
+func createTestTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("unable to write tar header: %v", err)
+		}
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+func createTestZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("unable to create zip entry: %v", err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func createTestExecutable(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("old binary"), 0750); err != nil {
+		t.Fatalf("unable to create executable: %v", err)
+	}
+	return path
+}
+
+func currentPlatformAssetName(extension string) string {
+	return fmt.Sprintf("app_%s_%s%s", runtime.GOOS, runtime.GOARCH, extension)
+}
+
+func TestSelectReleaseAsset(t *testing.T) {
+	release := &Release{
+		TagName: "v1.0.0",
+		Assets: []ReleaseAsset{
+			{Name: "checksums.txt"},
+			{Name: "app_linux_amd64.tar.gz.sig"},
+			{Name: "app_1.0.0_amd64.deb"},
+			{Name: "app_Linux_x86_64.tar.gz"},
+			{Name: "app_Linux_arm64.tar.gz"},
+			{Name: "app_Linux_i386.tar.gz"},
+			{Name: "app-darwin-all.zip"},
+			{Name: "app_Windows_x86_64.zip"},
+		},
+	}
+
+	tests := []struct {
+		goos    string
+		goarch  string
+		want    string
+		wantErr bool
+	}{
+		{"linux", "amd64", "app_Linux_x86_64.tar.gz", false},
+		{"linux", "arm64", "app_Linux_arm64.tar.gz", false},
+		{"linux", "386", "app_Linux_i386.tar.gz", false},
+		{"darwin", "arm64", "app-darwin-all.zip", false},
+		{"windows", "amd64", "app_Windows_x86_64.zip", false},
+		{"windows", "arm64", "", true},
+		{"freebsd", "amd64", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos+"/"+tt.goarch, func(t *testing.T) {
+			got, err := SelectReleaseAsset(release, tt.goos, tt.goarch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectReleaseAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Name != tt.want {
+				t.Errorf("SelectReleaseAsset() = %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLatestRelease(t *testing.T) {
+	server := newTestReleaseServer(t, []string{"v2.0.0-rc.1", "v1.10.0", "v1.9.0"}, nil)
+
+	release, err := GetLatestRelease(context.Background(), "owner", "repo", WithGitHubAPIURL(server.URL+"/"), WithGitHubToken("secret"))
+	if err != nil {
+		t.Fatalf("GetLatestRelease() error: %v", err)
+	}
+	if release.TagName != "v1.10.0" {
+		t.Errorf("TagName = %s, want v1.10.0", release.TagName)
+	}
+	if server.authorization != "Bearer secret" {
+		t.Errorf("Authorization = %q, want bearer token", server.authorization)
+	}
+
+	release, err = GetLatestRelease(context.Background(), "owner", "repo", WithGitHubAPIURL(server.URL), WithPrerelease())
+	if err != nil {
+		t.Fatalf("GetLatestRelease() error: %v", err)
+	}
+	if release.TagName != "v2.0.0-rc.1" {
+		t.Errorf("TagName = %s, want v2.0.0-rc.1", release.TagName)
+	}
+
+	if _, err := GetLatestRelease(context.Background(), "owner", "missing", WithGitHubAPIURL(server.URL)); err == nil {
+		t.Error("GetLatestRelease() of missing repository should return error")
+	}
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	server := newTestReleaseServer(t, []string{"v1.2.0"}, nil)
+
+	tests := []struct {
+		current    string
+		wantNewer  bool
+		wantErrStr string
+	}{
+		{"v1.1.9", true, ""},
+		{"1.2.0", false, ""},
+		{"v1.3.0-rc.1", false, ""},
+		{"dev", false, "unable to parse current version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			_, isNewer, err := CheckForUpdate(context.Background(), "owner", "repo", tt.current, WithGitHubAPIURL(server.URL))
+			if tt.wantErrStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrStr) {
+					t.Errorf("CheckForUpdate() error = %v, want %q", err, tt.wantErrStr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckForUpdate() error: %v", err)
+			}
+			if isNewer != tt.wantNewer {
+				t.Errorf("CheckForUpdate() = %v, want %v", isNewer, tt.wantNewer)
+			}
+		})
+	}
+}
+
+func TestSelfUpdateFromTarGz(t *testing.T) {
+	asset := createTestTarGz(t, map[string]string{"README.md": "readme", "app_dir/app": "new binary"})
+	assetName := currentPlatformAssetName(".tar.gz")
+	checksums := fmt.Sprintf("%s  other.tar.gz\n%s *%s\n", sha256Hex([]byte("x")), sha256Hex(asset), assetName)
+	server := newTestReleaseServer(t, []string{"v1.1.0"}, map[string][]byte{
+		assetName:                 asset,
+		"app_1.1.0_checksums.txt": []byte(checksums),
+	})
+	executable := createTestExecutable(t, "app")
+
+	release, isUpdated, err := SelfUpdate(context.Background(), "owner", "repo", "v1.0.0", WithGitHubAPIURL(server.URL), WithExecutablePath(executable))
+	if err != nil {
+		t.Fatalf("SelfUpdate() error: %v", err)
+	}
+	if !isUpdated || release.TagName != "v1.1.0" {
+		t.Errorf("SelfUpdate() = %s, %v, want v1.1.0, true", release.TagName, isUpdated)
+	}
+
+	content, _ := os.ReadFile(executable)
+	if string(content) != "new binary" {
+		t.Errorf("executable = %q, want new binary", content)
+	}
+	info, _ := os.Stat(executable)
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0750 {
+		t.Errorf("permission = %v, want permission of original executable", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(executable))
+	if len(entries) != 1 {
+		t.Errorf("directory of executable should not contain temporary files: %v", entries)
+	}
+}
+
+func TestSelfUpdateFromZipWithBinaryName(t *testing.T) {
+	asset := createTestZip(t, map[string]string{"tool.exe": "new binary"})
+	server := newTestReleaseServer(t, []string{"v1.1.0"}, map[string][]byte{
+		"bundle.zip":        asset,
+		"bundle.zip.sha256": []byte(sha256Hex(asset) + "\n"),
+	})
+	executable := createTestExecutable(t, "app")
+
+	_, isUpdated, err := SelfUpdate(context.Background(), "owner", "repo", "v1.0.0",
+		WithGitHubAPIURL(server.URL),
+		WithExecutablePath(executable),
+		WithAssetName("bundle.zip"),
+		WithBinaryName("tool"),
+	)
+	if err != nil {
+		t.Fatalf("SelfUpdate() error: %v", err)
+	}
+	content, _ := os.ReadFile(executable)
+	if !isUpdated || string(content) != "new binary" {
+		t.Errorf("executable = %q, updated = %v", content, isUpdated)
+	}
+}
+
+func TestSelfUpdateWithSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	asset := []byte("new binary")
+	assetName := currentPlatformAssetName("")
+	checksums := []byte(fmt.Sprintf("%s  %s\n", sha256Hex(asset), assetName))
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, checksums))
+
+	tests := []struct {
+		name      string
+		publicKey ed25519.PublicKey
+		signature []byte
+		wantErr   string
+	}{
+		{"valid signature", publicKey, []byte(signature + "\n"), ""},
+		{"raw signature", publicKey, ed25519.Sign(privateKey, checksums), ""},
+		{"wrong key", otherKey, []byte(signature), "invalid signature"},
+		{"missing signature", publicKey, nil, "unable to find signature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string][]byte{assetName: asset, "checksums.txt": checksums}
+			if tt.signature != nil {
+				files["checksums.txt.sig"] = tt.signature
+			}
+			server := newTestReleaseServer(t, []string{"v1.1.0"}, files)
+			executable := createTestExecutable(t, "app")
+
+			_, _, err := SelfUpdate(context.Background(), "owner", "repo", "v1.0.0",
+				WithGitHubAPIURL(server.URL),
+				WithExecutablePath(executable),
+				WithSignatureVerification(".sig", NewEd25519SignatureVerifier(tt.publicKey)),
+			)
+			content, _ := os.ReadFile(executable)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("SelfUpdate() error: %v", err)
+				}
+				if string(content) != "new binary" {
+					t.Errorf("executable = %q, want new binary", content)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SelfUpdate() error = %v, want %q", err, tt.wantErr)
+			}
+			if string(content) != "old binary" {
+				t.Errorf("executable = %q, should not be replaced", content)
+			}
+		})
+	}
+}
+
+func TestSelfUpdateFromPrivateRepository(t *testing.T) {
+	asset := []byte("new binary")
+	assetName := currentPlatformAssetName("")
+	server := newTestReleaseServer(t, []string{"v1.1.0"}, map[string][]byte{
+		assetName:       asset,
+		"checksums.txt": []byte(sha256Hex(asset) + "  " + assetName),
+	})
+	server.token = "secret"
+	executable := createTestExecutable(t, "app")
+
+	if _, _, err := SelfUpdate(context.Background(), "owner", "repo", "v1.0.0", WithGitHubAPIURL(server.URL), WithExecutablePath(executable)); err == nil {
+		t.Error("SelfUpdate() without token should return error")
+	}
+
+	_, isUpdated, err := SelfUpdate(context.Background(), "owner", "repo", "v1.0.0", WithGitHubAPIURL(server.URL), WithExecutablePath(executable), WithGitHubToken("secret"))
+	if err != nil {
+		t.Fatalf("SelfUpdate() error: %v", err)
+	}
+	content, _ := os.ReadFile(executable)
+	if !isUpdated || string(content) != "new binary" {
+		t.Errorf("SelfUpdate() = %v with executable %q, want true with new binary", isUpdated, content)
+	}
+}
+
+func TestSelfUpdateFailures(t *testing.T) {
+	assetName := currentPlatformAssetName("")
+	asset := []byte("new binary")
+	archive := createTestTarGz(t, map[string]string{"other": "x"})
+
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		opts    []UpdateOption
+		wantErr string
+	}{
+		{
+			"checksum mismatch",
+			map[string][]byte{assetName: asset, "checksums.txt": []byte(sha256Hex([]byte("other")) + "  " + assetName)},
+			nil,
+			ErrChecksumMismatch.Error(),
+		},
+		{
+			"missing checksum file",
+			map[string][]byte{assetName: asset},
+			nil,
+			"no checksum file",
+		},
+		{
+			"missing checksum of asset",
+			map[string][]byte{assetName: asset, "checksums.txt": []byte(sha256Hex(asset) + "  other")},
+			nil,
+			"checksum of [" + assetName + "] is not found",
+		},
+		{
+			"missing asset",
+			map[string][]byte{"checksums.txt": nil},
+			[]UpdateOption{WithAssetName("missing.tar.gz")},
+			"asset [missing.tar.gz] is not found",
+		},
+		{
+			"binary not in archive",
+			map[string][]byte{"app.tar.gz": archive, "SHA256SUMS": []byte(sha256Hex(archive) + "  app.tar.gz")},
+			[]UpdateOption{WithAssetName("app.tar.gz")},
+			"executable [app] is not found in app.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestReleaseServer(t, []string{"v1.1.0"}, tt.files)
+			executable := createTestExecutable(t, "app")
+			opts := append([]UpdateOption{WithGitHubAPIURL(server.URL), WithExecutablePath(executable)}, tt.opts...)
+
+			_, isUpdated, err := SelfUpdate(context.Background(), "owner", "repo", "v1.0.0", opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SelfUpdate() error = %v, want %q", err, tt.wantErr)
+			}
+			if isUpdated {
+				t.Error("SelfUpdate() should not report update")
+			}
+			content, _ := os.ReadFile(executable)
+			if string(content) != "old binary" {
+				t.Errorf("executable = %q, should not be replaced", content)
+			}
+		})
+	}
+}
+
+func TestSelfUpdateChecksumMismatchIsTyped(t *testing.T) {
+	assetName := currentPlatformAssetName("")
+	server := newTestReleaseServer(t, []string{"v1.1.0"}, map[string][]byte{
+		assetName:       []byte("new binary"),
+		"checksums.txt": []byte(sha256Hex([]byte("other")) + "  " + assetName),
+	})
+
+	_, _, err := SelfUpdate(context.Background(), "owner", "repo", "v1.0.0", WithGitHubAPIURL(server.URL), WithExecutablePath(createTestExecutable(t, "app")))
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("SelfUpdate() error = %v, want %v", err, ErrChecksumMismatch)
+	}
+}
+
+func TestSelfUpdateAlreadyUpToDate(t *testing.T) {
+	server := newTestReleaseServer(t, []string{"v1.0.0"}, nil)
+	executable := createTestExecutable(t, "app")
+
+	release, isUpdated, err := SelfUpdate(context.Background(), "owner", "repo", "v1.0.0", WithGitHubAPIURL(server.URL), WithExecutablePath(executable))
+	if err != nil {
+		t.Fatalf("SelfUpdate() error: %v", err)
+	}
+	if isUpdated || release.TagName != "v1.0.0" {
+		t.Errorf("SelfUpdate() = %s, %v, want v1.0.0, false", release.TagName, isUpdated)
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	checksums := []byte("abc  app.tar.gz\ndef *app.zip\n\n")
+
+	tests := []struct {
+		checksums []byte
+		name      string
+		want      string
+		wantErr   bool
+	}{
+		{checksums, "app.tar.gz", "abc", false},
+		{checksums, "app.zip", "def", false},
+		{checksums, "app", "", true},
+		{[]byte("abc\n"), "app", "abc", false},
+		{nil, "app", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findChecksum(tt.checksums, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("findChecksum() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateCommand(t *testing.T) {
+	assetName := currentPlatformAssetName("")
+	asset := []byte("new binary")
+	server := newTestReleaseServer(t, []string{"v1.1.0"}, map[string][]byte{
+		assetName:       asset,
+		"checksums.txt": []byte(sha256Hex(asset) + "  " + assetName),
+	})
+	executable := createTestExecutable(t, "app")
+
+	tests := []struct {
+		args    []string
+		current string
+		want    string
+	}{
+		{[]string{"--check"}, "v1.0.0", "A new version v1.1.0 is available: https://github.com/owner/repo/releases/tag/v1.1.0\n"},
+		{[]string{"--check"}, "v1.1.0", "Already up to date (v1.1.0)\n"},
+		{nil, "v1.0.0", "Updated from v1.0.0 to v1.1.0\n"},
+		{nil, "v1.1.0", "Already up to date (v1.1.0)\n"},
+	}
+	for _, tt := range tests {
+		cmd := NewUpdateCommand("owner", "repo", tt.current, WithGitHubAPIURL(server.URL), WithExecutablePath(executable))
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(tt.args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("update %v returned error: %v", tt.args, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("output = %q, want %q", out.String(), tt.want)
+		}
+	}
+}
+
+func BenchmarkSelectReleaseAsset(b *testing.B) {
+	release := &Release{}
+	for _, goos := range []string{"linux", "darwin", "windows", "freebsd"} {
+		for _, arch := range []string{"x86_64", "arm64", "i386", "armv7"} {
+			release.Assets = append(release.Assets, ReleaseAsset{Name: fmt.Sprintf("app_%s_%s.tar.gz", goos, arch)})
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = SelectReleaseAsset(release, "windows", "arm64")
+	}
+}
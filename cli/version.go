@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FormatText is the human readable format of version command
+	FormatText = "text"
+
+	defaultVersion = "dev"
+	develVersion   = "(devel)"
+)
+
+// BuildInfo describes the build of the running executable
+type BuildInfo struct {
+	Version   string    `json:"version" yaml:"version"`
+	Revision  string    `json:"revision,omitempty" yaml:"revision,omitempty"`
+	Dirty     bool      `json:"dirty" yaml:"dirty"`
+	BuildTime time.Time `json:"build_time,omitzero" yaml:"build_time,omitempty"`
+	GoVersion string    `json:"go_version" yaml:"go_version"`
+	Platform  string    `json:"platform" yaml:"platform"`
+}
+
+// GetBuildInfo returns the build information of the running executable from
+// runtime/debug.ReadBuildInfo. The specified version, which is usually set
+// with -ldflags "-X main.version=...", takes precedence over the module
+// version; dev is returned if neither is available (such as with go run).
+// The build time is the commit time of the VCS revision.
+func GetBuildInfo(version string) BuildInfo {
+	info, _ := debug.ReadBuildInfo()
+	return getBuildInfo(version, info)
+}
+
+func getBuildInfo(version string, info *debug.BuildInfo) BuildInfo {
+	buildInfo := BuildInfo{
+		Version:   version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if info != nil {
+		if buildInfo.Version == "" && info.Main.Version != develVersion {
+			buildInfo.Version = info.Main.Version
+		}
+		if info.GoVersion != "" {
+			buildInfo.GoVersion = info.GoVersion
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				buildInfo.Revision = setting.Value
+			case "vcs.modified":
+				buildInfo.Dirty = setting.Value == "true"
+			case "vcs.time":
+				if t, err := time.Parse(time.RFC3339, setting.Value); err == nil {
+					buildInfo.BuildTime = t
+				}
+			}
+		}
+	}
+	if buildInfo.Version == "" {
+		buildInfo.Version = defaultVersion
+	}
+	return buildInfo
+}
+
+// WriteBuildInfo writes info to w in the specified format, which is one of
+// text, json or yaml
+func WriteBuildInfo(w io.Writer, info BuildInfo, format string) error {
+	switch format {
+	case FormatText, "":
+		revision := info.Revision
+		if revision == "" {
+			revision = "unknown"
+		}
+		if info.Dirty {
+			revision += " (dirty)"
+		}
+		buildTime := "unknown"
+		if !info.BuildTime.IsZero() {
+			buildTime = info.BuildTime.UTC().Format(time.RFC3339)
+		}
+		_, err := fmt.Fprintf(w, "Version:    %s\nRevision:   %s\nBuild time: %s\nGo version: %s\nPlatform:   %s\n",
+			info.Version, revision, buildTime, info.GoVersion, info.Platform)
+		return err
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		defer encoder.Close()
+		return encoder.Encode(info)
+	default:
+		return fmt.Errorf("unsupported format [%s] (supported formats: text, json, yaml)", format)
+	}
+}
+
+// NewVersionCommand returns a version command which prints the build
+// information of the running executable (see GetBuildInfo) in text, json or
+// yaml format
+//
+// Example:
+//
+//	var version string // set by -ldflags "-X main.version=v1.2.3"
+//
+//	func init() {
+//		rootCmd.AddCommand(cli.NewVersionCommand(version))
+//	}
+func NewVersionCommand(version string) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version and build information",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return WriteBuildInfo(cmd.OutOrStdout(), GetBuildInfo(version), format)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", FormatText, "Output format (text, json or yaml)")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{FormatText, FormatJSON, FormatYAML}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestBuildInfo(version string, settings ...debug.BuildSetting) *debug.BuildInfo {
+	return &debug.BuildInfo{
+		GoVersion: "go1.25.5",
+		Main:      debug.Module{Path: "github.com/alexhokl/app", Version: version},
+		Settings:  settings,
+	}
+}
+
+func TestGetBuildInfo(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs", Value: "git"},
+		{Key: "vcs.revision", Value: "0123456789abcdef"},
+		{Key: "vcs.time", Value: "2025-01-02T03:04:05Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	tests := []struct {
+		name        string
+		version     string
+		info        *debug.BuildInfo
+		wantVersion string
+		wantDirty   bool
+		wantCommit  string
+	}{
+		{"version from ldflags", "v1.2.3", newTestBuildInfo("v1.0.0", settings...), "v1.2.3", true, "0123456789abcdef"},
+		{"module version", "", newTestBuildInfo("v1.0.0"), "v1.0.0", false, ""},
+		{"devel build", "", newTestBuildInfo("(devel)", settings...), "dev", true, "0123456789abcdef"},
+		{"no build info", "", nil, "dev", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getBuildInfo(tt.version, tt.info)
+			if got.Version != tt.wantVersion {
+				t.Errorf("Version = %q, want %q", got.Version, tt.wantVersion)
+			}
+			if got.Dirty != tt.wantDirty {
+				t.Errorf("Dirty = %v, want %v", got.Dirty, tt.wantDirty)
+			}
+			if got.Revision != tt.wantCommit {
+				t.Errorf("Revision = %q, want %q", got.Revision, tt.wantCommit)
+			}
+			if got.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+				t.Errorf("Platform = %q", got.Platform)
+			}
+		})
+	}
+
+	got := getBuildInfo("", newTestBuildInfo("", settings...))
+	if !got.BuildTime.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("BuildTime = %v", got.BuildTime)
+	}
+	if got.GoVersion != "go1.25.5" {
+		t.Errorf("GoVersion = %q, want go version of build", got.GoVersion)
+	}
+}
+
+func TestGetBuildInfoOfTestBinary(t *testing.T) {
+	got := GetBuildInfo("v9.9.9")
+	if got.Version != "v9.9.9" {
+		t.Errorf("Version = %q, want v9.9.9", got.Version)
+	}
+	if got.GoVersion == "" {
+		t.Error("GoVersion should not be empty")
+	}
+}
+
+func TestWriteBuildInfo(t *testing.T) {
+	info := BuildInfo{
+		Version:   "v1.2.3",
+		Revision:  "abc123",
+		Dirty:     true,
+		BuildTime: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		GoVersion: "go1.25.5",
+		Platform:  "linux/amd64",
+	}
+
+	tests := []struct {
+		name   string
+		info   BuildInfo
+		format string
+		want   string
+	}{
+		{
+			"text",
+			info,
+			FormatText,
+			"Version:    v1.2.3\nRevision:   abc123 (dirty)\nBuild time: 2025-01-02T03:04:05Z\nGo version: go1.25.5\nPlatform:   linux/amd64\n",
+		},
+		{
+			"text without VCS information",
+			BuildInfo{Version: "dev", GoVersion: "go1.25.5", Platform: "linux/amd64"},
+			"",
+			"Version:    dev\nRevision:   unknown\nBuild time: unknown\nGo version: go1.25.5\nPlatform:   linux/amd64\n",
+		},
+		{
+			"json",
+			info,
+			FormatJSON,
+			`{
+  "version": "v1.2.3",
+  "revision": "abc123",
+  "dirty": true,
+  "build_time": "2025-01-02T03:04:05Z",
+  "go_version": "go1.25.5",
+  "platform": "linux/amd64"
+}
+`,
+		},
+		{
+			"json without VCS information",
+			BuildInfo{Version: "dev", GoVersion: "go1.25.5", Platform: "linux/amd64"},
+			FormatJSON,
+			`{
+  "version": "dev",
+  "dirty": false,
+  "go_version": "go1.25.5",
+  "platform": "linux/amd64"
+}
+`,
+		},
+		{
+			"yaml",
+			info,
+			FormatYAML,
+			"version: v1.2.3\nrevision: abc123\ndirty: true\nbuild_time: 2025-01-02T03:04:05Z\ngo_version: go1.25.5\nplatform: linux/amd64\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteBuildInfo(&buf, tt.info, tt.format); err != nil {
+				t.Fatalf("WriteBuildInfo() error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("WriteBuildInfo() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	if err := WriteBuildInfo(&bytes.Buffer{}, info, "xml"); err == nil {
+		t.Error("WriteBuildInfo() with unsupported format should return error")
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	cmd := NewVersionCommand("v1.2.3")
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("version returned error: %v", err)
+	}
+
+	var info BuildInfo
+	if err := json.Unmarshal(out.Bytes(), &info); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if info.Version != "v1.2.3" {
+		t.Errorf("Version = %q, want v1.2.3", info.Version)
+	}
+
+	cmd = NewVersionCommand("v1.2.3")
+	out.Reset()
+	cmd.SetOut(&out)
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("version returned error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Version:    v1.2.3\n") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func BenchmarkGetBuildInfo(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		GetBuildInfo("")
+	}
+}
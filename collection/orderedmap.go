@@ -0,0 +1,235 @@
+package collection
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+)
+
+type orderedMapEntry[K comparable, V any] struct {
+	key   K
+	value V
+	prev  *orderedMapEntry[K, V]
+	next  *orderedMapEntry[K, V]
+}
+
+// OrderedMap is a map which iterates in insertion order of its keys; the zero
+// value is an empty map ready to use. It marshals to and unmarshals from a
+// JSON object preserving the order of keys, which is supported for keys of
+// string, integer or encoding.TextMarshaler types as in encoding/json.
+type OrderedMap[K comparable, V any] struct {
+	entries map[K]*orderedMapEntry[K, V]
+	head    *orderedMapEntry[K, V]
+	tail    *orderedMapEntry[K, V]
+}
+
+// NewOrderedMap returns an empty ordered map
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{entries: make(map[K]*orderedMapEntry[K, V])}
+}
+
+// Set sets the value of key; a new key is appended to the end while an
+// existing key keeps its position
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if entry, ok := m.entries[key]; ok {
+		entry.value = value
+		return
+	}
+	if m.entries == nil {
+		m.entries = make(map[K]*orderedMapEntry[K, V])
+	}
+	entry := &orderedMapEntry[K, V]{key: key, value: value, prev: m.tail}
+	if m.tail == nil {
+		m.head = entry
+	} else {
+		m.tail.next = entry
+	}
+	m.tail = entry
+	m.entries[key] = entry
+}
+
+// Get returns the value of key and whether the key exists
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if entry, ok := m.entries[key]; ok {
+		return entry.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Has returns true if key exists
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := m.entries[key]
+	return ok
+}
+
+// Delete removes key and returns whether it existed
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	entry, ok := m.entries[key]
+	if !ok {
+		return false
+	}
+	if entry.prev == nil {
+		m.head = entry.next
+	} else {
+		entry.prev.next = entry.next
+	}
+	if entry.next == nil {
+		m.tail = entry.prev
+	} else {
+		entry.next.prev = entry.prev
+	}
+	delete(m.entries, key)
+	return true
+}
+
+// Len returns the number of keys
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// Keys returns the keys in insertion order
+func (m *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m.entries))
+	for entry := m.head; entry != nil; entry = entry.next {
+		keys = append(keys, entry.key)
+	}
+	return keys
+}
+
+// Values returns the values in insertion order of their keys
+func (m *OrderedMap[K, V]) Values() []V {
+	values := make([]V, 0, len(m.entries))
+	for entry := m.head; entry != nil; entry = entry.next {
+		values = append(values, entry.value)
+	}
+	return values
+}
+
+// All returns an iterator over the keys and values in insertion order; it is
+// safe to delete the current key during iteration
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for entry := m.head; entry != nil; {
+			next := entry.next
+			if !yield(entry.key, entry.value) {
+				return
+			}
+			entry = next
+		}
+	}
+}
+
+// MarshalJSON marshals the map as a JSON object with keys in insertion order;
+// it has a value receiver so that maps held by value in structs are marshalled
+func (m OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for entry := m.head; entry != nil; entry = entry.next {
+		if entry != m.head {
+			buf.WriteByte(',')
+		}
+		key, err := marshalMapKey(entry.key)
+		if err != nil {
+			return nil, err
+		}
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueJSON, err := json.Marshal(entry.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+		buf.Write(valueJSON)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON sets the keys and values of a JSON object in the order they
+// appear; existing keys keep their positions
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected a JSON object but got %v", token)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var key K
+		if err := unmarshalMapKey(token.(string), &key); err != nil {
+			return err
+		}
+		var value V
+		if err := decoder.Decode(&value); err != nil {
+			return fmt.Errorf("unable to decode value of key [%s]: %w", token, err)
+		}
+		m.Set(key, value)
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+// marshalMapKey converts key to a string as encoding/json does for map keys
+func marshalMapKey(key any) (string, error) {
+	if tm, ok := key.(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	v := reflect.ValueOf(key)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// unmarshalMapKey converts s to the key pointed by key as encoding/json does
+// for map keys
+func unmarshalMapKey(s string, key any) error {
+	if tu, ok := key.(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(s))
+	}
+	v := reflect.ValueOf(key).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid key [%s] for type %s", s, v.Type())
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid key [%s] for type %s", s, v.Type())
+		}
+		v.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported key type %s", v.Type())
+	}
+	return nil
+}
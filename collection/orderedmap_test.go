@@ -0,0 +1,267 @@
+package collection
+
+import (
+	"encoding/json"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func newTestOrderedMap() *OrderedMap[string, int] {
+	m := NewOrderedMap[string, int]()
+	m.Set("zulu", 1)
+	m.Set("alpha", 2)
+	m.Set("mike", 3)
+	return m
+}
+
+func TestOrderedMap(t *testing.T) {
+	m := newTestOrderedMap()
+
+	if !slices.Equal(m.Keys(), []string{"zulu", "alpha", "mike"}) {
+		t.Errorf("Expected keys in insertion order but got %v", m.Keys())
+	}
+	if !slices.Equal(m.Values(), []int{1, 2, 3}) {
+		t.Errorf("Expected values in insertion order but got %v", m.Values())
+	}
+
+	m.Set("zulu", 10)
+	if v, ok := m.Get("zulu"); !ok || v != 10 {
+		t.Errorf("Expected updated value 10 but got %d, %v", v, ok)
+	}
+	if m.Keys()[0] != "zulu" {
+		t.Errorf("Expected updated key to keep position but got %v", m.Keys())
+	}
+	if _, ok := m.Get("missing"); ok {
+		t.Error("Expected missing key not to be found")
+	}
+	if !m.Has("alpha") || m.Has("missing") {
+		t.Error("Unexpected result of Has")
+	}
+}
+
+func TestOrderedMapDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		expected []string
+		deleted  bool
+	}{
+		{"head", "zulu", []string{"alpha", "mike"}, true},
+		{"middle", "alpha", []string{"zulu", "mike"}, true},
+		{"tail", "mike", []string{"zulu", "alpha"}, true},
+		{"missing", "missing", []string{"zulu", "alpha", "mike"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestOrderedMap()
+			if deleted := m.Delete(tt.key); deleted != tt.deleted {
+				t.Errorf("Expected Delete() = %v but got %v", tt.deleted, deleted)
+			}
+			if !slices.Equal(m.Keys(), tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, m.Keys())
+			}
+			if m.Len() != len(tt.expected) {
+				t.Errorf("Expected length %d but got %d", len(tt.expected), m.Len())
+			}
+
+			m.Set("new", 4)
+			if keys := m.Keys(); keys[len(keys)-1] != "new" {
+				t.Errorf("Expected new key at the end but got %v", keys)
+			}
+		})
+	}
+}
+
+func TestOrderedMapDeleteAll(t *testing.T) {
+	m := newTestOrderedMap()
+	for key := range m.All() {
+		m.Delete(key)
+	}
+	if m.Len() != 0 || len(m.Keys()) != 0 {
+		t.Errorf("Expected empty map but got %v", m.Keys())
+	}
+
+	m.Set("again", 1)
+	if !slices.Equal(m.Keys(), []string{"again"}) {
+		t.Errorf("Expected [again] but got %v", m.Keys())
+	}
+}
+
+func TestOrderedMapZeroValue(t *testing.T) {
+	var m OrderedMap[int, string]
+	if _, ok := m.Get(1); ok || m.Delete(1) {
+		t.Error("Expected zero value to be empty")
+	}
+	m.Set(1, "one")
+	if v, _ := m.Get(1); v != "one" {
+		t.Errorf("Expected zero value to be usable but got %q", v)
+	}
+}
+
+func TestOrderedMapAllStopsEarly(t *testing.T) {
+	m := newTestOrderedMap()
+
+	var keys []string
+	for key, value := range m.All() {
+		keys = append(keys, key)
+		if value == 2 {
+			break
+		}
+	}
+	if !slices.Equal(keys, []string{"zulu", "alpha"}) {
+		t.Errorf("Expected [zulu alpha] but got %v", keys)
+	}
+}
+
+func TestOrderedMapMarshalJSON(t *testing.T) {
+	m := newTestOrderedMap()
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Unable to marshal map: %v", err)
+	}
+	if string(data) != `{"zulu":1,"alpha":2,"mike":3}` {
+		t.Errorf("Expected keys in insertion order but got %s", data)
+	}
+
+	empty, _ := json.Marshal(NewOrderedMap[string, int]())
+	if string(empty) != "{}" {
+		t.Errorf("Expected {} but got %s", empty)
+	}
+
+	nested := struct {
+		Columns *OrderedMap[int, string] `json:"columns"`
+	}{NewOrderedMap[int, string]()}
+	nested.Columns.Set(2, "b")
+	nested.Columns.Set(1, "a")
+	data, _ = json.Marshal(nested)
+	if string(data) != `{"columns":{"2":"b","1":"a"}}` {
+		t.Errorf("Expected integer keys in insertion order but got %s", data)
+	}
+
+	addresses := NewOrderedMap[netip.Addr, bool]()
+	addresses.Set(netip.MustParseAddr("10.0.0.2"), true)
+	data, _ = json.Marshal(addresses)
+	if string(data) != `{"10.0.0.2":true}` {
+		t.Errorf("Expected text marshaller key but got %s", data)
+	}
+
+	if _, err := json.Marshal(func() *OrderedMap[float64, int] {
+		m := NewOrderedMap[float64, int]()
+		m.Set(1.5, 1)
+		return m
+	}()); err == nil {
+		t.Error("Expected error with unsupported key type")
+	}
+}
+
+func TestMarshalJSONByValue(t *testing.T) {
+	var value struct {
+		M OrderedMap[string, int]
+		T Set[string]
+	}
+	value.M.Set("zulu", 1)
+	value.M.Set("alpha", 2)
+	value.T.Add("x")
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Unable to marshal struct: %v", err)
+	}
+	if string(data) != `{"M":{"zulu":1,"alpha":2},"T":["x"]}` {
+		t.Errorf("Expected map and set held by value to be marshalled but got %s", data)
+	}
+
+	data, _ = json.Marshal(&value)
+	if string(data) != `{"M":{"zulu":1,"alpha":2},"T":["x"]}` {
+		t.Errorf("Expected map and set held by pointer to be marshalled but got %s", data)
+	}
+}
+
+func TestOrderedMapUnmarshalJSON(t *testing.T) {
+	var m OrderedMap[string, []int]
+	if err := json.Unmarshal([]byte(`{"zulu":[1],"alpha":[2,3],"mike":null}`), &m); err != nil {
+		t.Fatalf("Unable to unmarshal map: %v", err)
+	}
+	if !slices.Equal(m.Keys(), []string{"zulu", "alpha", "mike"}) {
+		t.Errorf("Expected keys in order of JSON but got %v", m.Keys())
+	}
+	if v, _ := m.Get("alpha"); !slices.Equal(v, []int{2, 3}) {
+		t.Errorf("Expected [2 3] but got %v", v)
+	}
+
+	var ints OrderedMap[uint8, string]
+	if err := json.Unmarshal([]byte(`{"3":"c","1":"a"}`), &ints); err != nil {
+		t.Fatalf("Unable to unmarshal map: %v", err)
+	}
+	if !slices.Equal(ints.Keys(), []uint8{3, 1}) {
+		t.Errorf("Expected [3 1] but got %v", ints.Keys())
+	}
+
+	var addresses OrderedMap[netip.Addr, int]
+	if err := json.Unmarshal([]byte(`{"10.0.0.2":1}`), &addresses); err != nil {
+		t.Fatalf("Unable to unmarshal map: %v", err)
+	}
+	if !addresses.Has(netip.MustParseAddr("10.0.0.2")) {
+		t.Errorf("Expected text unmarshaller key but got %v", addresses.Keys())
+	}
+}
+
+func TestOrderedMapUnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"array", `[1,2]`},
+		{"invalid value", `{"a":"x"}`},
+		{"invalid key", `{"999":1}`},
+		{"truncated", `{"a":1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m OrderedMap[uint8, int]
+			if err := json.Unmarshal([]byte(tt.data), &m); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestOrderedMapRoundTrip(t *testing.T) {
+	original := []byte(`{"name":"a","id":1,"created":"2024-01-01","tags":["x"]}`)
+
+	var m OrderedMap[string, json.RawMessage]
+	if err := json.Unmarshal(original, &m); err != nil {
+		t.Fatalf("Unable to unmarshal map: %v", err)
+	}
+	data, err := json.Marshal(&m)
+	if err != nil {
+		t.Fatalf("Unable to marshal map: %v", err)
+	}
+	if string(data) != string(original) {
+		t.Errorf("Expected %s but got %s", original, data)
+	}
+}
+
+func BenchmarkOrderedMapSet(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		m := NewOrderedMap[int, int]()
+		for j := 0; j < 1000; j++ {
+			m.Set(j, j)
+		}
+	}
+}
+
+func BenchmarkOrderedMapMarshalJSON(b *testing.B) {
+	m := NewOrderedMap[string, int]()
+	for j := 0; j < 100; j++ {
+		m.Set("key"+string(rune('a'+j%26))+string(rune('a'+j/26)), j)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = json.Marshal(m)
+	}
+}
@@ -0,0 +1,134 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ParallelOptions configures the concurrency and the handling of errors of
+// ParallelMap and ForEach
+type ParallelOptions struct {
+	workers  int
+	failFast bool
+}
+
+// ParallelOption is a functional option for ParallelMap and ForEach
+type ParallelOption func(*ParallelOptions)
+
+// WithWorkers sets the maximum number of items processed concurrently; it
+// defaults to GOMAXPROCS
+func WithWorkers(workers int) ParallelOption {
+	return func(o *ParallelOptions) {
+		o.workers = workers
+	}
+}
+
+// WithFailFast stops processing items after the first error; the context
+// passed to items in progress is cancelled and items not yet started are
+// skipped
+func WithFailFast() ParallelOption {
+	return func(o *ParallelOptions) {
+		o.failFast = true
+	}
+}
+
+func defaultParallelOptions() *ParallelOptions {
+	return &ParallelOptions{
+		workers: runtime.GOMAXPROCS(0),
+	}
+}
+
+// ParallelMap applies f to items concurrently with a limited number of
+// workers and returns the results in the order of items. Errors of all items
+// are joined with errors.Join, each annotated with the index of its item,
+// and the results of failed or skipped items are zero values. Items not yet
+// started are skipped once ctx is done, in which case the error of ctx is
+// also returned. A panic in f is returned as an error of its item.
+func ParallelMap[T any, U any](ctx context.Context, items []T, f func(ctx context.Context, item T) (U, error), opts ...ParallelOption) ([]U, error) {
+	options := defaultParallelOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	results := make([]U, len(items))
+	errs := make([]error, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var failed sync.Once
+	isFailedFast := false
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(1, min(options.workers, len(items))); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = callSafely(workerCtx, items[i], f)
+				if errs[i] != nil && options.failFast {
+					failed.Do(func() {
+						isFailedFast = true
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := range items {
+		// checks cancellation first as select chooses randomly among ready
+		// cases
+		if workerCtx.Err() != nil {
+			break
+		}
+		select {
+		case indexes <- i:
+		case <-workerCtx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	var joined []error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		// errors caused by cancellation of fail-fast are not reported
+		if isFailedFast && ctx.Err() == nil && errors.Is(err, context.Canceled) {
+			continue
+		}
+		joined = append(joined, fmt.Errorf("item %d: %w", i, err))
+	}
+	if err := ctx.Err(); err != nil {
+		joined = append(joined, err)
+	}
+	return results, errors.Join(joined...)
+}
+
+// ForEach calls f with items concurrently with a limited number of workers;
+// errors are handled as in ParallelMap
+func ForEach[T any](ctx context.Context, items []T, f func(ctx context.Context, item T) error, opts ...ParallelOption) error {
+	_, err := ParallelMap(ctx, items, func(ctx context.Context, item T) (struct{}, error) {
+		return struct{}{}, f(ctx, item)
+	}, opts...)
+	return err
+}
+
+func callSafely[T any, U any](ctx context.Context, item T, f func(context.Context, T) (U, error)) (result U, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f(ctx, item)
+}
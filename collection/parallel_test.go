@@ -0,0 +1,180 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelMap(t *testing.T) {
+	items := []int{5, 1, 4, 2, 3}
+
+	results, err := ParallelMap(context.Background(), items, func(_ context.Context, v int) (string, error) {
+		time.Sleep(time.Duration(v) * time.Millisecond)
+		return fmt.Sprint(v * 10), nil
+	}, WithWorkers(3))
+	if err != nil {
+		t.Fatalf("ParallelMap() error: %v", err)
+	}
+	if !slices.Equal(results, []string{"50", "10", "40", "20", "30"}) {
+		t.Errorf("Expected results in order of items but got %v", results)
+	}
+}
+
+func TestParallelMapEmpty(t *testing.T) {
+	results, err := ParallelMap(context.Background(), nil, func(context.Context, int) (int, error) {
+		t.Error("f should not be called")
+		return 0, nil
+	})
+	if err != nil || len(results) != 0 {
+		t.Errorf("Expected no result and no error but got %v, %v", results, err)
+	}
+}
+
+func TestParallelMapWorkerLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		want    int32
+	}{
+		{"limited", 2, 2},
+		{"one worker when not positive", 0, 1},
+		{"no more than items", 100, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak atomic.Int32
+			err := ForEach(context.Background(), make([]int, 8), func(context.Context, int) error {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			}, WithWorkers(tt.workers))
+			if err != nil {
+				t.Fatalf("ForEach() error: %v", err)
+			}
+			if peak.Load() > tt.want {
+				t.Errorf("Expected at most %d concurrent items but got %d", tt.want, peak.Load())
+			}
+			if tt.workers <= 1 && peak.Load() != 1 {
+				t.Errorf("Expected items to be processed one by one but got %d", peak.Load())
+			}
+		})
+	}
+}
+
+func TestParallelMapCollectsErrors(t *testing.T) {
+	errOdd := errors.New("odd")
+
+	results, err := ParallelMap(context.Background(), []int{1, 2, 3, 4}, func(_ context.Context, v int) (int, error) {
+		if v%2 == 1 {
+			return 0, errOdd
+		}
+		return v, nil
+	}, WithWorkers(2))
+
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("Expected joined error to contain %v but got %v", errOdd, err)
+	}
+	if err.Error() != "item 0: odd\nitem 2: odd" {
+		t.Errorf("Expected errors of all items in order but got %q", err.Error())
+	}
+	if !slices.Equal(results, []int{0, 2, 0, 4}) {
+		t.Errorf("Expected results of successful items but got %v", results)
+	}
+}
+
+func TestParallelMapFailFast(t *testing.T) {
+	var started atomic.Int32
+	errFirst := errors.New("first")
+
+	_, err := ParallelMap(context.Background(), make([]int, 100), func(ctx context.Context, _ int) (int, error) {
+		if started.Add(1) == 1 {
+			return 0, errFirst
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(time.Second):
+			return 0, nil
+		}
+	}, WithWorkers(4), WithFailFast())
+
+	if !errors.Is(err, errFirst) {
+		t.Fatalf("Expected %v but got %v", errFirst, err)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation caused by fail-fast not to be reported but got %v", err)
+	}
+	if n := started.Load(); n > 8 {
+		t.Errorf("Expected remaining items to be skipped but %d items started", n)
+	}
+}
+
+func TestParallelMapContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var started atomic.Int32
+
+	err := ForEach(ctx, make([]int, 100), func(ctx context.Context, _ int) error {
+		if started.Add(1) == 2 {
+			cancel()
+		}
+		<-ctx.Done()
+		return nil
+	}, WithWorkers(2))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v but got %v", context.Canceled, err)
+	}
+	if n := started.Load(); n > 4 {
+		t.Errorf("Expected items not to be started after cancellation but %d items started", n)
+	}
+}
+
+func TestParallelMapCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := ForEach(ctx, []int{1, 2}, func(context.Context, int) error {
+		t.Error("f should not be called")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v but got %v", context.Canceled, err)
+	}
+}
+
+func TestParallelMapRecoversPanic(t *testing.T) {
+	_, err := ParallelMap(context.Background(), []int{1, 2}, func(_ context.Context, v int) (int, error) {
+		if v == 2 {
+			panic("boom")
+		}
+		return v, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "item 1: panic: boom") {
+		t.Errorf("Expected panic to be returned as error but got %v", err)
+	}
+}
+
+func BenchmarkParallelMap(b *testing.B) {
+	items := newBenchmarkInts(10000)
+	square := func(_ context.Context, v int) (int, error) {
+		return v * v, nil
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = ParallelMap(context.Background(), items, square, WithWorkers(8))
+	}
+}
@@ -0,0 +1,263 @@
+package collection
+
+import (
+	"context"
+	"iter"
+)
+
+// The functions in this file compose iter.Seq and iter.Seq2 lazily; no
+// element is read from the source until the result is iterated and no
+// intermediate slice is created. The results can be collected with
+// slices.Collect, maps.Collect, CollectMap or ToChannel.
+
+// MapSeq returns a sequence of the results of applying f to each element of
+// seq
+func MapSeq[T any, U any](seq iter.Seq[T], f func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+// MapSeq2 returns a sequence of the results of applying f to each pair of
+// seq
+func MapSeq2[K any, V any, K2 any, V2 any](seq iter.Seq2[K, V], f func(K, V) (K2, V2)) iter.Seq2[K2, V2] {
+	return func(yield func(K2, V2) bool) {
+		for k, v := range seq {
+			if !yield(f(k, v)) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq returns a sequence of the elements of seq satisfying predicate
+func FilterSeq[T any](seq iter.Seq[T], predicate func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if predicate(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq2 returns a sequence of the pairs of seq satisfying predicate
+func FilterSeq2[K any, V any](seq iter.Seq2[K, V], predicate func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if predicate(k, v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Take returns a sequence of the first n elements of seq; seq is not read
+// beyond the nth element
+func Take[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		count := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			count++
+			if count >= n {
+				return
+			}
+		}
+	}
+}
+
+// TakeSeq2 returns a sequence of the first n pairs of seq
+func TakeSeq2[K any, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if n <= 0 {
+			return
+		}
+		count := 0
+		for k, v := range seq {
+			if !yield(k, v) {
+				return
+			}
+			count++
+			if count >= n {
+				return
+			}
+		}
+	}
+}
+
+// Skip returns a sequence of the elements of seq after the first n
+func Skip[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		count := 0
+		for v := range seq {
+			if count < n {
+				count++
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// SkipSeq2 returns a sequence of the pairs of seq after the first n
+func SkipSeq2[K any, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		count := 0
+		for k, v := range seq {
+			if count < n {
+				count++
+				continue
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Batch returns a sequence of consecutive batches of the specified size of
+// the elements of seq; the last batch may be smaller. Each batch is a new
+// slice which can be retained. It panics if size is less than 1.
+func Batch[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size < 1 {
+		panic("collection: batch size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		batch := make([]T, 0, size)
+		for v := range seq {
+			batch = append(batch, v)
+			if len(batch) == size {
+				if !yield(batch) {
+					return
+				}
+				batch = make([]T, 0, size)
+			}
+		}
+		if len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// Flatten returns a sequence of the elements of the slices of seq, such as
+// pages of results of an API
+func Flatten[T any](seq iter.Seq[[]T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for s := range seq {
+			for _, v := range s {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Dedupe returns a sequence of the elements of seq without duplicates in the
+// order of their first occurrences; the elements seen are kept in memory
+func Dedupe[T comparable](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(map[T]struct{})
+		for v := range seq {
+			if _, ok := seen[v]; ok {
+				continue
+			}
+			seen[v] = struct{}{}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Window returns a sequence of sliding windows of the specified size over
+// the elements of seq, such as [1 2 3], [2 3 4] for size 3; nothing is
+// returned if seq has fewer elements than size. Each window is a new slice
+// which can be retained. It panics if size is less than 1.
+func Window[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size < 1 {
+		panic("collection: window size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		window := make([]T, 0, size)
+		for v := range seq {
+			if len(window) == size {
+				window = window[1:]
+			}
+			window = append(window, v)
+			if len(window) == size {
+				w := make([]T, size)
+				copy(w, window)
+				if !yield(w) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Pairs returns a sequence of the pairs of seq
+func Pairs[K any, V any](seq iter.Seq2[K, V]) iter.Seq[Pair[K, V]] {
+	return func(yield func(Pair[K, V]) bool) {
+		for k, v := range seq {
+			if !yield(Pair[K, V]{First: k, Second: v}) {
+				return
+			}
+		}
+	}
+}
+
+// CollectMap returns a map of the elements of seq by their keys; the last
+// element wins if more than one element has the same key
+func CollectMap[T any, K comparable](seq iter.Seq[T], key func(T) K) map[K]T {
+	m := make(map[K]T)
+	for v := range seq {
+		m[key(v)] = v
+	}
+	return m
+}
+
+// ToChannel returns a channel with the specified buffer size which receives
+// the elements of seq in a new goroutine. The channel is closed after the
+// last element or when ctx is done, in which case seq is not read further.
+func ToChannel[T any](ctx context.Context, seq iter.Seq[T], bufferSize int) <-chan T {
+	ch := make(chan T, bufferSize)
+	go func() {
+		defer close(ch)
+		for v := range seq {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// FromChannel returns a sequence of the values received from ch until it is
+// closed
+func FromChannel[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
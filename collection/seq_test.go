@@ -0,0 +1,306 @@
+package collection
+
+import (
+	"context"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// countingSeq returns a sequence of 1 to n and a pointer to the number of
+// elements read from it
+func countingSeq(n int) (iter.Seq[int], *int) {
+	read := 0
+	return func(yield func(int) bool) {
+		for i := 1; i <= n; i++ {
+			read++
+			if !yield(i) {
+				return
+			}
+		}
+	}, &read
+}
+
+func TestMapSeq(t *testing.T) {
+	seq, _ := countingSeq(3)
+	actual := slices.Collect(MapSeq(seq, strconv.Itoa))
+
+	if !slices.Equal(actual, []string{"1", "2", "3"}) {
+		t.Errorf("Expected [1 2 3] but got %v", actual)
+	}
+}
+
+func TestFilterSeq(t *testing.T) {
+	seq, _ := countingSeq(6)
+	actual := slices.Collect(FilterSeq(seq, isEven))
+
+	if !slices.Equal(actual, []int{2, 4, 6}) {
+		t.Errorf("Expected [2 4 6] but got %v", actual)
+	}
+}
+
+func TestTake(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		expected []int
+		wantRead int
+	}{
+		{"zero", 0, nil, 0},
+		{"some", 2, []int{1, 2}, 2},
+		{"more than available", 10, []int{1, 2, 3, 4, 5}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq, read := countingSeq(5)
+			actual := slices.Collect(Take(seq, tt.n))
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, actual)
+			}
+			if *read != tt.wantRead {
+				t.Errorf("Expected %d elements to be read but got %d", tt.wantRead, *read)
+			}
+		})
+	}
+}
+
+func TestSkip(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		expected []int
+	}{
+		{"zero", 0, []int{1, 2, 3}},
+		{"some", 2, []int{3}},
+		{"all", 5, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq, _ := countingSeq(3)
+			actual := slices.Collect(Skip(seq, tt.n))
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+	seq, _ := countingSeq(5)
+	actual := slices.Collect(Batch(seq, 2))
+
+	expected := [][]int{{1, 2}, {3, 4}, {5}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	empty, _ := countingSeq(0)
+	if actual := slices.Collect(Batch(empty, 2)); len(actual) != 0 {
+		t.Errorf("Expected no batch but got %v", actual)
+	}
+}
+
+func TestBatchPanicsWithInvalidSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic with non-positive size")
+		}
+	}()
+	seq, _ := countingSeq(1)
+	Batch(seq, 0)
+}
+
+func TestFlatten(t *testing.T) {
+	pages := slices.Values([][]string{{"a", "b"}, {}, {"c"}})
+	actual := slices.Collect(Flatten(pages))
+
+	if !slices.Equal(actual, []string{"a", "b", "c"}) {
+		t.Errorf("Expected [a b c] but got %v", actual)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	actual := slices.Collect(Dedupe(slices.Values([]int{3, 1, 3, 2, 1})))
+
+	if !slices.Equal(actual, []int{3, 1, 2}) {
+		t.Errorf("Expected [3 1 2] but got %v", actual)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		size     int
+		expected [][]int
+	}{
+		{"sliding", 5, 3, [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}},
+		{"size of one", 2, 1, [][]int{{1}, {2}}},
+		{"fewer elements than size", 2, 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq, _ := countingSeq(tt.n)
+			actual := slices.Collect(Window(seq, tt.size))
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSeq2Combinators(t *testing.T) {
+	source := slices.All([]string{"a", "b", "c", "d"})
+
+	filtered := FilterSeq2(source, func(i int, _ string) bool { return i != 1 })
+	mapped := MapSeq2(filtered, func(i int, s string) (string, int) { return s, i * 10 })
+	actual := maps.Collect(TakeSeq2(SkipSeq2(mapped, 1), 5))
+
+	expected := map[string]int{"c": 20, "d": 30}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	if actual := maps.Collect(TakeSeq2(source, 0)); len(actual) != 0 {
+		t.Errorf("Expected no pair but got %v", actual)
+	}
+}
+
+func TestPairs(t *testing.T) {
+	actual := slices.Collect(Pairs(slices.All([]string{"a", "b"})))
+
+	expected := []Pair[int, string]{{0, "a"}, {1, "b"}}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestPipelineIsLazy(t *testing.T) {
+	seq, read := countingSeq(1000)
+
+	pipeline := Take(FilterSeq(MapSeq(seq, func(v int) int { return v * 3 }), isEven), 2)
+	if *read != 0 {
+		t.Errorf("Expected nothing to be read before iteration but got %d", *read)
+	}
+
+	actual := slices.Collect(pipeline)
+	if !slices.Equal(actual, []int{6, 12}) {
+		t.Errorf("Expected [6 12] but got %v", actual)
+	}
+	if *read != 4 {
+		t.Errorf("Expected 4 elements to be read but got %d", *read)
+	}
+}
+
+func TestEarlyTerminationOfCombinators(t *testing.T) {
+	tests := []struct {
+		name string
+		seq  func(iter.Seq[int]) iter.Seq[int]
+	}{
+		{"map", func(s iter.Seq[int]) iter.Seq[int] { return MapSeq(s, func(v int) int { return v }) }},
+		{"filter", func(s iter.Seq[int]) iter.Seq[int] { return FilterSeq(s, func(int) bool { return true }) }},
+		{"skip", func(s iter.Seq[int]) iter.Seq[int] { return Skip(s, 0) }},
+		{"dedupe", Dedupe[int]},
+		{"flatten of batch", func(s iter.Seq[int]) iter.Seq[int] { return Flatten(Batch(s, 1)) }},
+		{"flatten of window", func(s iter.Seq[int]) iter.Seq[int] { return Flatten(Window(s, 1)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq, read := countingSeq(100)
+			for range tt.seq(seq) {
+				break
+			}
+			if *read != 1 {
+				t.Errorf("Expected 1 element to be read but got %d", *read)
+			}
+		})
+	}
+}
+
+func TestCollectMap(t *testing.T) {
+	actual := CollectMap(slices.Values(testPeople), func(p testPerson) string { return p.Name })
+
+	if len(actual) != len(testPeople) || actual["carol"].Age != 35 {
+		t.Errorf("Unexpected map %v", actual)
+	}
+}
+
+func TestToChannel(t *testing.T) {
+	seq, _ := countingSeq(5)
+
+	var actual []int
+	for v := range ToChannel(context.Background(), seq, 2) {
+		actual = append(actual, v)
+	}
+	if !slices.Equal(actual, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("Expected [1 2 3 4 5] but got %v", actual)
+	}
+}
+
+func TestToChannelCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	seq, _ := countingSeq(1000)
+
+	ch := ToChannel(ctx, seq, 0)
+	if v := <-ch; v != 1 {
+		t.Errorf("Expected 1 but got %d", v)
+	}
+	cancel()
+
+	// the channel is closed after cancellation; at most one more element may
+	// be delivered if sending raced with cancellation
+	count := 0
+	for range ch {
+		count++
+	}
+	if count > 1 {
+		t.Errorf("Expected the channel to be closed after cancellation but got %d elements", count)
+	}
+}
+
+func TestFromChannel(t *testing.T) {
+	ch := make(chan string, 3)
+	ch <- "a"
+	ch <- "b"
+	close(ch)
+
+	actual := slices.Collect(FromChannel(ch))
+	if !slices.Equal(actual, []string{"a", "b"}) {
+		t.Errorf("Expected [a b] but got %v", actual)
+	}
+}
+
+func BenchmarkSeqPipeline(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		seq := Take(FilterSeq(MapSeq(slices.Values(input), func(v int) int { return v * 3 }), isEven), 1000)
+		for range Batch(seq, 100) {
+		}
+	}
+}
+
+func BenchmarkDedupe(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range Dedupe(slices.Values(input)) {
+		}
+	}
+}
+
+func BenchmarkWindow(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range Window(slices.Values(input), 5) {
+		}
+	}
+}
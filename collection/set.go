@@ -0,0 +1,152 @@
+package collection
+
+import (
+	"encoding/json"
+	"iter"
+	"maps"
+)
+
+// Set is an unordered collection of distinct values; the zero value is an
+// empty set ready to use
+type Set[T comparable] struct {
+	m map[T]struct{}
+}
+
+// NewSet returns a set of the specified values
+func NewSet[T comparable](values ...T) *Set[T] {
+	s := &Set[T]{m: make(map[T]struct{}, len(values))}
+	s.Add(values...)
+	return s
+}
+
+// CollectSet returns a set of the values of seq
+func CollectSet[T comparable](seq iter.Seq[T]) *Set[T] {
+	s := NewSet[T]()
+	for v := range seq {
+		s.Add(v)
+	}
+	return s
+}
+
+// Add adds the specified values to the set
+func (s *Set[T]) Add(values ...T) {
+	if s.m == nil {
+		s.m = make(map[T]struct{}, len(values))
+	}
+	for _, v := range values {
+		s.m[v] = struct{}{}
+	}
+}
+
+// Remove removes the specified values from the set
+func (s *Set[T]) Remove(values ...T) {
+	for _, v := range values {
+		delete(s.m, v)
+	}
+}
+
+// Contains returns true if v is in the set
+func (s *Set[T]) Contains(v T) bool {
+	_, ok := s.m[v]
+	return ok
+}
+
+// Len returns the number of values in the set
+func (s *Set[T]) Len() int {
+	return len(s.m)
+}
+
+// Clear removes all values from the set
+func (s *Set[T]) Clear() {
+	clear(s.m)
+}
+
+// Clone returns a copy of the set
+func (s *Set[T]) Clone() *Set[T] {
+	c := NewSet[T]()
+	maps.Copy(c.m, s.m)
+	return c
+}
+
+// All returns an iterator over the values of the set in no particular order
+func (s *Set[T]) All() iter.Seq[T] {
+	return maps.Keys(s.m)
+}
+
+// Values returns the values of the set in no particular order
+func (s *Set[T]) Values() []T {
+	values := make([]T, 0, len(s.m))
+	for v := range s.m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Union returns a new set of the values in either s or other
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	result := s.Clone()
+	for v := range other.m {
+		result.m[v] = struct{}{}
+	}
+	return result
+}
+
+// Intersection returns a new set of the values in both s and other
+func (s *Set[T]) Intersection(other *Set[T]) *Set[T] {
+	smaller, larger := s, other
+	if smaller.Len() > larger.Len() {
+		smaller, larger = larger, smaller
+	}
+	result := NewSet[T]()
+	for v := range smaller.m {
+		if larger.Contains(v) {
+			result.m[v] = struct{}{}
+		}
+	}
+	return result
+}
+
+// Difference returns a new set of the values in s but not in other
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	result := NewSet[T]()
+	for v := range s.m {
+		if !other.Contains(v) {
+			result.m[v] = struct{}{}
+		}
+	}
+	return result
+}
+
+// IsSubsetOf returns true if every value of s is in other
+func (s *Set[T]) IsSubsetOf(other *Set[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	for v := range s.m {
+		if !other.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal returns true if s and other contain the same values
+func (s *Set[T]) Equal(other *Set[T]) bool {
+	return s.Len() == other.Len() && s.IsSubsetOf(other)
+}
+
+// MarshalJSON marshals the set as an array in no particular order; it has a
+// value receiver so that sets held by value in structs are marshalled
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Values())
+}
+
+// UnmarshalJSON adds the values of a JSON array to the set
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	s.Add(values...)
+	return nil
+}
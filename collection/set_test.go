@@ -0,0 +1,158 @@
+package collection
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func sortedValues(s *Set[int]) []int {
+	values := s.Values()
+	slices.Sort(values)
+	return values
+}
+
+func TestSet(t *testing.T) {
+	s := NewSet(3, 1, 2, 3)
+
+	if s.Len() != 3 {
+		t.Errorf("Expected 3 values but got %d", s.Len())
+	}
+	if !s.Contains(1) || s.Contains(4) {
+		t.Errorf("Expected set to contain 1 but not 4: %v", s.Values())
+	}
+
+	s.Add(4, 5)
+	s.Remove(1, 9)
+	if got := sortedValues(s); !slices.Equal(got, []int{2, 3, 4, 5}) {
+		t.Errorf("Expected [2 3 4 5] but got %v", got)
+	}
+
+	s.Clear()
+	if s.Len() != 0 {
+		t.Errorf("Expected empty set but got %v", s.Values())
+	}
+}
+
+func TestSetZeroValue(t *testing.T) {
+	var s Set[string]
+	if s.Contains("a") || s.Len() != 0 {
+		t.Error("Expected zero value to be empty")
+	}
+	s.Remove("a")
+	s.Add("a")
+	if !s.Contains("a") {
+		t.Error("Expected zero value to be usable")
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5)
+
+	tests := []struct {
+		name     string
+		actual   *Set[int]
+		expected []int
+	}{
+		{"union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"intersection", a.Intersection(b), []int{3, 4}},
+		{"intersection of smaller set", b.Intersection(a), []int{3, 4}},
+		{"difference", a.Difference(b), []int{1, 2}},
+		{"difference of other", b.Difference(a), []int{5}},
+		{"intersection with empty set", a.Intersection(NewSet[int]()), []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortedValues(tt.actual); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if got := sortedValues(a); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected operations not to modify set but got %v", got)
+	}
+}
+
+func TestSetComparison(t *testing.T) {
+	a := NewSet(1, 2)
+
+	if !a.IsSubsetOf(NewSet(1, 2, 3)) {
+		t.Error("Expected subset")
+	}
+	if a.IsSubsetOf(NewSet(1, 3)) {
+		t.Error("Expected not subset")
+	}
+	if !a.Equal(NewSet(2, 1)) || a.Equal(NewSet(1, 2, 3)) || a.Equal(NewSet(1, 3)) {
+		t.Error("Unexpected result of Equal")
+	}
+}
+
+func TestSetClone(t *testing.T) {
+	a := NewSet(1, 2)
+	c := a.Clone()
+	c.Add(3)
+
+	if a.Contains(3) {
+		t.Error("Expected clone to be independent")
+	}
+}
+
+func TestSetIteration(t *testing.T) {
+	s := NewSet("a", "b", "c")
+
+	var values []string
+	for v := range s.All() {
+		values = append(values, v)
+	}
+	slices.Sort(values)
+	if !slices.Equal(values, []string{"a", "b", "c"}) {
+		t.Errorf("Expected [a b c] but got %v", values)
+	}
+
+	collected := CollectSet(slices.Values([]string{"x", "y", "x"}))
+	if collected.Len() != 2 {
+		t.Errorf("Expected 2 values but got %v", collected.Values())
+	}
+}
+
+func TestSetJSON(t *testing.T) {
+	data, err := json.Marshal(NewSet(2))
+	if err != nil {
+		t.Fatalf("Unable to marshal set: %v", err)
+	}
+	if string(data) != "[2]" {
+		t.Errorf("Expected [2] but got %s", data)
+	}
+
+	var s Set[int]
+	if err := json.Unmarshal([]byte("[1,2,2]"), &s); err != nil {
+		t.Fatalf("Unable to unmarshal set: %v", err)
+	}
+	if got := sortedValues(&s); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("Expected [1 2] but got %v", got)
+	}
+	if err := json.Unmarshal([]byte(`{"a":1}`), &s); err == nil {
+		t.Error("Expected error when unmarshalling an object")
+	}
+}
+
+func BenchmarkSetAdd(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		s := NewSet[int]()
+		for j := 0; j < 1000; j++ {
+			s.Add(j)
+		}
+	}
+}
+
+func BenchmarkSetIntersection(b *testing.B) {
+	x := NewSet(newBenchmarkInts(10000)...)
+	y := NewSet(Map(newBenchmarkInts(10000), func(v int) int { return v * 2 })...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Intersection(y)
+	}
+}
@@ -0,0 +1,289 @@
+package collection
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	defaultFuzzyMinScore   = 0.7
+	jaroWinklerPrefixScale = 0.1
+	jaroWinklerMaxPrefix   = 4
+)
+
+// The functions in this file operate on runes rather than bytes so that
+// non-ASCII characters, such as those of Chinese or accented names, count as
+// single characters.
+
+// LevenshteinDistance returns the minimum number of insertions, deletions
+// and substitutions of characters required to change a into b
+func LevenshteinDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// DamerauLevenshteinDistance returns the Levenshtein distance of a and b with
+// transpositions of two adjacent characters counted as single edits, which
+// catches typing mistakes such as "teh" for "the". This is the optimal string
+// alignment variant in which no substring is edited more than once.
+func DamerauLevenshteinDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// only three rows are required as a transposition looks back two rows
+	rows := [3][]int{make([]int, len(rb)+1), make([]int, len(rb)+1), make([]int, len(rb)+1)}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current, previous, beforePrevious := rows[i%3], rows[(i-1)%3], rows[(i+1)%3]
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				current[j] = min(current[j], beforePrevious[j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)%3][len(rb)]
+}
+
+// JaroSimilarity returns the Jaro similarity of a and b, from 0 (no
+// similarity) to 1 (identical)
+func JaroSimilarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	matchDistance := max(0, max(len(ra), len(rb))/2-1)
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i, r := range ra {
+		start := max(0, i-matchDistance)
+		end := min(len(rb), i+matchDistance+1)
+		for j := start; j < end; j++ {
+			if !matchedB[j] && rb[j] == r {
+				matchedA[i] = true
+				matchedB[j] = true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+}
+
+// JaroWinklerSimilarity returns the Jaro similarity of a and b boosted by
+// the length of their common prefix (up to 4 characters), from 0 (no
+// similarity) to 1 (identical); it favours strings which match from the
+// beginning, such as names
+func JaroWinklerSimilarity(a string, b string) float64 {
+	jaro := JaroSimilarity(a, b)
+
+	prefix := 0
+	ra, rb := []rune(a), []rune(b)
+	for prefix < min(len(ra), len(rb), jaroWinklerMaxPrefix) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*jaroWinklerPrefixScale*(1-jaro)
+}
+
+// NGramSimilarity returns the Sørensen–Dice coefficient of the n-grams
+// (substrings of n characters) of a and b, from 0 (no common n-gram) to 1
+// (same n-grams); strings shorter than n are similar only if they are equal.
+// It is insensitive to the order of words, such as "team meeting" and
+// "meeting team". It panics if n is less than 1.
+func NGramSimilarity(a string, b string, n int) float64 {
+	if n < 1 {
+		panic("collection: n of n-gram must be positive")
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < n || len(rb) < n {
+		if a == b {
+			return 1
+		}
+		return 0
+	}
+
+	gramsA := make(map[string]int, len(ra)-n+1)
+	for i := 0; i+n <= len(ra); i++ {
+		gramsA[string(ra[i:i+n])]++
+	}
+	common := 0
+	for i := 0; i+n <= len(rb); i++ {
+		gram := string(rb[i : i+n])
+		if gramsA[gram] > 0 {
+			gramsA[gram]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(ra)-n+1+len(rb)-n+1)
+}
+
+// FuzzyMatch is a candidate matched by FuzzyFind
+type FuzzyMatch struct {
+	Value string
+	Index int
+	Score float64
+}
+
+// FuzzyFindOptions configures the minimum score and the number of
+// candidates returned by FuzzyFind
+type FuzzyFindOptions struct {
+	minScore float64
+	limit    int
+}
+
+// FuzzyFindOption is a functional option for FuzzyFind
+type FuzzyFindOption func(*FuzzyFindOptions)
+
+// WithMinScore sets the minimum score, from 0 to 1, of candidates to be
+// returned; it defaults to 0.7
+func WithMinScore(score float64) FuzzyFindOption {
+	return func(o *FuzzyFindOptions) {
+		o.minScore = score
+	}
+}
+
+// WithLimit sets the maximum number of candidates to be returned
+func WithLimit(limit int) FuzzyFindOption {
+	return func(o *FuzzyFindOptions) {
+		o.limit = limit
+	}
+}
+
+func defaultFuzzyFindOptions() *FuzzyFindOptions {
+	return &FuzzyFindOptions{
+		minScore: defaultFuzzyMinScore,
+	}
+}
+
+// FuzzyFind returns the candidates similar to query ranked by their scores,
+// from 0 to 1, with ties in the order of candidates. Strings are compared
+// case-insensitively and without diacritics (so "cafe" matches "Café"). An
+// exact match scores 1 and is followed by candidates starting with query,
+// then candidates containing query and then other candidates by the higher
+// of their Jaro-Winkler and bigram similarities, so that typing mistakes
+// still match.
+func FuzzyFind(query string, candidates []string, opts ...FuzzyFindOption) []FuzzyMatch {
+	options := defaultFuzzyFindOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	normalizedQuery := normalizeForMatching(query)
+	var matches []FuzzyMatch
+	for i, candidate := range candidates {
+		score := fuzzyScore(normalizedQuery, normalizeForMatching(candidate))
+		if score >= options.minScore {
+			matches = append(matches, FuzzyMatch{Value: candidate, Index: i, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if options.limit > 0 && len(matches) > options.limit {
+		matches = matches[:options.limit]
+	}
+	return matches
+}
+
+// fuzzyScore scores normalized candidate against normalized query in bands
+// so that a prefix match always outranks a substring match, which in turn
+// outranks a similar string
+func fuzzyScore(query string, candidate string) float64 {
+	if query == candidate {
+		return 1
+	}
+	if query == "" || candidate == "" {
+		return 0
+	}
+
+	// shorter candidates rank higher within a band as more of them is matched
+	coverage := float64(len([]rune(query))) / float64(len([]rune(candidate)))
+	switch {
+	case strings.HasPrefix(candidate, query):
+		return 0.9 + 0.09*coverage
+	case strings.Contains(candidate, query):
+		return 0.8 + 0.09*coverage
+	}
+	similarity := max(JaroWinklerSimilarity(query, candidate), NGramSimilarity(query, candidate, 2))
+	return 0.8 * similarity
+}
+
+// normalizeForMatching returns s in lower case without diacritics
+func normalizeForMatching(s string) string {
+	if isASCII(s) {
+		return strings.ToLower(strings.TrimSpace(s))
+	}
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, err := transform.String(t, s)
+	if err != nil {
+		normalized = s
+	}
+	return strings.ToLower(strings.TrimSpace(normalized))
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
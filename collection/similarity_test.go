@@ -0,0 +1,282 @@
+package collection
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func almostEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < 0.001
+}
+
+func TestLevenshteinDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"ca", "abc", 3},
+		{"teh", "the", 2},
+		{"日本語", "日本", 1},
+		{"café", "cafe", 1},
+		{"same", "same", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if actual := LevenshteinDistance(tt.a, tt.b); actual != tt.expected {
+				t.Errorf("Expected %d but got %d", tt.expected, actual)
+			}
+			if actual := LevenshteinDistance(tt.b, tt.a); actual != tt.expected {
+				t.Errorf("Expected symmetric distance %d but got %d", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDamerauLevenshteinDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"teh", "the", 1},
+		{"abcdef", "abdcef", 1},
+		{"ca", "abc", 3},
+		{"日本語", "本日語", 1},
+		{"same", "same", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if actual := DamerauLevenshteinDistance(tt.a, tt.b); actual != tt.expected {
+				t.Errorf("Expected %d but got %d", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestJaroSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"", "", 1},
+		{"abc", "", 0},
+		{"MARTHA", "MARHTA", 0.944},
+		{"DIXON", "DICKSONX", 0.767},
+		{"JELLYFISH", "SMELLYFISH", 0.896},
+		{"abc", "xyz", 0},
+		{"same", "same", 1},
+		{"a", "a", 1},
+		{"a", "b", 0},
+		{"a", "ab", 0.833},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if actual := JaroSimilarity(tt.a, tt.b); !almostEqual(actual, tt.expected) {
+				t.Errorf("Expected %.3f but got %.3f", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestJaroWinklerSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"MARTHA", "MARHTA", 0.961},
+		{"DIXON", "DICKSONX", 0.813},
+		{"DWAYNE", "DUANE", 0.84},
+		{"abc", "xyz", 0},
+		{"會議室", "會議", 0.911},
+		{"a", "a", 1},
+		{"a", "b", 0},
+		{"a", "ab", 0.85},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if actual := JaroWinklerSimilarity(tt.a, tt.b); !almostEqual(actual, tt.expected) {
+				t.Errorf("Expected %.3f but got %.3f", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNGramSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		n        int
+		expected float64
+	}{
+		{"night", "nacht", 2, 0.25},
+		{"team meeting", "meeting team", 2, 0.818},
+		{"abc", "abc", 3, 1},
+		{"a", "a", 2, 1},
+		{"a", "b", 2, 0},
+		{"aaaa", "aa", 2, 0.5},
+		{"日本語", "日本", 2, 0.667},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if actual := NGramSimilarity(tt.a, tt.b, tt.n); !almostEqual(actual, tt.expected) {
+				t.Errorf("Expected %.3f but got %.3f", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNGramSimilarityPanicsWithInvalidN(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic with non-positive n")
+		}
+	}()
+	NGramSimilarity("a", "b", 0)
+}
+
+func TestFuzzyFind(t *testing.T) {
+	candidates := []string{
+		"Personal",
+		"Work calendar",
+		"Calendar",
+		"Team calendar",
+		"Holidays in Hong Kong",
+		"Café meetups",
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		opts     []FuzzyFindOption
+		expected []string
+	}{
+		{"exact match first", "calendar", nil, []string{"Calendar", "Work calendar", "Team calendar"}},
+		{"prefix before substring", "cal", nil, []string{"Calendar", "Work calendar", "Team calendar"}},
+		{"typing mistake", "calender", nil, []string{"Calendar"}},
+		{"transposition", "persnoal", nil, []string{"Personal"}},
+		{"diacritics", "cafe", nil, []string{"Café meetups"}},
+		{"limit", "calendar", []FuzzyFindOption{WithLimit(1)}, []string{"Calendar"}},
+		{"no match", "zzz", nil, nil},
+		{"all candidates with zero minimum score", "", []FuzzyFindOption{WithMinScore(0)}, candidates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := FuzzyFind(tt.query, candidates, tt.opts...)
+			actual := Map(matches, func(m FuzzyMatch) string { return m.Value })
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFuzzyFindScores(t *testing.T) {
+	matches := FuzzyFind("Work", []string{"work", "workspace", "my work", "wrok"}, WithMinScore(0))
+
+	if len(matches) != 4 {
+		t.Fatalf("Expected 4 matches but got %v", matches)
+	}
+	if matches[0].Score != 1 || matches[0].Index != 0 {
+		t.Errorf("Expected exact match to score 1 but got %v", matches[0])
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score >= matches[i-1].Score {
+			t.Errorf("Expected descending scores but got %v", matches)
+		}
+	}
+	if matches[1].Value != "workspace" || matches[2].Value != "my work" || matches[3].Value != "wrok" {
+		t.Errorf("Expected prefix, substring and similar matches in order but got %v", matches)
+	}
+}
+
+func TestFuzzyFindKeepsOrderOfTies(t *testing.T) {
+	matches := FuzzyFind("abc", []string{"abcx", "abcy", "abcz"})
+
+	indexes := Map(matches, func(m FuzzyMatch) int { return m.Index })
+	if !slices.Equal(indexes, []int{0, 1, 2}) {
+		t.Errorf("Expected ties in order of candidates but got %v", indexes)
+	}
+}
+
+func TestNormalizeForMatching(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{" Calendar ", "calendar"},
+		{"Crème Brûlée", "creme brulee"},
+		{"ÅNGSTRÖM", "angstrom"},
+		{"會議室", "會議室"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if actual := normalizeForMatching(tt.input); actual != tt.expected {
+				t.Errorf("Expected %q but got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+var benchmarkCandidates = func() []string {
+	words := []string{"calendar", "meeting", "personal", "work", "holiday", "team", "project", "review"}
+	var candidates []string
+	for _, a := range words {
+		for _, b := range words {
+			candidates = append(candidates, a+" "+b)
+		}
+	}
+	return candidates
+}()
+
+func BenchmarkLevenshteinDistance(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		LevenshteinDistance("team calendar review", "calendar of the team")
+	}
+}
+
+func BenchmarkDamerauLevenshteinDistance(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		DamerauLevenshteinDistance("team calendar review", "calendar of the team")
+	}
+}
+
+func BenchmarkJaroWinklerSimilarity(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		JaroWinklerSimilarity("team calendar review", "calendar of the team")
+	}
+}
+
+func BenchmarkNGramSimilarity(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NGramSimilarity("team calendar review", "calendar of the team", 2)
+	}
+}
+
+func BenchmarkFuzzyFind(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		FuzzyFind("calender reveiw", benchmarkCandidates)
+	}
+}
+
+func BenchmarkFuzzyFindUnicode(b *testing.B) {
+	candidates := Map(benchmarkCandidates, func(s string) string { return s + " é" })
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FuzzyFind("calender reveiw", candidates)
+	}
+}
@@ -0,0 +1,168 @@
+package collection
+
+// Pair holds a pair of values, such as the elements at the same index of two
+// slices
+type Pair[T any, U any] struct {
+	First  T
+	Second U
+}
+
+// Distinct returns the elements of s without duplicates in the order of their
+// first occurrences
+func Distinct[T comparable](s []T) []T {
+	seen := make(map[T]struct{}, len(s))
+	result := make([]T, 0, len(s))
+	for _, v := range s {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
+
+// DistinctBy returns the elements of s without those having the same key as
+// a preceding element
+func DistinctBy[T any, K comparable](s []T, key func(T) K) []T {
+	seen := make(map[K]struct{}, len(s))
+	result := make([]T, 0, len(s))
+	for _, v := range s {
+		k := key(v)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
+
+// Map returns the results of applying f to each element of s
+func Map[T any, U any](s []T, f func(T) U) []U {
+	result := make([]U, len(s))
+	for i, v := range s {
+		result[i] = f(v)
+	}
+	return result
+}
+
+// Filter returns the elements of s satisfying predicate
+func Filter[T any](s []T, predicate func(T) bool) []T {
+	result := make([]T, 0, len(s))
+	for _, v := range s {
+		if predicate(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Reduce accumulates the elements of s from left to right with f, starting
+// with initial
+func Reduce[T any, A any](s []T, initial A, f func(A, T) A) A {
+	accumulator := initial
+	for _, v := range s {
+		accumulator = f(accumulator, v)
+	}
+	return accumulator
+}
+
+// GroupBy groups the elements of s by their keys; the order of elements is
+// preserved within each group
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for _, v := range s {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+// KeyBy returns a map of the elements of s by their keys; the last element
+// wins if more than one element has the same key
+func KeyBy[T any, K comparable](s []T, key func(T) K) map[K]T {
+	m := make(map[K]T, len(s))
+	for _, v := range s {
+		m[key(v)] = v
+	}
+	return m
+}
+
+// Partition splits s into the elements satisfying predicate and those which
+// do not
+func Partition[T any](s []T, predicate func(T) bool) ([]T, []T) {
+	var matched, unmatched []T
+	for _, v := range s {
+		if predicate(v) {
+			matched = append(matched, v)
+		} else {
+			unmatched = append(unmatched, v)
+		}
+	}
+	return matched, unmatched
+}
+
+// Chunk splits s into consecutive sub-slices of the specified size; the last
+// chunk may be shorter. The chunks share the underlying array of s but
+// appending to a chunk never overwrites the next one. It panics if size is
+// less than 1.
+func Chunk[T any](s []T, size int) [][]T {
+	if size < 1 {
+		panic("collection: chunk size must be positive")
+	}
+	chunks := make([][]T, 0, (len(s)+size-1)/size)
+	for i := 0; i < len(s); i += size {
+		end := min(i+size, len(s))
+		chunks = append(chunks, s[i:end:end])
+	}
+	return chunks
+}
+
+// Zip pairs up the elements of a and b at the same index; the result is as
+// long as the shorter slice
+func Zip[T any, U any](a []T, b []U) []Pair[T, U] {
+	n := min(len(a), len(b))
+	result := make([]Pair[T, U], n)
+	for i := 0; i < n; i++ {
+		result[i] = Pair[T, U]{First: a[i], Second: b[i]}
+	}
+	return result
+}
+
+// Intersect returns the distinct elements of a which are also in b, in the
+// order of a
+func Intersect[T comparable](a []T, b []T) []T {
+	inB := toLookup(b)
+	return Filter(Distinct(a), func(v T) bool {
+		_, ok := inB[v]
+		return ok
+	})
+}
+
+// Union returns the distinct elements of a followed by those of b which are
+// not in a
+func Union[T comparable](a []T, b []T) []T {
+	combined := make([]T, 0, len(a)+len(b))
+	combined = append(combined, a...)
+	combined = append(combined, b...)
+	return Distinct(combined)
+}
+
+// Difference returns the distinct elements of a which are not in b, in the
+// order of a
+func Difference[T comparable](a []T, b []T) []T {
+	inB := toLookup(b)
+	return Filter(Distinct(a), func(v T) bool {
+		_, ok := inB[v]
+		return !ok
+	})
+}
+
+func toLookup[T comparable](s []T) map[T]struct{} {
+	m := make(map[T]struct{}, len(s))
+	for _, v := range s {
+		m[v] = struct{}{}
+	}
+	return m
+}
@@ -0,0 +1,336 @@
+package collection
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testPerson struct {
+	Name string
+	Team string
+	Age  int
+}
+
+var testPeople = []testPerson{
+	{"alice", "red", 30},
+	{"bob", "blue", 25},
+	{"carol", "red", 35},
+	{"dave", "green", 25},
+}
+
+func isEven(v int) bool {
+	return v%2 == 0
+}
+
+func TestDistinct(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		expected []int
+	}{
+		{"nil", nil, []int{}},
+		{"non-duplicated", []int{3, 1, 2}, []int{3, 1, 2}},
+		{"duplicated", []int{3, 1, 3, 2, 1, 3}, []int{3, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := Distinct(tt.input)
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDistinctBy(t *testing.T) {
+	actual := DistinctBy(testPeople, func(p testPerson) int { return p.Age })
+
+	names := Map(actual, func(p testPerson) string { return p.Name })
+	if !slices.Equal(names, []string{"alice", "bob", "carol"}) {
+		t.Errorf("Expected first person of each age but got %v", names)
+	}
+}
+
+func TestMap(t *testing.T) {
+	actual := Map([]int{1, 2, 3}, strconv.Itoa)
+	if !slices.Equal(actual, []string{"1", "2", "3"}) {
+		t.Errorf("Expected [1 2 3] but got %v", actual)
+	}
+	if actual := Map(nil, strconv.Itoa); len(actual) != 0 {
+		t.Errorf("Expected empty slice but got %v", actual)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		expected []int
+	}{
+		{"empty", []int{}, []int{}},
+		{"none matched", []int{1, 3}, []int{}},
+		{"some matched", []int{1, 2, 3, 4}, []int{2, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := Filter(tt.input, isEven)
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce([]int{1, 2, 3, 4}, 0, func(acc int, v int) int { return acc + v })
+	if sum != 10 {
+		t.Errorf("Expected 10 but got %d", sum)
+	}
+
+	joined := Reduce([]int{1, 2, 3}, "", func(acc string, v int) string { return acc + strconv.Itoa(v) })
+	if joined != "123" {
+		t.Errorf("Expected 123 but got %s", joined)
+	}
+
+	if initial := Reduce(nil, 42, func(acc int, v int) int { return acc + v }); initial != 42 {
+		t.Errorf("Expected initial value but got %d", initial)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	actual := GroupBy(testPeople, func(p testPerson) string { return p.Team })
+
+	expected := map[string][]testPerson{
+		"red":   {testPeople[0], testPeople[2]},
+		"blue":  {testPeople[1]},
+		"green": {testPeople[3]},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestKeyBy(t *testing.T) {
+	actual := KeyBy(testPeople, func(p testPerson) int { return p.Age })
+
+	if len(actual) != 3 {
+		t.Errorf("Expected 3 keys but got %v", actual)
+	}
+	if actual[25].Name != "dave" {
+		t.Errorf("Expected the last element to win but got %v", actual[25])
+	}
+}
+
+func TestPartition(t *testing.T) {
+	matched, unmatched := Partition([]int{1, 2, 3, 4, 5}, isEven)
+
+	if !slices.Equal(matched, []int{2, 4}) {
+		t.Errorf("Expected [2 4] but got %v", matched)
+	}
+	if !slices.Equal(unmatched, []int{1, 3, 5}) {
+		t.Errorf("Expected [1 3 5] but got %v", unmatched)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		size     int
+		expected [][]int
+	}{
+		{"empty", nil, 2, [][]int{}},
+		{"exact", []int{1, 2, 3, 4}, 2, [][]int{{1, 2}, {3, 4}}},
+		{"remainder", []int{1, 2, 3, 4, 5}, 2, [][]int{{1, 2}, {3, 4}, {5}}},
+		{"larger than slice", []int{1, 2}, 5, [][]int{{1, 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := Chunk(tt.input, tt.size)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestChunkAppendDoesNotOverwrite(t *testing.T) {
+	input := []int{1, 2, 3, 4}
+	chunks := Chunk(input, 2)
+
+	_ = append(chunks[0], 99)
+	if !slices.Equal(input, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected input to be unchanged but got %v", input)
+	}
+}
+
+func TestChunkInvalidSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic with non-positive size")
+		}
+	}()
+	Chunk([]int{1}, 0)
+}
+
+func TestZip(t *testing.T) {
+	actual := Zip([]string{"a", "b", "c"}, []int{1, 2})
+
+	expected := []Pair[string, int]{{"a", 1}, {"b", 2}}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestSetOperationsOfSlices(t *testing.T) {
+	a := []string{"c", "a", "b", "a"}
+	b := []string{"d", "b", "c", "d"}
+
+	tests := []struct {
+		name     string
+		f        func([]string, []string) []string
+		expected []string
+	}{
+		{"intersect", Intersect[string], []string{"c", "b"}},
+		{"union", Union[string], []string{"c", "a", "b", "d"}},
+		{"difference", Difference[string], []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := tt.f(a, b)
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, actual)
+			}
+		})
+	}
+
+	if actual := Intersect(a, nil); len(actual) != 0 {
+		t.Errorf("Expected empty intersection but got %v", actual)
+	}
+	if actual := Difference(a, nil); !slices.Equal(actual, []string{"c", "a", "b"}) {
+		t.Errorf("Expected distinct elements of a but got %v", actual)
+	}
+}
+
+func newBenchmarkInts(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i % (n / 4)
+	}
+	return s
+}
+
+func BenchmarkDistinct(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Distinct(input)
+	}
+}
+
+func BenchmarkDistinctBy(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DistinctBy(input, func(v int) int { return v % 100 })
+	}
+}
+
+func BenchmarkMap(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Map(input, strconv.Itoa)
+	}
+}
+
+func BenchmarkFilter(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Filter(input, isEven)
+	}
+}
+
+func BenchmarkReduce(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Reduce(input, 0, func(acc int, v int) int { return acc + v })
+	}
+}
+
+func BenchmarkGroupBy(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GroupBy(input, func(v int) int { return v % 10 })
+	}
+}
+
+func BenchmarkKeyBy(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		KeyBy(input, func(v int) int { return v })
+	}
+}
+
+func BenchmarkPartition(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Partition(input, isEven)
+	}
+}
+
+func BenchmarkChunk(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Chunk(input, 100)
+	}
+}
+
+func BenchmarkZip(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	names := Map(input, strconv.Itoa)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Zip(input, names)
+	}
+}
+
+func BenchmarkIntersect(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	other := Map(input, func(v int) int { return v * 2 })
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Intersect(input, other)
+	}
+}
+
+func BenchmarkUnion(b *testing.B) {
+	input := newBenchmarkInts(10000)
+	other := Map(input, func(v int) int { return v * 2 })
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Union(input, other)
+	}
+}
+
+func BenchmarkDifference(b *testing.B) {
+	input := strings.Split(strings.Repeat("a,b,c,d,", 1000), ",")
+	other := []string{"b", "d"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Difference(input, other)
+	}
+}
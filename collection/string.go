@@ -5,19 +5,10 @@ import (
 	"strings"
 )
 
-// GetDistinct returns a distinct (non-duplicated) array from the specified input
+// GetDistinct returns a distinct (non-duplicated) array from the specified
+// input in the order of first occurrences (see Distinct)
 func GetDistinct(array []string) []string {
-	m := map[string]bool{}
-
-	for v := range array {
-		m[array[v]] = true
-	}
-
-	d := []string{}
-	for k := range m {
-		d = append(d, k)
-	}
-	return d
+	return Distinct(array)
 }
 
 // GetDelimitedString returns a delimited string using a specified array
@@ -1,6 +1,9 @@
 package collection
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestGetDistinct(t *testing.T) {
 	var tests = []struct {
@@ -15,7 +18,7 @@ func TestGetDistinct(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			actual := GetDistinct(tt.input)
-			if len(actual) != len(tt.expected) {
+			if !slices.Equal(actual, tt.expected) {
 				t.Errorf("Expected %v but got %v", tt.expected, actual)
 			}
 		})
@@ -65,6 +68,13 @@ func TestGetDelimitedString(t *testing.T) {
 	}
 }
 
+func BenchmarkGetDistinct(b *testing.B) {
+	input := []string{"1", "2", "4", "3", "5", "3", "1", "4"}
+	for i := 0; i < b.N; i++ {
+		GetDistinct(input)
+	}
+}
+
 func BenchmarkGetDelimitedString(t *testing.B) {
 	for i := 0; i < t.N; i++ {
 		GetDelimitedString([]string{"abc", "def"}, ",")
@@ -0,0 +1,362 @@
+package cryptohelper
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	pemTypeCertificate = "CERTIFICATE"
+
+	defaultCertificateAuthorityValidity = 5 * 365 * 24 * time.Hour
+	defaultLeafCertificateValidity      = 365 * 24 * time.Hour
+
+	// certificates are valid slightly before they are created to allow
+	// differences of clocks
+	certificateClockSkew = time.Minute
+)
+
+// Certificate is an X.509 certificate with its private key
+type Certificate struct {
+	Certificate *x509.Certificate
+	PrivateKey  crypto.Signer
+}
+
+// CertificateAuthority is a certificate which issues other certificates,
+// such as a local certificate authority for development and tests
+type CertificateAuthority struct {
+	Certificate
+}
+
+// CertificateOptions configures the key, the validity, the organization and
+// the subject alternative names of certificates created by
+// NewCertificateAuthority, IssueServerCertificate and IssueClientCertificate
+type CertificateOptions struct {
+	keyType      KeyType
+	notBefore    time.Time
+	validity     time.Duration
+	organization string
+	names        []string
+}
+
+// CertificateOption is a functional option for NewCertificateAuthority,
+// IssueServerCertificate and IssueClientCertificate
+type CertificateOption func(*CertificateOptions)
+
+// WithCertificateKeyType sets the type of the key of the certificate; it
+// defaults to ECDSA P-256
+func WithCertificateKeyType(keyType KeyType) CertificateOption {
+	return func(o *CertificateOptions) {
+		o.keyType = keyType
+	}
+}
+
+// WithValidity sets the validity window of the certificate; it defaults to
+// 5 years for certificate authorities and 1 year for other certificates
+// from the time of creation. Certificates issued by a certificate authority
+// do not start before the authority does.
+func WithValidity(notBefore time.Time, validity time.Duration) CertificateOption {
+	return func(o *CertificateOptions) {
+		o.notBefore = notBefore
+		o.validity = validity
+	}
+}
+
+// WithOrganization sets the organization (O) in the subject of the
+// certificate
+func WithOrganization(organization string) CertificateOption {
+	return func(o *CertificateOptions) {
+		o.organization = organization
+	}
+}
+
+// WithSubjectAlternativeNames adds subject alternative names to the
+// certificate; each name is added as an IP address, an email address, a URI
+// or a DNS name depending on its format
+func WithSubjectAlternativeNames(names ...string) CertificateOption {
+	return func(o *CertificateOptions) {
+		o.names = append(o.names, names...)
+	}
+}
+
+func defaultCertificateOptions(validity time.Duration) *CertificateOptions {
+	return &CertificateOptions{
+		keyType:  KeyTypeECDSAP256,
+		validity: validity,
+	}
+}
+
+// NewCertificateAuthority creates a self-signed certificate authority which
+// can issue certificates but not other certificate authorities
+func NewCertificateAuthority(commonName string, opts ...CertificateOption) (*CertificateAuthority, error) {
+	options := defaultCertificateOptions(defaultCertificateAuthorityValidity)
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	template, key, err := newCertificateTemplate(commonName, options)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.MaxPathLenZero = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	cert, err := createCertificate(template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	return &CertificateAuthority{Certificate{Certificate: cert, PrivateKey: key}}, nil
+}
+
+// IssueServerCertificate issues a certificate for TLS servers of hosts,
+// such as "localhost", "127.0.0.1" and "::1"; the first host is used as the
+// common name
+func (ca *CertificateAuthority) IssueServerCertificate(hosts []string, opts ...CertificateOption) (*Certificate, error) {
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("at least one host is required")
+	}
+	opts = append([]CertificateOption{WithSubjectAlternativeNames(hosts...)}, opts...)
+	return ca.issue(hosts[0], x509.ExtKeyUsageServerAuth, opts)
+}
+
+// IssueClientCertificate issues a certificate for TLS clients (mutual TLS)
+// of the common name, such as the name of a user or a service
+func (ca *CertificateAuthority) IssueClientCertificate(commonName string, opts ...CertificateOption) (*Certificate, error) {
+	return ca.issue(commonName, x509.ExtKeyUsageClientAuth, opts)
+}
+
+func (ca *CertificateAuthority) issue(commonName string, usage x509.ExtKeyUsage, opts []CertificateOption) (*Certificate, error) {
+	options := defaultCertificateOptions(defaultLeafCertificateValidity)
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	template, key, err := newCertificateTemplate(commonName, options)
+	if err != nil {
+		return nil, err
+	}
+	if template.NotAfter.After(ca.Certificate.Certificate.NotAfter) {
+		return nil, fmt.Errorf("certificate expiring at %s cannot be issued by certificate authority expiring at %s",
+			template.NotAfter.Format(time.RFC3339),
+			ca.Certificate.Certificate.NotAfter.Format(time.RFC3339),
+		)
+	}
+	// a certificate cannot be valid before its issuer
+	if template.NotBefore.Before(ca.Certificate.Certificate.NotBefore) {
+		template.NotBefore = ca.Certificate.Certificate.NotBefore
+	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	cert, err := createCertificate(template, ca.Certificate.Certificate, key.Public(), ca.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{Certificate: cert, PrivateKey: key}, nil
+}
+
+func newCertificateTemplate(commonName string, options *CertificateOptions) (*x509.Certificate, crypto.Signer, error) {
+	if options.validity <= 0 {
+		return nil, nil, fmt.Errorf("validity must be positive")
+	}
+	key, err := GenerateKey(options.keyType)
+	if err != nil {
+		return nil, nil, err
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to generate serial number: %w", err)
+	}
+
+	notBefore := options.notBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now().Add(-certificateClockSkew)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(options.validity),
+		BasicConstraintsValid: true,
+	}
+	if options.organization != "" {
+		template.Subject.Organization = []string{options.organization}
+	}
+	if err := addSubjectAlternativeNames(template, options.names); err != nil {
+		return nil, nil, err
+	}
+	return template, key, nil
+}
+
+func addSubjectAlternativeNames(template *x509.Certificate, names []string) error {
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+			continue
+		}
+		if strings.Contains(name, "://") {
+			uri, err := url.Parse(name)
+			if err != nil {
+				return fmt.Errorf("unable to parse URI %s: %w", name, err)
+			}
+			template.URIs = append(template.URIs, uri)
+			continue
+		}
+		if strings.Contains(name, "@") {
+			address, err := mail.ParseAddress(name)
+			if err != nil {
+				return fmt.Errorf("unable to parse email address %s: %w", name, err)
+			}
+			template.EmailAddresses = append(template.EmailAddresses, address.Address)
+			continue
+		}
+		template.DNSNames = append(template.DNSNames, name)
+	}
+	return nil
+}
+
+func createCertificate(template *x509.Certificate, parent *x509.Certificate, publicKey crypto.PublicKey, signer crypto.Signer) (*x509.Certificate, error) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, signer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// CertificatePEM returns the certificate in PEM
+func (c *Certificate) CertificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeCertificate, Bytes: c.Certificate.Raw})
+}
+
+// PrivateKeyPEM returns the private key in PKCS#8 PEM
+func (c *Certificate) PrivateKeyPEM() ([]byte, error) {
+	return MarshalPrivateKeyPEM(c.PrivateKey, nil)
+}
+
+// WriteFiles writes the certificate and its private key in PEM to the
+// specified paths, such as for servers configured with files
+func (c *Certificate) WriteFiles(certificatePath string, privateKeyPath string) error {
+	keyPEM, err := c.PrivateKeyPEM()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Clean(certificatePath), c.CertificatePEM(), 0600); err != nil {
+		return fmt.Errorf("unable to write certificate to %s: %w", certificatePath, err)
+	}
+	if err := os.WriteFile(filepath.Clean(privateKeyPath), keyPEM, 0600); err != nil {
+		return fmt.Errorf("unable to write private key to %s: %w", privateKeyPath, err)
+	}
+	return nil
+}
+
+// TLSCertificate returns the certificate for tls.Config
+func (c *Certificate) TLSCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{c.Certificate.Raw},
+		PrivateKey:  c.PrivateKey,
+		Leaf:        c.Certificate,
+	}
+}
+
+// LoadCertificate reads a certificate and its private key in PEM from the
+// specified paths; see ParsePrivateKey for the supported formats of private
+// keys
+func LoadCertificate(certificatePath string, privateKeyPath string, passphrase []byte) (*Certificate, error) {
+	certificatePEM, err := os.ReadFile(filepath.Clean(certificatePath))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read certificate from %s: %w", certificatePath, err)
+	}
+	block, _ := pem.Decode(certificatePEM)
+	if block == nil || block.Type != pemTypeCertificate {
+		return nil, fmt.Errorf("unable to decode certificate from %s", certificatePath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate from %s: %w", certificatePath, err)
+	}
+
+	keyPEM, err := os.ReadFile(filepath.Clean(privateKeyPath))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read private key from %s: %w", privateKeyPath, err)
+	}
+	key, err := ParsePrivateKey(keyPEM, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !key.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(cert.PublicKey) {
+		return nil, fmt.Errorf("private key in %s does not match certificate in %s", privateKeyPath, certificatePath)
+	}
+	return &Certificate{Certificate: cert, PrivateKey: key}, nil
+}
+
+// LoadCertificateAuthority reads a certificate authority and its private
+// key in PEM from the specified paths
+func LoadCertificateAuthority(certificatePath string, privateKeyPath string, passphrase []byte) (*CertificateAuthority, error) {
+	cert, err := LoadCertificate(certificatePath, privateKeyPath, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !cert.Certificate.IsCA {
+		return nil, fmt.Errorf("certificate in %s is not a certificate authority", certificatePath)
+	}
+	return &CertificateAuthority{*cert}, nil
+}
+
+// CertPool returns a pool with the certificate authority, which can be used
+// as tls.Config.RootCAs or tls.Config.ClientCAs
+func (ca *CertificateAuthority) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Certificate.Certificate)
+	return pool
+}
+
+// NewServerTLSConfig returns a configuration of a TLS server with cert. If
+// clientCA is not nil, clients are required to present certificates issued
+// by clientCA (mutual TLS).
+func NewServerTLSConfig(cert *Certificate, clientCA *CertificateAuthority) *tls.Config {
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert.TLSCertificate()},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCA != nil {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = clientCA.CertPool()
+	}
+	return config
+}
+
+// NewClientTLSConfig returns a configuration of a TLS client trusting
+// servers with certificates issued by serverCA. If cert is not nil, it is
+// presented to servers (mutual TLS).
+func NewClientTLSConfig(serverCA *CertificateAuthority, cert *Certificate) *tls.Config {
+	config := &tls.Config{
+		RootCAs:    serverCA.CertPool(),
+		MinVersion: tls.VersionTLS12,
+	}
+	if cert != nil {
+		config.Certificates = []tls.Certificate{cert.TLSCertificate()}
+	}
+	return config
+}
@@ -0,0 +1,318 @@
+package cryptohelper
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestCertificateAuthority(t testing.TB, commonName string) *CertificateAuthority {
+	t.Helper()
+	ca, err := NewCertificateAuthority(commonName)
+	if err != nil {
+		t.Fatalf("NewCertificateAuthority() error: %v", err)
+	}
+	return ca
+}
+
+func TestNewCertificateAuthority(t *testing.T) {
+	ca, err := NewCertificateAuthority("Test CA", WithOrganization("Test"))
+	if err != nil {
+		t.Fatalf("NewCertificateAuthority() error: %v", err)
+	}
+
+	cert := ca.Certificate.Certificate
+	if !cert.IsCA || !cert.MaxPathLenZero || cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		t.Errorf("NewCertificateAuthority() = %+v", cert)
+	}
+	if cert.Subject.CommonName != "Test CA" || len(cert.Subject.Organization) != 1 || cert.Subject.Organization[0] != "Test" {
+		t.Errorf("NewCertificateAuthority() subject = %s", cert.Subject)
+	}
+	if err := cert.CheckSignatureFrom(cert); err != nil {
+		t.Errorf("NewCertificateAuthority() is not self-signed: %v", err)
+	}
+	if validity := cert.NotAfter.Sub(cert.NotBefore); validity != defaultCertificateAuthorityValidity {
+		t.Errorf("NewCertificateAuthority() validity = %s", validity)
+	}
+}
+
+func TestIssueServerCertificate(t *testing.T) {
+	ca := newTestCertificateAuthority(t, "Test CA")
+
+	cert, err := ca.IssueServerCertificate(
+		[]string{"localhost", "127.0.0.1", "::1"},
+		WithSubjectAlternativeNames("spiffe://test/server", "admin@example.com", "*.example.com"),
+	)
+	if err != nil {
+		t.Fatalf("IssueServerCertificate() error: %v", err)
+	}
+
+	leaf := cert.Certificate
+	if leaf.Subject.CommonName != "localhost" || leaf.IsCA {
+		t.Errorf("IssueServerCertificate() = %+v", leaf)
+	}
+	if len(leaf.DNSNames) != 2 || leaf.DNSNames[0] != "localhost" || leaf.DNSNames[1] != "*.example.com" {
+		t.Errorf("IssueServerCertificate() DNS names = %v", leaf.DNSNames)
+	}
+	if len(leaf.IPAddresses) != 2 || len(leaf.URIs) != 1 || len(leaf.EmailAddresses) != 1 {
+		t.Errorf("IssueServerCertificate() IP addresses = %v, URIs = %v, email addresses = %v", leaf.IPAddresses, leaf.URIs, leaf.EmailAddresses)
+	}
+
+	for _, host := range []string{"localhost", "127.0.0.1", "::1", "www.example.com"} {
+		_, err := leaf.Verify(x509.VerifyOptions{
+			DNSName:   host,
+			Roots:     ca.CertPool(),
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		if err != nil {
+			t.Errorf("Verify() of %s error: %v", host, err)
+		}
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:     ca.CertPool(),
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err == nil {
+		t.Error("Verify() of server certificate for client authentication should return error")
+	}
+}
+
+func TestIssueCertificateKeyTypes(t *testing.T) {
+	ca := newTestCertificateAuthority(t, "Test CA")
+
+	tests := []struct {
+		keyType KeyType
+		check   func(any) bool
+	}{
+		{KeyTypeRSA2048, func(key any) bool { _, ok := key.(*rsa.PrivateKey); return ok }},
+		{KeyTypeEd25519, func(key any) bool { _, ok := key.(ed25519.PrivateKey); return ok }},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.keyType), func(t *testing.T) {
+			cert, err := ca.IssueClientCertificate("alex", WithCertificateKeyType(tt.keyType))
+			if err != nil {
+				t.Fatalf("IssueClientCertificate() error: %v", err)
+			}
+			if !tt.check(cert.PrivateKey) {
+				t.Errorf("IssueClientCertificate() key = %T", cert.PrivateKey)
+			}
+			if err := cert.Certificate.CheckSignatureFrom(ca.Certificate.Certificate); err != nil {
+				t.Errorf("CheckSignatureFrom() error: %v", err)
+			}
+		})
+	}
+}
+
+func TestIssueCertificateErrors(t *testing.T) {
+	ca := newTestCertificateAuthority(t, "Test CA")
+
+	tests := []struct {
+		name  string
+		issue func() (*Certificate, error)
+	}{
+		{"no host", func() (*Certificate, error) { return ca.IssueServerCertificate(nil) }},
+		{"unsupported key type", func() (*Certificate, error) {
+			return ca.IssueClientCertificate("alex", WithCertificateKeyType("DSA"))
+		}},
+		{"negative validity", func() (*Certificate, error) {
+			return ca.IssueClientCertificate("alex", WithValidity(time.Now(), -time.Hour))
+		}},
+		{"validity beyond certificate authority", func() (*Certificate, error) {
+			return ca.IssueClientCertificate("alex", WithValidity(time.Now(), 10*defaultCertificateAuthorityValidity))
+		}},
+		{"invalid email address", func() (*Certificate, error) {
+			return ca.IssueServerCertificate([]string{"localhost"}, WithSubjectAlternativeNames("a@b@c"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.issue(); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestCertificateWithValidity(t *testing.T) {
+	notBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ca, err := NewCertificateAuthority("Test CA", WithValidity(notBefore, 48*time.Hour))
+	if err != nil {
+		t.Fatalf("NewCertificateAuthority() error: %v", err)
+	}
+	cert, err := ca.IssueServerCertificate([]string{"localhost"}, WithValidity(notBefore.Add(time.Hour), time.Hour))
+	if err != nil {
+		t.Fatalf("IssueServerCertificate() error: %v", err)
+	}
+
+	if !cert.Certificate.NotBefore.Equal(notBefore.Add(time.Hour)) || !cert.Certificate.NotAfter.Equal(notBefore.Add(2*time.Hour)) {
+		t.Errorf("IssueServerCertificate() validity = %s - %s", cert.Certificate.NotBefore, cert.Certificate.NotAfter)
+	}
+	_, err = cert.Certificate.Verify(x509.VerifyOptions{Roots: ca.CertPool(), DNSName: "localhost"})
+	if err == nil {
+		t.Error("Verify() of expired certificate should return error")
+	}
+	_, err = cert.Certificate.Verify(x509.VerifyOptions{Roots: ca.CertPool(), DNSName: "localhost", CurrentTime: notBefore.Add(90 * time.Minute)})
+	if err != nil {
+		t.Errorf("Verify() within validity error: %v", err)
+	}
+}
+
+func TestCertificateNotBeforeIssuer(t *testing.T) {
+	notBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ca, err := NewCertificateAuthority("Test CA", WithValidity(notBefore, 48*time.Hour))
+	if err != nil {
+		t.Fatalf("NewCertificateAuthority() error: %v", err)
+	}
+	cert, err := ca.IssueClientCertificate("client", WithValidity(notBefore.Add(-time.Hour), 2*time.Hour))
+	if err != nil {
+		t.Fatalf("IssueClientCertificate() error: %v", err)
+	}
+
+	if !cert.Certificate.NotBefore.Equal(notBefore) || !cert.Certificate.NotAfter.Equal(notBefore.Add(time.Hour)) {
+		t.Errorf("IssueClientCertificate() validity = %s - %s, want %s - %s", cert.Certificate.NotBefore, cert.Certificate.NotAfter, notBefore, notBefore.Add(time.Hour))
+	}
+	_, err = cert.Certificate.Verify(x509.VerifyOptions{
+		Roots:       ca.CertPool(),
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		CurrentTime: notBefore.Add(30 * time.Minute),
+	})
+	if err != nil {
+		t.Errorf("Verify() within validity error: %v", err)
+	}
+}
+
+func TestCertificateWriteFilesAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCertificateAuthority(t, "Test CA")
+	cert, err := ca.IssueServerCertificate([]string{"localhost"})
+	if err != nil {
+		t.Fatalf("IssueServerCertificate() error: %v", err)
+	}
+
+	caCertPath := filepath.Join(dir, "ca.pem")
+	caKeyPath := filepath.Join(dir, "ca-key.pem")
+	certPath := filepath.Join(dir, "server.pem")
+	keyPath := filepath.Join(dir, "server-key.pem")
+	if err := ca.WriteFiles(caCertPath, caKeyPath); err != nil {
+		t.Fatalf("WriteFiles() error: %v", err)
+	}
+	if err := cert.WriteFiles(certPath, keyPath); err != nil {
+		t.Fatalf("WriteFiles() error: %v", err)
+	}
+
+	// files are compatible with the standard library
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		t.Errorf("tls.LoadX509KeyPair() error: %v", err)
+	}
+
+	loadedCA, err := LoadCertificateAuthority(caCertPath, caKeyPath, nil)
+	if err != nil {
+		t.Fatalf("LoadCertificateAuthority() error: %v", err)
+	}
+	if !loadedCA.Certificate.Certificate.Equal(ca.Certificate.Certificate) {
+		t.Error("LoadCertificateAuthority() returned different certificate")
+	}
+	if _, err := loadedCA.IssueClientCertificate("alex"); err != nil {
+		t.Errorf("IssueClientCertificate() of loaded certificate authority error: %v", err)
+	}
+
+	loaded, err := LoadCertificate(certPath, keyPath, nil)
+	if err != nil {
+		t.Fatalf("LoadCertificate() error: %v", err)
+	}
+	if !loaded.Certificate.Equal(cert.Certificate) || !loaded.PrivateKey.(equalKey).Equal(cert.PrivateKey) {
+		t.Error("LoadCertificate() returned different certificate")
+	}
+
+	if _, err := LoadCertificateAuthority(certPath, keyPath, nil); err == nil {
+		t.Error("LoadCertificateAuthority() of server certificate should return error")
+	}
+	if _, err := LoadCertificate(certPath, caKeyPath, nil); err == nil {
+		t.Error("LoadCertificate() with mismatched private key should return error")
+	}
+	if _, err := LoadCertificate(keyPath, keyPath, nil); err == nil {
+		t.Error("LoadCertificate() of private key as certificate should return error")
+	}
+	if _, err := LoadCertificate(filepath.Join(dir, "missing.pem"), keyPath, nil); err == nil {
+		t.Error("LoadCertificate() of missing file should return error")
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	serverCA := newTestCertificateAuthority(t, "Server CA")
+	clientCA := newTestCertificateAuthority(t, "Client CA")
+	serverCert, err := serverCA.IssueServerCertificate([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("IssueServerCertificate() error: %v", err)
+	}
+	clientCert, err := clientCA.IssueClientCertificate("alex")
+	if err != nil {
+		t.Fatalf("IssueClientCertificate() error: %v", err)
+	}
+	otherClientCert, err := serverCA.IssueClientCertificate("mallory")
+	if err != nil {
+		t.Fatalf("IssueClientCertificate() error: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = NewServerTLSConfig(serverCert, clientCA)
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(config *tls.Config) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+		defer client.CloseIdleConnections()
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	body, err := get(NewClientTLSConfig(serverCA, clientCert))
+	if err != nil {
+		t.Fatalf("GET with client certificate error: %v", err)
+	}
+	if body != "alex" {
+		t.Errorf("GET with client certificate = %q, want alex", body)
+	}
+
+	tests := []struct {
+		name   string
+		config *tls.Config
+	}{
+		{"without client certificate", NewClientTLSConfig(serverCA, nil)},
+		{"client certificate of another authority", NewClientTLSConfig(serverCA, otherClientCert)},
+		{"untrusted server", NewClientTLSConfig(clientCA, clientCert)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := get(tt.config); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func BenchmarkIssueServerCertificate(b *testing.B) {
+	ca := newTestCertificateAuthority(b, "Test CA")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = ca.IssueServerCertificate([]string{"localhost"})
+	}
+}
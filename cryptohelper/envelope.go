@@ -0,0 +1,387 @@
+package cryptohelper
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptionAlgorithm is an AEAD algorithm of Envelope
+type EncryptionAlgorithm byte
+
+const (
+	AlgorithmAES256GCM         EncryptionAlgorithm = 1
+	AlgorithmXChaCha20Poly1305 EncryptionAlgorithm = 2
+)
+
+func (a EncryptionAlgorithm) String() string {
+	switch a {
+	case AlgorithmAES256GCM:
+		return "AES-256-GCM"
+	case AlgorithmXChaCha20Poly1305:
+		return "XChaCha20-Poly1305"
+	default:
+		return fmt.Sprintf("EncryptionAlgorithm(%d)", byte(a))
+	}
+}
+
+// KeyDerivation is an algorithm deriving a key of Envelope from passphrase
+type KeyDerivation byte
+
+const (
+	KeyDerivationNone     KeyDerivation = 0
+	KeyDerivationArgon2id KeyDerivation = 1
+	KeyDerivationScrypt   KeyDerivation = 2
+)
+
+const (
+	// EnvelopeKeySize is the size of keys of Envelope in bytes
+	EnvelopeKeySize = 32
+
+	envelopeVersion      = 1
+	envelopeModeMessage  = 0
+	envelopeModeStream   = 1
+	envelopeSaltSize     = 16
+	envelopeHeaderSize   = 4
+	envelopeKDFParamSize = 3*4 + envelopeSaltSize
+
+	// the second recommended option of RFC 9106
+	defaultArgon2idTime    = 3
+	defaultArgon2idMemory  = 64 * 1024
+	defaultArgon2idThreads = 4
+
+	// limits of parameters read from ciphertexts so that a crafted
+	// ciphertext cannot exhaust memory or CPU before it fails
+	// authentication; key derivation uses at most 256 MiB, which is four
+	// times the memory of the defaults
+	maxKDFMemory       = 256 * 1024 * 1024
+	maxArgon2idTime    = 16
+	maxArgon2idMemory  = maxKDFMemory / 1024
+	maxArgon2idThreads = 255
+	maxScryptCost      = 1 << 20
+	maxScryptBlockSize = 8
+	maxScryptThreads   = 4
+)
+
+var (
+	// ErrDecryptionFailed is returned when a ciphertext cannot be decrypted
+	// as the key or passphrase is incorrect or the ciphertext is modified
+	ErrDecryptionFailed = errors.New("unable to decrypt as key is incorrect or ciphertext is modified")
+
+	// ErrInvalidEnvelope is returned when a ciphertext is not in the format
+	// of Envelope or it is encrypted with an unsupported version or algorithm
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+)
+
+// Envelope encrypts and decrypts data with an AEAD algorithm for secrets at
+// rest, such as configuration and cached tokens. A ciphertext starts with a
+// header of the version of its format, the algorithm and, if the key is
+// derived from a passphrase, the parameters of key derivation and a random
+// salt; the header is authenticated together with the data. Decryption reads
+// the algorithm from the header so that ciphertexts of other algorithms can
+// still be decrypted after the default is changed.
+type Envelope struct {
+	key           []byte
+	passphrase    []byte
+	algorithm     EncryptionAlgorithm
+	keyDerivation KeyDerivation
+	kdfParams     [3]uint32
+	chunkSize     int
+}
+
+// EnvelopeOption is a functional option for NewEnvelope and
+// NewPassphraseEnvelope
+type EnvelopeOption func(*Envelope)
+
+// WithEncryptionAlgorithm sets the algorithm of encryption; it defaults to
+// AES-256-GCM. XChaCha20-Poly1305 has a larger nonce and it is preferred if
+// a key encrypts a very large number of messages.
+func WithEncryptionAlgorithm(algorithm EncryptionAlgorithm) EnvelopeOption {
+	return func(e *Envelope) {
+		e.algorithm = algorithm
+	}
+}
+
+// WithArgon2idKeyDerivation derives keys from passphrase with Argon2id of
+// the specified number of passes, memory in KiB and threads; it is the
+// default with 3 passes, 64 MiB and 4 threads
+func WithArgon2idKeyDerivation(time uint32, memory uint32, threads uint8) EnvelopeOption {
+	return func(e *Envelope) {
+		e.keyDerivation = KeyDerivationArgon2id
+		e.kdfParams = [3]uint32{time, memory, uint32(threads)}
+	}
+}
+
+// WithScryptKeyDerivation derives keys from passphrase with scrypt of the
+// specified CPU/memory cost (N), which must be a power of 2 such as 32768
+// and at most 262144 as scrypt uses 1 KiB of memory per unit of cost
+func WithScryptKeyDerivation(cost uint32) EnvelopeOption {
+	return func(e *Envelope) {
+		e.keyDerivation = KeyDerivationScrypt
+		e.kdfParams = [3]uint32{cost, 8, 1}
+	}
+}
+
+// WithChunkSize sets the size of chunks of plaintext in streaming
+// encryption; it defaults to 64 KiB
+func WithChunkSize(size int) EnvelopeOption {
+	return func(e *Envelope) {
+		e.chunkSize = size
+	}
+}
+
+func defaultEnvelope() *Envelope {
+	return &Envelope{
+		algorithm:     AlgorithmAES256GCM,
+		keyDerivation: KeyDerivationArgon2id,
+		kdfParams:     [3]uint32{defaultArgon2idTime, defaultArgon2idMemory, defaultArgon2idThreads},
+		chunkSize:     defaultChunkSize,
+	}
+}
+
+// NewEnvelope returns an Envelope with key of EnvelopeKeySize bytes, such as
+// one generated by GenerateEnvelopeKey
+func NewEnvelope(key []byte, opts ...EnvelopeOption) (*Envelope, error) {
+	if len(key) != EnvelopeKeySize {
+		return nil, fmt.Errorf("key must be %d bytes but got %d bytes", EnvelopeKeySize, len(key))
+	}
+	e := defaultEnvelope()
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.key = bytes.Clone(key)
+	e.keyDerivation = KeyDerivationNone
+	e.kdfParams = [3]uint32{}
+	return e, e.validate()
+}
+
+// NewPassphraseEnvelope returns an Envelope with keys derived from
+// passphrase; each ciphertext has its own random salt
+func NewPassphraseEnvelope(passphrase []byte, opts ...EnvelopeOption) (*Envelope, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrPassphraseRequired
+	}
+	e := defaultEnvelope()
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.passphrase = bytes.Clone(passphrase)
+	return e, e.validate()
+}
+
+// GenerateEnvelopeKey returns a random key for NewEnvelope
+func GenerateEnvelopeKey() ([]byte, error) {
+	key := make([]byte, EnvelopeKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("unable to generate key: %w", err)
+	}
+	return key, nil
+}
+
+func (e *Envelope) validate() error {
+	if _, err := newAEAD(e.algorithm, make([]byte, EnvelopeKeySize)); err != nil {
+		return err
+	}
+	if e.keyDerivation != KeyDerivationNone {
+		if err := validateKDFParams(e.keyDerivation, e.kdfParams); err != nil {
+			return err
+		}
+	}
+	if e.chunkSize < 1 || e.chunkSize > maxChunkSize {
+		return fmt.Errorf("chunk size must be between 1 and %d bytes", maxChunkSize)
+	}
+	return nil
+}
+
+// Seal encrypts plaintext and returns the ciphertext with its header
+func (e *Envelope) Seal(plaintext []byte) ([]byte, error) {
+	header, aead, err := e.newHeader(envelopeModeMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("unable to generate nonce: %w", err)
+	}
+	header = append(header, nonce...)
+	// the additional data must not overlap with the output
+	return aead.Seal(header, nonce, plaintext, bytes.Clone(header)), nil
+}
+
+// Open decrypts ciphertext returned by Seal
+func (e *Envelope) Open(ciphertext []byte) ([]byte, error) {
+	r := bytes.NewReader(ciphertext)
+	header, aead, err := e.readHeader(r, envelopeModeMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		return nil, fmt.Errorf("%w: unable to read nonce", ErrInvalidEnvelope)
+	}
+	header = append(header, nonce...)
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext[len(header):], header)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// Reencrypt decrypts ciphertext with from and encrypts it with to, such as
+// to rotate a key or passphrase or to change the algorithm
+func Reencrypt(ciphertext []byte, from *Envelope, to *Envelope) ([]byte, error) {
+	plaintext, err := from.Open(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+	return to.Seal(plaintext)
+}
+
+// newHeader returns a header of a new ciphertext, without nonce, and the
+// AEAD with its key
+func (e *Envelope) newHeader(mode byte) ([]byte, cipher.AEAD, error) {
+	header := []byte{envelopeVersion, byte(e.algorithm), byte(e.keyDerivation), mode}
+
+	key := e.key
+	if e.keyDerivation != KeyDerivationNone {
+		salt := make([]byte, envelopeSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, nil, fmt.Errorf("unable to generate salt: %w", err)
+		}
+		for _, p := range e.kdfParams {
+			header = binary.BigEndian.AppendUint32(header, p)
+		}
+		header = append(header, salt...)
+
+		derived, err := deriveKey(e.passphrase, salt, e.keyDerivation, e.kdfParams)
+		if err != nil {
+			return nil, nil, err
+		}
+		key = derived
+	}
+
+	aead, err := newAEAD(e.algorithm, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, aead, nil
+}
+
+// readHeader reads a header, without nonce, from r and returns it with the
+// AEAD with its key
+func (e *Envelope) readHeader(r io.Reader, mode byte) ([]byte, cipher.AEAD, error) {
+	header := make([]byte, envelopeHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, fmt.Errorf("%w: unable to read header", ErrInvalidEnvelope)
+	}
+	if header[0] != envelopeVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, header[0])
+	}
+	if header[3] != mode {
+		if mode == envelopeModeStream {
+			return nil, nil, fmt.Errorf("%w: ciphertext is not a stream", ErrInvalidEnvelope)
+		}
+		return nil, nil, fmt.Errorf("%w: ciphertext is a stream", ErrInvalidEnvelope)
+	}
+	algorithm := EncryptionAlgorithm(header[1])
+	keyDerivation := KeyDerivation(header[2])
+
+	key := e.key
+	switch {
+	case keyDerivation == KeyDerivationNone && e.key == nil:
+		return nil, nil, fmt.Errorf("%w: ciphertext is encrypted with a key instead of passphrase", ErrInvalidEnvelope)
+	case keyDerivation != KeyDerivationNone && e.key != nil:
+		return nil, nil, fmt.Errorf("%w: ciphertext is encrypted with a passphrase instead of key", ErrInvalidEnvelope)
+	case keyDerivation != KeyDerivationNone:
+		kdfBytes := make([]byte, envelopeKDFParamSize)
+		if _, err := io.ReadFull(r, kdfBytes); err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to read parameters of key derivation", ErrInvalidEnvelope)
+		}
+		header = append(header, kdfBytes...)
+
+		var params [3]uint32
+		for i := range params {
+			params[i] = binary.BigEndian.Uint32(kdfBytes[i*4:])
+		}
+		if err := validateKDFParams(keyDerivation, params); err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+		}
+		derived, err := deriveKey(e.passphrase, kdfBytes[len(params)*4:], keyDerivation, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		key = derived
+	}
+
+	aead, err := newAEAD(algorithm, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	return header, aead, nil
+}
+
+func newAEAD(algorithm EncryptionAlgorithm, key []byte) (cipher.AEAD, error) {
+	switch algorithm {
+	case AlgorithmAES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create AES cipher: %w", err)
+		}
+		return cipher.NewGCM(block)
+	case AlgorithmXChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	default:
+		return nil, fmt.Errorf("unsupported encryption algorithm %s", algorithm)
+	}
+}
+
+func validateKDFParams(keyDerivation KeyDerivation, params [3]uint32) error {
+	switch keyDerivation {
+	case KeyDerivationArgon2id:
+		if params[0] < 1 || params[0] > maxArgon2idTime ||
+			params[1] < 8*params[2] || params[1] > maxArgon2idMemory ||
+			params[2] < 1 || params[2] > maxArgon2idThreads {
+			return fmt.Errorf("invalid parameters of Argon2id %v", params)
+		}
+	case KeyDerivationScrypt:
+		n := params[0]
+		// scrypt allocates 128 * N * r bytes
+		if n < 2 || n&(n-1) != 0 || n > maxScryptCost ||
+			params[1] < 1 || params[1] > maxScryptBlockSize ||
+			params[2] < 1 || params[2] > maxScryptThreads ||
+			128*uint64(n)*uint64(params[1]) > maxKDFMemory {
+			return fmt.Errorf("invalid parameters of scrypt %v", params)
+		}
+	default:
+		return fmt.Errorf("unsupported key derivation %d", keyDerivation)
+	}
+	return nil
+}
+
+func deriveKey(passphrase []byte, salt []byte, keyDerivation KeyDerivation, params [3]uint32) ([]byte, error) {
+	switch keyDerivation {
+	case KeyDerivationArgon2id:
+		return argon2.IDKey(passphrase, salt, params[0], params[1], uint8(params[2]), EnvelopeKeySize), nil
+	case KeyDerivationScrypt:
+		key, err := scrypt.Key(passphrase, salt, int(params[0]), int(params[1]), int(params[2]), EnvelopeKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("unable to derive key with scrypt: %w", err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key derivation %d", keyDerivation)
+	}
+}
@@ -0,0 +1,245 @@
+package cryptohelper
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	defaultChunkSize = 64 * 1024
+	maxChunkSize     = 16 * 1024 * 1024
+
+	// a nonce of a chunk is the random prefix of the stream followed by the
+	// counter of the chunk and a flag of the last chunk (STREAM construction)
+	streamNonceSuffixSize = 4 + 1
+)
+
+// NewWriter returns a writer encrypting data written to it into w in chunks
+// so that large files can be encrypted without being loaded into memory.
+// Each chunk is authenticated with its position and the last chunk is marked
+// so that reordered, removed or truncated chunks are detected. Close must be
+// called to write the last chunk; it does not close w.
+func (e *Envelope) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	header, aead, err := e.newHeader(envelopeModeStream)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, aead.NonceSize()-streamNonceSuffixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("unable to generate nonce: %w", err)
+	}
+	header = binary.BigEndian.AppendUint32(header, uint32(e.chunkSize))
+	header = append(header, prefix...)
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("unable to write header: %w", err)
+	}
+
+	return &encryptWriter{
+		w: w,
+		stream: stream{
+			aead:   aead,
+			header: header,
+			prefix: prefix,
+		},
+		buffer:    make([]byte, 0, e.chunkSize),
+		chunkSize: e.chunkSize,
+	}, nil
+}
+
+// NewReader returns a reader decrypting the stream written by the writer of
+// NewWriter from r. Data of a chunk is returned only after the chunk is
+// authenticated and an error matching ErrDecryptionFailed is returned if the
+// stream is modified or truncated.
+func (e *Envelope) NewReader(r io.Reader) (io.Reader, error) {
+	header, aead, err := e.readHeader(r, envelopeModeStream)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := make([]byte, 4+aead.NonceSize()-streamNonceSuffixSize)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, fmt.Errorf("%w: unable to read header", ErrInvalidEnvelope)
+	}
+	chunkSize := binary.BigEndian.Uint32(rest)
+	if chunkSize < 1 || chunkSize > maxChunkSize {
+		return nil, fmt.Errorf("%w: invalid chunk size %d", ErrInvalidEnvelope, chunkSize)
+	}
+	header = append(header, rest...)
+
+	return &decryptReader{
+		r: bufio.NewReader(r),
+		stream: stream{
+			aead:   aead,
+			header: header,
+			prefix: rest[4:],
+		},
+		chunk: make([]byte, int(chunkSize)+aead.Overhead()),
+	}, nil
+}
+
+// ReencryptStream decrypts the stream in src with from and writes it to dst
+// encrypted with to, such as to rotate the key of a large file
+func ReencryptStream(dst io.Writer, src io.Reader, from *Envelope, to *Envelope) error {
+	r, err := from.NewReader(src)
+	if err != nil {
+		return err
+	}
+	w, err := to.NewWriter(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+type stream struct {
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	counter uint32
+}
+
+func (s *stream) nextNonce(isLast bool) ([]byte, error) {
+	if s.counter == math.MaxUint32 {
+		return nil, errors.New("stream has too many chunks")
+	}
+	nonce := make([]byte, 0, s.aead.NonceSize())
+	nonce = append(nonce, s.prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, s.counter)
+	if isLast {
+		nonce = append(nonce, 1)
+	} else {
+		nonce = append(nonce, 0)
+	}
+	s.counter++
+	return nonce, nil
+}
+
+type encryptWriter struct {
+	w         io.Writer
+	stream    stream
+	buffer    []byte
+	chunkSize int
+	isClosed  bool
+	err       error
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	if w.isClosed {
+		return 0, errors.New("write to closed writer")
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// a full chunk is written only when more data comes as the last
+		// chunk has to be marked
+		if len(w.buffer) == w.chunkSize {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buffer[len(w.buffer):w.chunkSize], p)
+		w.buffer = w.buffer[:len(w.buffer)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *encryptWriter) Close() error {
+	if w.isClosed {
+		return nil
+	}
+	w.isClosed = true
+	if w.err != nil {
+		return w.err
+	}
+	err := w.flush(true)
+	clear(w.buffer)
+	return err
+}
+
+func (w *encryptWriter) flush(isLast bool) error {
+	nonce, err := w.stream.nextNonce(isLast)
+	if err != nil {
+		w.err = err
+		return err
+	}
+	sealed := w.stream.aead.Seal(nil, nonce, w.buffer, w.stream.header)
+	if _, err := w.w.Write(sealed); err != nil {
+		w.err = fmt.Errorf("unable to write chunk: %w", err)
+		return w.err
+	}
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+type decryptReader struct {
+	r         *bufio.Reader
+	stream    stream
+	chunk     []byte
+	plaintext []byte
+	isDone    bool
+	err       error
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plaintext) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.isDone {
+			return 0, io.EOF
+		}
+		r.err = r.readChunk()
+	}
+	n := copy(p, r.plaintext)
+	r.plaintext = r.plaintext[n:]
+	return n, nil
+}
+
+func (r *decryptReader) readChunk() error {
+	n, err := io.ReadFull(r.r, r.chunk)
+	isLast := false
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: stream is truncated", ErrDecryptionFailed)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		isLast = true
+	case err != nil:
+		return fmt.Errorf("unable to read chunk: %w", err)
+	default:
+		if _, err := r.r.Peek(1); errors.Is(err, io.EOF) {
+			isLast = true
+		} else if err != nil {
+			return fmt.Errorf("unable to read chunk: %w", err)
+		}
+	}
+
+	nonce, err := r.stream.nextNonce(isLast)
+	if err != nil {
+		return err
+	}
+	plaintext, err := r.stream.aead.Open(r.chunk[:0], nonce, r.chunk[:n], r.stream.header)
+	if err != nil {
+		if isLast {
+			return fmt.Errorf("%w: stream is truncated or modified", ErrDecryptionFailed)
+		}
+		return ErrDecryptionFailed
+	}
+	r.plaintext = plaintext
+	r.isDone = isLast
+	return nil
+}
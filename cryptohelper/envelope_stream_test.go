@@ -0,0 +1,186 @@
+package cryptohelper
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+const testChunkSize = 16
+
+func encryptStream(t testing.TB, e *Envelope, plaintext []byte, writeSize int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := e.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter() error: %v", err)
+	}
+	for len(plaintext) > 0 {
+		n := min(writeSize, len(plaintext))
+		if _, err := w.Write(plaintext[:n]); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+		plaintext = plaintext[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(e *Envelope, ciphertext []byte) ([]byte, error) {
+	r, err := e.NewReader(bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestEnvelopeStream(t *testing.T) {
+	envelopes := []struct {
+		name     string
+		envelope *Envelope
+	}{
+		{"AES-256-GCM", newTestEnvelope(t, WithChunkSize(testChunkSize))},
+		{"XChaCha20-Poly1305", newTestEnvelope(t, WithChunkSize(testChunkSize), WithEncryptionAlgorithm(AlgorithmXChaCha20Poly1305))},
+		{"passphrase", newTestPassphraseEnvelope(t, testPassphrase, WithChunkSize(testChunkSize))},
+	}
+	sizes := []int{0, 1, testChunkSize - 1, testChunkSize, testChunkSize + 1, 3 * testChunkSize, 100}
+
+	for _, ee := range envelopes {
+		for _, size := range sizes {
+			for _, writeSize := range []int{1, 7, 1000} {
+				plaintext := make([]byte, size)
+				_, _ = rand.Read(plaintext)
+
+				ciphertext := encryptStream(t, ee.envelope, plaintext, writeSize)
+				decrypted, err := decryptStream(ee.envelope, ciphertext)
+				if err != nil {
+					t.Fatalf("%s: decrypt %d bytes written in %d bytes error: %v", ee.name, size, writeSize, err)
+				}
+				if !bytes.Equal(decrypted, plaintext) {
+					t.Errorf("%s: decrypt %d bytes written in %d bytes = %x, want %x", ee.name, size, writeSize, decrypted, plaintext)
+				}
+			}
+		}
+	}
+}
+
+func TestEnvelopeStreamOneByteReader(t *testing.T) {
+	e := newTestEnvelope(t, WithChunkSize(testChunkSize))
+	plaintext := bytes.Repeat([]byte("abc"), 20)
+	ciphertext := encryptStream(t, e, plaintext, len(plaintext))
+
+	r, err := e.NewReader(iotest.OneByteReader(bytes.NewReader(ciphertext)))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	if err := iotest.TestReader(r, plaintext); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEnvelopeStreamModified(t *testing.T) {
+	e := newTestEnvelope(t, WithChunkSize(testChunkSize))
+	plaintext := bytes.Repeat([]byte("a"), 3*testChunkSize+5)
+	ciphertext := encryptStream(t, e, plaintext, len(plaintext))
+
+	// header, nonce prefix and chunk size followed by three full chunks and
+	// the last one
+	headerSize := envelopeHeaderSize + 4 + 12 - streamNonceSuffixSize
+	sealedChunkSize := testChunkSize + 16
+	chunk := func(i int) []byte {
+		start := headerSize + i*sealedChunkSize
+		return ciphertext[start:min(start+sealedChunkSize, len(ciphertext))]
+	}
+	concat := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	header := ciphertext[:headerSize]
+	modified := bytes.Clone(ciphertext)
+	modified[headerSize+sealedChunkSize+5] ^= 1
+
+	tests := []struct {
+		name       string
+		ciphertext []byte
+	}{
+		{"last chunk removed", concat(header, chunk(0), chunk(1), chunk(2))},
+		{"chunks reordered", concat(header, chunk(1), chunk(0), chunk(2), chunk(3))},
+		{"chunk removed", concat(header, chunk(0), chunk(2), chunk(3))},
+		{"truncated last chunk", ciphertext[:len(ciphertext)-1]},
+		{"data appended", concat(ciphertext, []byte{0})},
+		{"no chunk", header},
+		{"modified chunk", modified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decryptStream(e, tt.ciphertext)
+			if !errors.Is(err, ErrDecryptionFailed) {
+				t.Errorf("decrypt error = %v, want %v", err, ErrDecryptionFailed)
+			}
+		})
+	}
+}
+
+func TestEnvelopeStreamAndMessageAreNotInterchangeable(t *testing.T) {
+	e := newTestEnvelope(t)
+	message, _ := e.Seal([]byte("secret"))
+	stream := encryptStream(t, e, []byte("secret"), 6)
+
+	if _, err := e.Open(stream); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("Open() error = %v, want %v", err, ErrInvalidEnvelope)
+	}
+	if _, err := e.NewReader(bytes.NewReader(message)); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("NewReader() error = %v, want %v", err, ErrInvalidEnvelope)
+	}
+}
+
+func TestEnvelopeStreamWriterClose(t *testing.T) {
+	e := newTestEnvelope(t)
+	w, err := e.NewWriter(io.Discard)
+	if err != nil {
+		t.Fatalf("NewWriter() error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() error: %v", err)
+	}
+	if _, err := w.Write([]byte("a")); err == nil {
+		t.Error("Write() after Close() should return error")
+	}
+}
+
+func TestReencryptStream(t *testing.T) {
+	oldEnvelope := newTestEnvelope(t, WithChunkSize(testChunkSize))
+	newEnvelope := newTestPassphraseEnvelope(t, testPassphrase, WithEncryptionAlgorithm(AlgorithmXChaCha20Poly1305))
+	plaintext := bytes.Repeat([]byte("large file "), 10)
+	ciphertext := encryptStream(t, oldEnvelope, plaintext, 3)
+
+	var rotated bytes.Buffer
+	if err := ReencryptStream(&rotated, bytes.NewReader(ciphertext), oldEnvelope, newEnvelope); err != nil {
+		t.Fatalf("ReencryptStream() error: %v", err)
+	}
+
+	decrypted, err := decryptStream(newEnvelope, rotated.Bytes())
+	if err != nil || !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("decrypt = %q, %v, want %q", decrypted, err, plaintext)
+	}
+}
+
+func BenchmarkEnvelopeStream(b *testing.B) {
+	e := newTestEnvelope(b)
+	plaintext := bytes.Repeat([]byte("a"), 1024*1024)
+	b.SetBytes(int64(len(plaintext)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w, _ := e.NewWriter(io.Discard)
+		_, _ = w.Write(plaintext)
+		_ = w.Close()
+	}
+}
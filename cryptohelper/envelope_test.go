@@ -0,0 +1,271 @@
+package cryptohelper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// fastKeyDerivation keeps tests fast; it must not be used otherwise
+var fastKeyDerivation = WithArgon2idKeyDerivation(1, 64, 1)
+
+func newTestEnvelope(t testing.TB, opts ...EnvelopeOption) *Envelope {
+	t.Helper()
+	key, err := GenerateEnvelopeKey()
+	if err != nil {
+		t.Fatalf("GenerateEnvelopeKey() error: %v", err)
+	}
+	e, err := NewEnvelope(key, opts...)
+	if err != nil {
+		t.Fatalf("NewEnvelope() error: %v", err)
+	}
+	return e
+}
+
+func newTestPassphraseEnvelope(t testing.TB, passphrase string, opts ...EnvelopeOption) *Envelope {
+	t.Helper()
+	e, err := NewPassphraseEnvelope([]byte(passphrase), append([]EnvelopeOption{fastKeyDerivation}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewPassphraseEnvelope() error: %v", err)
+	}
+	return e
+}
+
+func TestEnvelopeSealAndOpen(t *testing.T) {
+	tests := []struct {
+		name          string
+		envelope      *Envelope
+		algorithm     EncryptionAlgorithm
+		keyDerivation KeyDerivation
+	}{
+		{"AES-256-GCM", newTestEnvelope(t), AlgorithmAES256GCM, KeyDerivationNone},
+		{"XChaCha20-Poly1305", newTestEnvelope(t, WithEncryptionAlgorithm(AlgorithmXChaCha20Poly1305)), AlgorithmXChaCha20Poly1305, KeyDerivationNone},
+		{"Argon2id", newTestPassphraseEnvelope(t, testPassphrase), AlgorithmAES256GCM, KeyDerivationArgon2id},
+		{"scrypt", newTestPassphraseEnvelope(t, testPassphrase, WithScryptKeyDerivation(1<<10), WithEncryptionAlgorithm(AlgorithmXChaCha20Poly1305)), AlgorithmXChaCha20Poly1305, KeyDerivationScrypt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, plaintext := range [][]byte{nil, []byte("secret"), bytes.Repeat([]byte("a"), 100000)} {
+				ciphertext, err := tt.envelope.Seal(plaintext)
+				if err != nil {
+					t.Fatalf("Seal() error: %v", err)
+				}
+				if ciphertext[0] != envelopeVersion || EncryptionAlgorithm(ciphertext[1]) != tt.algorithm || KeyDerivation(ciphertext[2]) != tt.keyDerivation {
+					t.Errorf("Seal() header = %v", ciphertext[:4])
+				}
+				if len(plaintext) > 0 && bytes.Contains(ciphertext, plaintext) {
+					t.Error("Seal() returned ciphertext containing plaintext")
+				}
+
+				decrypted, err := tt.envelope.Open(ciphertext)
+				if err != nil {
+					t.Fatalf("Open() error: %v", err)
+				}
+				if !bytes.Equal(decrypted, plaintext) {
+					t.Errorf("Open() = %q, want %q", decrypted, plaintext)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvelopeSealIsRandomized(t *testing.T) {
+	for _, e := range []*Envelope{newTestEnvelope(t), newTestPassphraseEnvelope(t, testPassphrase)} {
+		first, _ := e.Seal([]byte("secret"))
+		second, _ := e.Seal([]byte("secret"))
+		if bytes.Equal(first, second) {
+			t.Error("Seal() returned the same ciphertext twice")
+		}
+	}
+}
+
+func TestEnvelopeDefaultKeyDerivation(t *testing.T) {
+	e, err := NewPassphraseEnvelope([]byte(testPassphrase))
+	if err != nil {
+		t.Fatalf("NewPassphraseEnvelope() error: %v", err)
+	}
+	ciphertext, err := e.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal() error: %v", err)
+	}
+	params := ciphertext[envelopeHeaderSize : envelopeHeaderSize+12]
+	if binary.BigEndian.Uint32(params) != 3 || binary.BigEndian.Uint32(params[4:]) != 64*1024 || binary.BigEndian.Uint32(params[8:]) != 4 {
+		t.Errorf("Seal() parameters of key derivation = %v", params)
+	}
+	if _, err := e.Open(ciphertext); err != nil {
+		t.Errorf("Open() error: %v", err)
+	}
+}
+
+func TestEnvelopeOpenErrors(t *testing.T) {
+	keyEnvelope := newTestEnvelope(t)
+	passphraseEnvelope := newTestPassphraseEnvelope(t, testPassphrase)
+	keyCiphertext, _ := keyEnvelope.Seal([]byte("secret"))
+	passphraseCiphertext, _ := passphraseEnvelope.Seal([]byte("secret"))
+
+	modify := func(ciphertext []byte, index int, value byte) []byte {
+		modified := bytes.Clone(ciphertext)
+		if index < 0 {
+			index += len(modified)
+		}
+		modified[index] = value
+		return modified
+	}
+	withKDFParams := func(ciphertext []byte, params ...uint32) []byte {
+		modified := bytes.Clone(ciphertext)
+		for i, p := range params {
+			binary.BigEndian.PutUint32(modified[envelopeHeaderSize+i*4:], p)
+		}
+		return modified
+	}
+	scryptCiphertext, _ := newTestPassphraseEnvelope(t, testPassphrase, WithScryptKeyDerivation(1<<10)).Seal([]byte("secret"))
+
+	tests := []struct {
+		name       string
+		envelope   *Envelope
+		ciphertext []byte
+		expected   error
+	}{
+		{"incorrect key", newTestEnvelope(t), keyCiphertext, ErrDecryptionFailed},
+		{"incorrect passphrase", newTestPassphraseEnvelope(t, "incorrect"), passphraseCiphertext, ErrDecryptionFailed},
+		{"modified data", keyEnvelope, modify(keyCiphertext, -1, keyCiphertext[len(keyCiphertext)-1]^1), ErrDecryptionFailed},
+		{"modified algorithm", keyEnvelope, modify(keyCiphertext, 1, byte(AlgorithmXChaCha20Poly1305)), ErrDecryptionFailed},
+		{"modified salt", passphraseEnvelope, modify(passphraseCiphertext, envelopeHeaderSize+12, passphraseCiphertext[envelopeHeaderSize+12]^1), ErrDecryptionFailed},
+		{"unsupported version", keyEnvelope, modify(keyCiphertext, 0, 2), ErrInvalidEnvelope},
+		{"unsupported algorithm", keyEnvelope, modify(keyCiphertext, 1, 9), ErrInvalidEnvelope},
+		{"unsupported key derivation", passphraseEnvelope, modify(passphraseCiphertext, 2, 9), ErrInvalidEnvelope},
+		// parameters above the limits are rejected with ErrInvalidEnvelope
+		// before key derivation instead of ErrDecryptionFailed after it
+		{"excessive memory of Argon2id", passphraseEnvelope, withKDFParams(passphraseCiphertext, 1, maxArgon2idMemory+1, 1), ErrInvalidEnvelope},
+		{"excessive passes of Argon2id", passphraseEnvelope, withKDFParams(passphraseCiphertext, maxArgon2idTime+1, 8, 1), ErrInvalidEnvelope},
+		{"excessive cost of scrypt", passphraseEnvelope, withKDFParams(scryptCiphertext, 1<<19, 8, 1), ErrInvalidEnvelope},
+		{"excessive block size of scrypt", passphraseEnvelope, withKDFParams(scryptCiphertext, 2, 1<<20, 1), ErrInvalidEnvelope},
+		{"excessive threads of scrypt", passphraseEnvelope, withKDFParams(scryptCiphertext, 2, 1, 1<<20), ErrInvalidEnvelope},
+		{"passphrase ciphertext with key", keyEnvelope, passphraseCiphertext, ErrInvalidEnvelope},
+		{"key ciphertext with passphrase", passphraseEnvelope, keyCiphertext, ErrInvalidEnvelope},
+		{"truncated header", keyEnvelope, keyCiphertext[:3], ErrInvalidEnvelope},
+		{"truncated nonce", keyEnvelope, keyCiphertext[:10], ErrInvalidEnvelope},
+		{"truncated data", keyEnvelope, keyCiphertext[:len(keyCiphertext)-1], ErrDecryptionFailed},
+		{"empty", keyEnvelope, nil, ErrInvalidEnvelope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.envelope.Open(tt.ciphertext)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Open() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewEnvelopeErrors(t *testing.T) {
+	key, _ := GenerateEnvelopeKey()
+
+	tests := []struct {
+		name string
+		new  func() (*Envelope, error)
+	}{
+		{"short key", func() (*Envelope, error) { return NewEnvelope(key[:16]) }},
+		{"unsupported algorithm", func() (*Envelope, error) { return NewEnvelope(key, WithEncryptionAlgorithm(9)) }},
+		{"invalid chunk size", func() (*Envelope, error) { return NewEnvelope(key, WithChunkSize(0)) }},
+		{"empty passphrase", func() (*Envelope, error) { return NewPassphraseEnvelope(nil) }},
+		{"invalid Argon2id parameters", func() (*Envelope, error) {
+			return NewPassphraseEnvelope([]byte(testPassphrase), WithArgon2idKeyDerivation(0, 64, 1))
+		}},
+		{"invalid scrypt cost", func() (*Envelope, error) {
+			return NewPassphraseEnvelope([]byte(testPassphrase), WithScryptKeyDerivation(1000))
+		}},
+		{"excessive scrypt cost", func() (*Envelope, error) {
+			return NewPassphraseEnvelope([]byte(testPassphrase), WithScryptKeyDerivation(1<<19))
+		}},
+		{"excessive Argon2id memory", func() (*Envelope, error) {
+			return NewPassphraseEnvelope([]byte(testPassphrase), WithArgon2idKeyDerivation(1, maxArgon2idMemory+1, 1))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.new(); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestEncryptionAlgorithmString(t *testing.T) {
+	tests := []struct {
+		algorithm EncryptionAlgorithm
+		expected  string
+	}{
+		{AlgorithmAES256GCM, "AES-256-GCM"},
+		{AlgorithmXChaCha20Poly1305, "XChaCha20-Poly1305"},
+		{9, "EncryptionAlgorithm(9)"},
+	}
+
+	for _, tt := range tests {
+		if actual := tt.algorithm.String(); actual != tt.expected {
+			t.Errorf("String() = %q, want %q", actual, tt.expected)
+		}
+	}
+}
+
+func TestNewEnvelopeCopiesKey(t *testing.T) {
+	key, _ := GenerateEnvelopeKey()
+	e, _ := NewEnvelope(key)
+	ciphertext, _ := e.Seal([]byte("secret"))
+
+	clear(key)
+	if _, err := e.Open(ciphertext); err != nil {
+		t.Errorf("Open() error after key is cleared by caller: %v", err)
+	}
+}
+
+func TestReencrypt(t *testing.T) {
+	oldEnvelope := newTestPassphraseEnvelope(t, "old passphrase")
+	newEnvelope := newTestEnvelope(t, WithEncryptionAlgorithm(AlgorithmXChaCha20Poly1305))
+
+	ciphertext, err := oldEnvelope.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal() error: %v", err)
+	}
+	rotated, err := Reencrypt(ciphertext, oldEnvelope, newEnvelope)
+	if err != nil {
+		t.Fatalf("Reencrypt() error: %v", err)
+	}
+
+	plaintext, err := newEnvelope.Open(rotated)
+	if err != nil || string(plaintext) != "secret" {
+		t.Errorf("Open() = %q, %v, want secret", plaintext, err)
+	}
+	if _, err := oldEnvelope.Open(rotated); err == nil {
+		t.Error("Open() with old envelope should return error")
+	}
+	if _, err := Reencrypt(ciphertext, newEnvelope, oldEnvelope); err == nil {
+		t.Error("Reencrypt() with incorrect envelope should return error")
+	}
+}
+
+func BenchmarkEnvelopeSeal(b *testing.B) {
+	plaintext := bytes.Repeat([]byte("a"), 1024)
+	for _, algorithm := range []EncryptionAlgorithm{AlgorithmAES256GCM, AlgorithmXChaCha20Poly1305} {
+		e := newTestEnvelope(b, WithEncryptionAlgorithm(algorithm))
+		b.Run(algorithm.String(), func(b *testing.B) {
+			b.SetBytes(int64(len(plaintext)))
+			for i := 0; i < b.N; i++ {
+				_, _ = e.Seal(plaintext)
+			}
+		})
+	}
+}
+
+func BenchmarkEnvelopeOpen(b *testing.B) {
+	e := newTestEnvelope(b)
+	ciphertext, _ := e.Seal(bytes.Repeat([]byte("a"), 1024))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = e.Open(ciphertext)
+	}
+}
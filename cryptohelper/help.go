@@ -2,28 +2,20 @@ package cryptohelper
 
 import (
 	"crypto/ecdsa"
-	"encoding/pem"
 	"fmt"
-
-	"log/slog"
-
-	"github.com/youmark/pkcs8"
 )
 
+// GetEcdsaKey returns the ECDSA private key in privateKeyBytes; see
+// ParsePrivateKey for the supported formats
 func GetEcdsaKey(privateKeyBytes []byte, passphraseBytes []byte) (*ecdsa.PrivateKey, error) {
-	block, _ := pem.Decode(privateKeyBytes)
-	if block == nil {
-		return nil, fmt.Errorf("unable to decode private key")
-	}
-
-	slog.Debug("Decoded private key",
-		slog.String("key_type", block.Type),
-	)
-
-	decryptedBlockBytes, err := pkcs8.ParsePKCS8PrivateKeyECDSA(block.Bytes, passphraseBytes)
+	signer, err := ParsePrivateKey(privateKeyBytes, passphraseBytes)
 	if err != nil {
-		return nil, fmt.Errorf("unable to decrypt private key: %w", err)
+		return nil, err
 	}
 
-	return decryptedBlockBytes, nil
+	key, ok := signer.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected ECDSA key but got %T", ErrUnsupportedKey, signer)
+	}
+	return key, nil
 }
@@ -0,0 +1,433 @@
+package cryptohelper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	jwkKeyTypeRSA = "RSA"
+	jwkKeyTypeEC  = "EC"
+	jwkKeyTypeOKP = "OKP"
+
+	jwkCurveP256    = "P-256"
+	jwkCurveP384    = "P-384"
+	jwkCurveP521    = "P-521"
+	jwkCurveEd25519 = "Ed25519"
+
+	// JWKUseSignature is the public key use (use) of keys for signatures
+	JWKUseSignature = "sig"
+)
+
+// ErrKeyNotFound is returned when a JWKS does not have a key of an ID
+var ErrKeyNotFound = errors.New("key is not found")
+
+// JWK is a JSON Web Key (RFC 7517) of an RSA, ECDSA or Ed25519 key. Private
+// members are empty for public keys.
+type JWK struct {
+	KeyType   string           `json:"kty"`
+	KeyID     string           `json:"kid,omitempty"`
+	Use       string           `json:"use,omitempty"`
+	Algorithm SigningAlgorithm `json:"alg,omitempty"`
+
+	// RSA
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// ECDSA and Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+
+	// private exponent of RSA, private key of ECDSA or seed of Ed25519
+	D string `json:"d,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set, such as the one published by an identity
+// provider at its jwks_uri
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKOptions configures the optional members (kid, use and alg) of the JWK
+// returned by NewJWK
+type JWKOptions struct {
+	keyID     string
+	use       string
+	algorithm SigningAlgorithm
+}
+
+// JWKOption is a functional option for NewJWK
+type JWKOption func(*JWKOptions)
+
+// WithJWKKeyID sets the ID of the key (kid); it defaults to the thumbprint
+// of the key
+func WithJWKKeyID(keyID string) JWKOption {
+	return func(o *JWKOptions) {
+		o.keyID = keyID
+	}
+}
+
+// WithJWKUse sets the intended use of the key (use), such as
+// JWKUseSignature
+func WithJWKUse(use string) JWKOption {
+	return func(o *JWKOptions) {
+		o.use = use
+	}
+}
+
+// WithJWKAlgorithm sets the algorithm intended to be used with the key
+// (alg)
+func WithJWKAlgorithm(algorithm SigningAlgorithm) JWKOption {
+	return func(o *JWKOptions) {
+		o.algorithm = algorithm
+	}
+}
+
+func defaultJWKOptions() *JWKOptions {
+	return &JWKOptions{}
+}
+
+// NewJWK returns the JWK of an RSA, ECDSA or Ed25519 public or private key,
+// such as one returned by identity.GetPublicKey or ParsePrivateKey. Its ID
+// is the thumbprint of the key (RFC 7638) unless WithJWKKeyID is specified.
+func NewJWK(key any, opts ...JWKOption) (*JWK, error) {
+	options := defaultJWKOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var jwk *JWK
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		jwk = newRSAJWK(k)
+	case *rsa.PrivateKey:
+		jwk = newRSAJWK(&k.PublicKey)
+		if len(k.Primes) != 2 {
+			return nil, fmt.Errorf("%w: RSA key with %d primes", ErrUnsupportedKey, len(k.Primes))
+		}
+		// CRT values are computed rather than k.Precompute() so that the
+		// key of the caller is not modified
+		p, q := k.Primes[0], k.Primes[1]
+		one := big.NewInt(1)
+		qi := new(big.Int).ModInverse(q, p)
+		if qi == nil {
+			return nil, fmt.Errorf("%w: RSA key with primes which are not coprime", ErrUnsupportedKey)
+		}
+		jwk.D = encodeBigInt(k.D)
+		jwk.P = encodeBigInt(p)
+		jwk.Q = encodeBigInt(q)
+		jwk.DP = encodeBigInt(new(big.Int).Mod(k.D, new(big.Int).Sub(p, one)))
+		jwk.DQ = encodeBigInt(new(big.Int).Mod(k.D, new(big.Int).Sub(q, one)))
+		jwk.QI = encodeBigInt(qi)
+	case *ecdsa.PublicKey:
+		var err error
+		if jwk, err = newECJWK(k); err != nil {
+			return nil, err
+		}
+	case *ecdsa.PrivateKey:
+		var err error
+		if jwk, err = newECJWK(&k.PublicKey); err != nil {
+			return nil, err
+		}
+		d, err := k.Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode ECDSA private key: %w", err)
+		}
+		jwk.D = base64URL.EncodeToString(d)
+	case ed25519.PublicKey:
+		jwk = &JWK{KeyType: jwkKeyTypeOKP, Curve: jwkCurveEd25519, X: base64URL.EncodeToString(k)}
+	case ed25519.PrivateKey:
+		jwk = &JWK{KeyType: jwkKeyTypeOKP, Curve: jwkCurveEd25519, X: base64URL.EncodeToString(k.Public().(ed25519.PublicKey))}
+		jwk.D = base64URL.EncodeToString(k.Seed())
+	default:
+		return nil, fmt.Errorf("%w: key type %T", ErrUnsupportedKey, key)
+	}
+
+	jwk.Use = options.use
+	jwk.Algorithm = options.algorithm
+	jwk.KeyID = options.keyID
+	if jwk.KeyID == "" {
+		thumbprint, err := jwk.Thumbprint()
+		if err != nil {
+			return nil, err
+		}
+		jwk.KeyID = thumbprint
+	}
+	return jwk, nil
+}
+
+func newRSAJWK(k *rsa.PublicKey) *JWK {
+	return &JWK{
+		KeyType: jwkKeyTypeRSA,
+		N:       encodeBigInt(k.N),
+		E:       encodeBigInt(big.NewInt(int64(k.E))),
+	}
+}
+
+func newECJWK(k *ecdsa.PublicKey) (*JWK, error) {
+	curve, err := getJWKCurve(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+	// uncompressed point of 0x04 followed by fixed-size X and Y
+	point, err := k.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode ECDSA public key: %w", err)
+	}
+	size := (len(point) - 1) / 2
+	return &JWK{
+		KeyType: jwkKeyTypeEC,
+		Curve:   curve,
+		X:       base64URL.EncodeToString(point[1 : 1+size]),
+		Y:       base64URL.EncodeToString(point[1+size:]),
+	}, nil
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64URL.EncodeToString(i.Bytes())
+}
+
+func decodeBigInt(name string, s string) (*big.Int, error) {
+	b, err := base64URL.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid JWK member %s", name)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func getJWKCurve(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwkCurveP256, nil
+	case elliptic.P384():
+		return jwkCurveP384, nil
+	case elliptic.P521():
+		return jwkCurveP521, nil
+	default:
+		return "", fmt.Errorf("%w: ECDSA curve %s", ErrUnsupportedKey, curve.Params().Name)
+	}
+}
+
+func getEllipticCurve(curve string) (elliptic.Curve, error) {
+	switch curve {
+	case jwkCurveP256:
+		return elliptic.P256(), nil
+	case jwkCurveP384:
+		return elliptic.P384(), nil
+	case jwkCurveP521:
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("%w: JWK curve %q", ErrUnsupportedKey, curve)
+	}
+}
+
+// IsPrivate returns true if the JWK has a private key
+func (j *JWK) IsPrivate() bool {
+	return j.D != ""
+}
+
+// Public returns a copy of the JWK without its private members, which can be
+// published
+func (j *JWK) Public() *JWK {
+	return &JWK{
+		KeyType:   j.KeyType,
+		KeyID:     j.KeyID,
+		Use:       j.Use,
+		Algorithm: j.Algorithm,
+		N:         j.N,
+		E:         j.E,
+		Curve:     j.Curve,
+		X:         j.X,
+		Y:         j.Y,
+	}
+}
+
+// PublicKey returns the key as *rsa.PublicKey, *ecdsa.PublicKey or
+// ed25519.PublicKey
+func (j *JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.KeyType {
+	case jwkKeyTypeRSA:
+		n, err := decodeBigInt("n", j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt("e", j.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 || e.Int64() < 3 {
+			return nil, fmt.Errorf("invalid RSA public exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case jwkKeyTypeEC:
+		curve, err := getEllipticCurve(j.Curve)
+		if err != nil {
+			return nil, err
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		x, errX := base64URL.DecodeString(j.X)
+		y, errY := base64URL.DecodeString(j.Y)
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("invalid JWK member x or y")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		key, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECDSA public key: %w", err)
+		}
+		return key, nil
+	case jwkKeyTypeOKP:
+		if j.Curve != jwkCurveEd25519 {
+			return nil, fmt.Errorf("%w: JWK curve %q", ErrUnsupportedKey, j.Curve)
+		}
+		x, err := base64URL.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid JWK member x")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w: JWK key type %q", ErrUnsupportedKey, j.KeyType)
+	}
+}
+
+// PrivateKey returns the key as *rsa.PrivateKey, *ecdsa.PrivateKey or
+// ed25519.PrivateKey; the JWK must have a private key
+func (j *JWK) PrivateKey() (crypto.Signer, error) {
+	if !j.IsPrivate() {
+		return nil, fmt.Errorf("JWK %s has no private key", j.KeyID)
+	}
+	publicKey, err := j.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		d, err := decodeBigInt("d", j.D)
+		if err != nil {
+			return nil, err
+		}
+		p, err := decodeBigInt("p", j.P)
+		if err != nil {
+			return nil, err
+		}
+		q, err := decodeBigInt("q", j.Q)
+		if err != nil {
+			return nil, err
+		}
+		key := &rsa.PrivateKey{PublicKey: *k, D: d, Primes: []*big.Int{p, q}}
+		if err := key.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid RSA private key: %w", err)
+		}
+		key.Precompute()
+		return key, nil
+	case *ecdsa.PublicKey:
+		d, err := base64URL.DecodeString(j.D)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK member d")
+		}
+		key, err := ecdsa.ParseRawPrivateKey(k.Curve, d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECDSA private key: %w", err)
+		}
+		if !key.PublicKey.Equal(k) {
+			return nil, errors.New("ECDSA private key does not match its public key")
+		}
+		return key, nil
+	default:
+		seed, err := base64URL.DecodeString(j.D)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid JWK member d")
+		}
+		key := ed25519.NewKeyFromSeed(seed)
+		if !key.Public().(ed25519.PublicKey).Equal(publicKey) {
+			return nil, errors.New("Ed25519 private key does not match its public key")
+		}
+		return key, nil
+	}
+}
+
+// Thumbprint returns the SHA-256 thumbprint of the key (RFC 7638) in
+// base64url, which is commonly used as the ID of the key
+func (j *JWK) Thumbprint() (string, error) {
+	// the required members in lexicographic order without whitespace
+	var members any
+	switch j.KeyType {
+	case jwkKeyTypeRSA:
+		members = struct {
+			E       string `json:"e"`
+			KeyType string `json:"kty"`
+			N       string `json:"n"`
+		}{j.E, j.KeyType, j.N}
+	case jwkKeyTypeEC:
+		members = struct {
+			Curve   string `json:"crv"`
+			KeyType string `json:"kty"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		}{j.Curve, j.KeyType, j.X, j.Y}
+	case jwkKeyTypeOKP:
+		members = struct {
+			Curve   string `json:"crv"`
+			KeyType string `json:"kty"`
+			X       string `json:"x"`
+		}{j.Curve, j.KeyType, j.X}
+	default:
+		return "", fmt.Errorf("%w: JWK key type %q", ErrUnsupportedKey, j.KeyType)
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal JWK members: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	return base64URL.EncodeToString(hash[:]), nil
+}
+
+// NewJWKS returns a JWKS of the public keys of keys; see NewJWK for the
+// supported keys
+func NewJWKS(keys ...any) (*JWKS, error) {
+	jwks := &JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk, err := NewJWK(key, WithJWKUse(JWKUseSignature))
+		if err != nil {
+			return nil, err
+		}
+		jwks.Keys = append(jwks.Keys, *jwk.Public())
+	}
+	return jwks, nil
+}
+
+// Find returns the JWK of the specified ID
+func (s *JWKS) Find(keyID string) (*JWK, bool) {
+	for i := range s.Keys {
+		if s.Keys[i].KeyID == keyID {
+			return &s.Keys[i], true
+		}
+	}
+	return nil, false
+}
+
+// GetPublicKey returns the public key of the specified ID, such as the key
+// ID in the header of a JWS (see ParseJWSHeader)
+func (s *JWKS) GetPublicKey(keyID string) (crypto.PublicKey, error) {
+	jwk, ok := s.Find(keyID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	return jwk.PublicKey()
+}
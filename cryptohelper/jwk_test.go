@@ -0,0 +1,306 @@
+package cryptohelper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+type equalKey interface {
+	Equal(crypto.PrivateKey) bool
+}
+
+func TestNewJWK(t *testing.T) {
+	keys := generateTestKeys(t)
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		key       crypto.Signer
+		keyType   string
+		curve     string
+		coordSize int
+	}{
+		{"RSA", keys.rsa, "RSA", "", 0},
+		{"ECDSA P-256", keys.ecdsa, "EC", "P-256", 32},
+		{"ECDSA P-521", p521Key, "EC", "P-521", 66},
+		{"Ed25519", keys.ed25519, "OKP", "Ed25519", 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publicJWK, err := NewJWK(tt.key.Public())
+			if err != nil {
+				t.Fatalf("NewJWK() error: %v", err)
+			}
+			if publicJWK.KeyType != tt.keyType || publicJWK.Curve != tt.curve || publicJWK.IsPrivate() {
+				t.Errorf("NewJWK() = %+v", publicJWK)
+			}
+			if tt.coordSize > 0 {
+				x, _ := base64URL.DecodeString(publicJWK.X)
+				if len(x) != tt.coordSize {
+					t.Errorf("NewJWK() x has %d bytes, want %d", len(x), tt.coordSize)
+				}
+			}
+			publicKey, err := publicJWK.PublicKey()
+			if err != nil {
+				t.Fatalf("PublicKey() error: %v", err)
+			}
+			if !publicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(tt.key.Public()) {
+				t.Error("PublicKey() returned different key")
+			}
+			if _, err := publicJWK.PrivateKey(); err == nil {
+				t.Error("PrivateKey() of public JWK should return error")
+			}
+
+			privateJWK, err := NewJWK(tt.key)
+			if err != nil {
+				t.Fatalf("NewJWK() error: %v", err)
+			}
+			if !privateJWK.IsPrivate() || privateJWK.KeyID != publicJWK.KeyID {
+				t.Errorf("NewJWK() of private key = %+v", privateJWK)
+			}
+			if *privateJWK.Public() != *publicJWK {
+				t.Errorf("Public() = %+v, want %+v", privateJWK.Public(), publicJWK)
+			}
+
+			data, err := json.Marshal(privateJWK)
+			if err != nil {
+				t.Fatalf("json.Marshal() error: %v", err)
+			}
+			var decoded JWK
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("json.Unmarshal() error: %v", err)
+			}
+			privateKey, err := decoded.PrivateKey()
+			if err != nil {
+				t.Fatalf("PrivateKey() error: %v", err)
+			}
+			if !privateKey.(equalKey).Equal(tt.key) {
+				t.Error("PrivateKey() returned different key")
+			}
+		})
+	}
+}
+
+func TestNewJWKOptions(t *testing.T) {
+	keys := generateTestKeys(t)
+
+	jwk, err := NewJWK(keys.ed25519.Public(), WithJWKKeyID("key-1"), WithJWKUse(JWKUseSignature), WithJWKAlgorithm(AlgorithmEdDSA))
+	if err != nil {
+		t.Fatalf("NewJWK() error: %v", err)
+	}
+
+	data, _ := json.Marshal(jwk)
+	expected := `{"kty":"OKP","kid":"key-1","use":"sig","alg":"EdDSA","crv":"Ed25519","x":"` + jwk.X + `"}`
+	if string(data) != expected {
+		t.Errorf("json.Marshal() = %s, want %s", data, expected)
+	}
+}
+
+func TestNewJWKDoesNotModifyRSAKey(t *testing.T) {
+	keys := generateTestKeys(t)
+	key := &rsa.PrivateKey{PublicKey: keys.rsa.PublicKey, D: keys.rsa.D, Primes: keys.rsa.Primes}
+
+	jwk, err := NewJWK(key)
+	if err != nil {
+		t.Fatalf("NewJWK() error: %v", err)
+	}
+	if key.Precomputed.Dp != nil || key.Precomputed.Dq != nil || key.Precomputed.Qinv != nil {
+		t.Error("NewJWK() should not precompute values of the key")
+	}
+	expected := map[string]*big.Int{
+		"dp": keys.rsa.Precomputed.Dp,
+		"dq": keys.rsa.Precomputed.Dq,
+		"qi": keys.rsa.Precomputed.Qinv,
+	}
+	for name, actual := range map[string]string{"dp": jwk.DP, "dq": jwk.DQ, "qi": jwk.QI} {
+		if actual != encodeBigInt(expected[name]) {
+			t.Errorf("%s = %s, want %s", name, actual, encodeBigInt(expected[name]))
+		}
+	}
+}
+
+func TestNewJWKUnsupported(t *testing.T) {
+	p224Key, _ := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+
+	for _, key := range []any{"key", p224Key, p224Key.Public()} {
+		if _, err := NewJWK(key); !errors.Is(err, ErrUnsupportedKey) {
+			t.Errorf("NewJWK(%T) error = %v, want %v", key, err, ErrUnsupportedKey)
+		}
+	}
+}
+
+func TestJWKThumbprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		jwk      JWK
+		expected string
+	}{
+		{
+			// example in section 3.1 of RFC 7638
+			name: "RSA",
+			jwk: JWK{
+				KeyType: "RSA",
+				N:       "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+				E:       "AQAB",
+				KeyID:   "2011-04-29",
+				Use:     "sig",
+			},
+			expected: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		},
+		{
+			// example in appendix A.3 of RFC 8037
+			name: "Ed25519",
+			jwk: JWK{
+				KeyType: "OKP",
+				Curve:   "Ed25519",
+				X:       "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+			},
+			expected: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumbprint, err := tt.jwk.Thumbprint()
+			if err != nil {
+				t.Fatalf("Thumbprint() error: %v", err)
+			}
+			if thumbprint != tt.expected {
+				t.Errorf("Thumbprint() = %s, want %s", thumbprint, tt.expected)
+			}
+		})
+	}
+
+	if _, err := (&JWK{KeyType: "oct"}).Thumbprint(); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("Thumbprint() error = %v, want %v", err, ErrUnsupportedKey)
+	}
+}
+
+func TestJWKPublicKeyErrors(t *testing.T) {
+	keys := generateTestKeys(t)
+	ecJWK, _ := NewJWK(keys.ecdsa.Public())
+	otherKeys := generateTestKeys(t)
+	otherECJWK, _ := NewJWK(otherKeys.ecdsa.Public())
+
+	tests := []struct {
+		name string
+		jwk  JWK
+	}{
+		{"unsupported key type", JWK{KeyType: "oct"}},
+		{"RSA without n", JWK{KeyType: "RSA", E: "AQAB"}},
+		{"RSA with invalid e", JWK{KeyType: "RSA", N: "AQAB", E: "AQ"}},
+		{"unsupported curve", JWK{KeyType: "EC", Curve: "secp256k1", X: ecJWK.X, Y: ecJWK.Y}},
+		{"point not on curve", JWK{KeyType: "EC", Curve: "P-256", X: ecJWK.X, Y: otherECJWK.Y}},
+		{"short x", JWK{KeyType: "EC", Curve: "P-256", X: "AQAB", Y: ecJWK.Y}},
+		{"unsupported OKP curve", JWK{KeyType: "OKP", Curve: "X25519", X: ecJWK.X}},
+		{"invalid Ed25519 key", JWK{KeyType: "OKP", Curve: "Ed25519", X: "AQAB"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.jwk.PublicKey(); err == nil {
+				t.Error("PublicKey() should return error")
+			}
+		})
+	}
+}
+
+func TestJWKPrivateKeyMismatch(t *testing.T) {
+	keys := generateTestKeys(t)
+	otherKeys := generateTestKeys(t)
+
+	for _, pair := range [][2]crypto.Signer{{keys.ecdsa, otherKeys.ecdsa}, {keys.ed25519, otherKeys.ed25519}, {keys.rsa, otherKeys.rsa}} {
+		jwk, _ := NewJWK(pair[0])
+		other, _ := NewJWK(pair[1])
+		jwk.D = other.D
+		jwk.P = other.P
+		jwk.Q = other.Q
+		if _, err := jwk.PrivateKey(); err == nil {
+			t.Errorf("PrivateKey() of %s with mismatched private key should return error", jwk.KeyType)
+		}
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	keys := generateTestKeys(t)
+
+	jwks, err := NewJWKS(keys.rsa.Public(), keys.ecdsa, keys.ed25519.Public())
+	if err != nil {
+		t.Fatalf("NewJWKS() error: %v", err)
+	}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if strings.Contains(string(data), `"d"`) {
+		t.Errorf("JWKS should not contain private keys but got %s", data)
+	}
+
+	var published JWKS
+	if err := json.Unmarshal(data, &published); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if len(published.Keys) != 3 || published.Keys[1].Use != JWKUseSignature {
+		t.Fatalf("json.Unmarshal() = %+v", published)
+	}
+
+	// a token is verified with the key of the ID in its header
+	ecJWK, _ := NewJWK(keys.ecdsa)
+	token, err := SignJWT(Claims{Subject: "alex"}, keys.ecdsa, AlgorithmES256, WithKeyID(ecJWK.KeyID))
+	if err != nil {
+		t.Fatalf("SignJWT() error: %v", err)
+	}
+	header, _ := ParseJWSHeader(token)
+	publicKey, err := published.GetPublicKey(header.KeyID)
+	if err != nil {
+		t.Fatalf("GetPublicKey() error: %v", err)
+	}
+	if err := VerifyJWT(token, publicKey, nil); err != nil {
+		t.Errorf("VerifyJWT() error: %v", err)
+	}
+
+	if jwk, ok := published.Find(ecJWK.KeyID); !ok || jwk.KeyType != "EC" {
+		t.Errorf("Find() = %v, %v", jwk, ok)
+	}
+	if _, ok := published.Find("unknown"); ok {
+		t.Error("Find() of unknown key ID should return false")
+	}
+	if _, err := published.GetPublicKey("unknown"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetPublicKey() error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestNewJWKSUnsupported(t *testing.T) {
+	if _, err := NewJWKS("key"); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("NewJWKS() error = %v, want %v", err, ErrUnsupportedKey)
+	}
+}
+
+func BenchmarkNewJWK(b *testing.B) {
+	keys := generateTestKeys(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = NewJWK(keys.rsa.Public())
+	}
+}
+
+func BenchmarkJWKPublicKey(b *testing.B) {
+	keys := generateTestKeys(b)
+	jwk, _ := NewJWK(keys.ecdsa.Public())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = jwk.PublicKey()
+	}
+}
@@ -0,0 +1,304 @@
+package cryptohelper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// SigningAlgorithm is a JWS algorithm as defined in RFC 7518 and RFC 8037
+type SigningAlgorithm string
+
+const (
+	AlgorithmRS256 SigningAlgorithm = "RS256"
+	AlgorithmRS384 SigningAlgorithm = "RS384"
+	AlgorithmPS256 SigningAlgorithm = "PS256"
+	AlgorithmES256 SigningAlgorithm = "ES256"
+	AlgorithmES384 SigningAlgorithm = "ES384"
+	AlgorithmEdDSA SigningAlgorithm = "EdDSA"
+)
+
+var (
+	// ErrInvalidToken is returned when a token is not a well-formed compact
+	// JWS
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrInvalidSignature is returned when the signature of a token does not
+	// match its content and the key
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrUnsupportedAlgorithm is returned when an algorithm is not supported
+	// or it does not match the type of a key
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+)
+
+var base64URL = base64.RawURLEncoding
+
+// JWSHeader is the protected header of a JWS
+type JWSHeader struct {
+	Algorithm SigningAlgorithm `json:"alg"`
+	KeyID     string           `json:"kid,omitempty"`
+	Type      string           `json:"typ,omitempty"`
+}
+
+// jwsHeader is the header of a parsed JWS; crit lists extensions which must
+// be understood by the verifying party
+type jwsHeader struct {
+	JWSHeader
+	Critical []string `json:"crit"`
+}
+
+// JWSOptions configures the header written by SignJWS and SignJWT
+type JWSOptions struct {
+	keyID      string
+	headerType string
+}
+
+// JWSOption is a functional option for SignJWS and SignJWT
+type JWSOption func(*JWSOptions)
+
+// WithKeyID sets the ID of the signing key (kid) in the header so that the
+// verifying party can select the public key, such as from a JWKS
+func WithKeyID(keyID string) JWSOption {
+	return func(o *JWSOptions) {
+		o.keyID = keyID
+	}
+}
+
+// WithHeaderType sets the media type (typ) in the header, such as "JWT"
+func WithHeaderType(headerType string) JWSOption {
+	return func(o *JWSOptions) {
+		o.headerType = headerType
+	}
+}
+
+func defaultJWSOptions() *JWSOptions {
+	return &JWSOptions{}
+}
+
+// GetSigningAlgorithm returns the default algorithm for the public key;
+// RS256 for RSA, ES256 or ES384 for ECDSA of P-256 or P-384 and EdDSA for
+// Ed25519
+func GetSigningAlgorithm(publicKey crypto.PublicKey) (SigningAlgorithm, error) {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRS256, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return AlgorithmES256, nil
+		case elliptic.P384():
+			return AlgorithmES384, nil
+		}
+		return "", fmt.Errorf("%w: ECDSA curve %s", ErrUnsupportedAlgorithm, k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	default:
+		return "", fmt.Errorf("%w: key type %T", ErrUnsupportedAlgorithm, publicKey)
+	}
+}
+
+// SignJWS signs payload with key and returns it as a compact JWS
+// (header.payload.signature)
+func SignJWS(payload []byte, key crypto.Signer, algorithm SigningAlgorithm, opts ...JWSOption) (string, error) {
+	options := defaultJWSOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if err := checkAlgorithm(algorithm, key.Public()); err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(JWSHeader{
+		Algorithm: algorithm,
+		KeyID:     options.keyID,
+		Type:      options.headerType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal JWS header: %w", err)
+	}
+
+	signingInput := base64URL.EncodeToString(header) + "." + base64URL.EncodeToString(payload)
+	signature, err := sign([]byte(signingInput), key, algorithm)
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64URL.EncodeToString(signature), nil
+}
+
+// ParseJWSHeader returns the header of token without verifying its
+// signature; it is useful to select the public key by its ID before calling
+// VerifyJWS
+func ParseJWSHeader(token string) (*JWSHeader, error) {
+	header, _, _, err := splitJWS(token)
+	if err != nil {
+		return nil, err
+	}
+	return &header.JWSHeader, nil
+}
+
+// VerifyJWS verifies the signature of compact JWS token with publicKey and
+// returns its header and payload. The algorithm in the header must match the
+// type of publicKey so that a token cannot choose a weaker algorithm.
+func VerifyJWS(token string, publicKey crypto.PublicKey) (*JWSHeader, []byte, error) {
+	header, payload, signature, err := splitJWS(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkAlgorithm(header.Algorithm, publicKey); err != nil {
+		return nil, nil, err
+	}
+	// RFC 7515 section 4.1.11 requires tokens with critical extensions which
+	// are not understood to be rejected; an empty list is not allowed either
+	if header.Critical != nil {
+		return nil, nil, fmt.Errorf("%w: unsupported critical header parameters %v", ErrInvalidToken, header.Critical)
+	}
+
+	signingInput := token[:strings.LastIndex(token, ".")]
+	if err := verify([]byte(signingInput), signature, publicKey, header.Algorithm); err != nil {
+		return nil, nil, err
+	}
+	return &header.JWSHeader, payload, nil
+}
+
+func splitJWS(token string) (*jwsHeader, []byte, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, nil, fmt.Errorf("%w: expected 3 parts but got %d", ErrInvalidToken, len(parts))
+	}
+
+	headerBytes, err := base64URL.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: unable to decode header: %w", ErrInvalidToken, err)
+	}
+	var header jwsHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: unable to parse header: %w", ErrInvalidToken, err)
+	}
+	payload, err := base64URL.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: unable to decode payload: %w", ErrInvalidToken, err)
+	}
+	signature, err := base64URL.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: unable to decode signature: %w", ErrInvalidToken, err)
+	}
+	return &header, payload, signature, nil
+}
+
+func checkAlgorithm(algorithm SigningAlgorithm, publicKey crypto.PublicKey) error {
+	isValid := false
+	switch algorithm {
+	case AlgorithmRS256, AlgorithmRS384, AlgorithmPS256:
+		_, isValid = publicKey.(*rsa.PublicKey)
+	case AlgorithmES256:
+		k, ok := publicKey.(*ecdsa.PublicKey)
+		isValid = ok && k.Curve == elliptic.P256()
+	case AlgorithmES384:
+		k, ok := publicKey.(*ecdsa.PublicKey)
+		isValid = ok && k.Curve == elliptic.P384()
+	case AlgorithmEdDSA:
+		_, isValid = publicKey.(ed25519.PublicKey)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, algorithm)
+	}
+	if !isValid {
+		return fmt.Errorf("%w: %s cannot be used with key type %T", ErrUnsupportedAlgorithm, algorithm, publicKey)
+	}
+	return nil
+}
+
+func getHash(algorithm SigningAlgorithm) crypto.Hash {
+	switch algorithm {
+	case AlgorithmRS384, AlgorithmES384:
+		return crypto.SHA384
+	case AlgorithmEdDSA:
+		// Ed25519 hashes the message itself
+		return crypto.Hash(0)
+	default:
+		return crypto.SHA256
+	}
+}
+
+func digest(message []byte, hash crypto.Hash) []byte {
+	if hash == crypto.Hash(0) {
+		return message
+	}
+	h := hash.New()
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+// ecdsaSignature is the ASN.1 structure of a signature returned by
+// crypto.Signer of ECDSA
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+func sign(message []byte, key crypto.Signer, algorithm SigningAlgorithm) ([]byte, error) {
+	hash := getHash(algorithm)
+	var signerOpts crypto.SignerOpts = hash
+	if algorithm == AlgorithmPS256 {
+		signerOpts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	}
+
+	signature, err := key.Sign(rand.Reader, digest(message, hash), signerOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign with %s: %w", algorithm, err)
+	}
+	if _, ok := key.Public().(*ecdsa.PublicKey); !ok {
+		return signature, nil
+	}
+
+	// JWS uses the fixed-size concatenation of R and S instead of ASN.1
+	var s ecdsaSignature
+	if _, err := asn1.Unmarshal(signature, &s); err != nil {
+		return nil, fmt.Errorf("unable to parse ECDSA signature: %w", err)
+	}
+	size := (key.Public().(*ecdsa.PublicKey).Curve.Params().BitSize + 7) / 8
+	concatenated := make([]byte, 2*size)
+	s.R.FillBytes(concatenated[:size])
+	s.S.FillBytes(concatenated[size:])
+	return concatenated, nil
+}
+
+func verify(message []byte, signature []byte, publicKey crypto.PublicKey, algorithm SigningAlgorithm) error {
+	hash := getHash(algorithm)
+	hashed := digest(message, hash)
+
+	isValid := false
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		if algorithm == AlgorithmPS256 {
+			isValid = rsa.VerifyPSS(k, hash, hashed, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		} else {
+			isValid = rsa.VerifyPKCS1v15(k, hash, hashed, signature) == nil
+		}
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) == 2*size {
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			isValid = ecdsa.Verify(k, hashed, r, s)
+		}
+	case ed25519.PublicKey:
+		isValid = ed25519.Verify(k, hashed, signature)
+	}
+	if !isValid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package cryptohelper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSignJWSAndVerifyJWS(t *testing.T) {
+	keys := generateTestKeys(t)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %v", err)
+	}
+
+	tests := []struct {
+		algorithm     SigningAlgorithm
+		key           crypto.Signer
+		signatureSize int
+	}{
+		{AlgorithmRS256, keys.rsa, 256},
+		{AlgorithmRS384, keys.rsa, 256},
+		{AlgorithmPS256, keys.rsa, 256},
+		{AlgorithmES256, keys.ecdsa, 64},
+		{AlgorithmES384, p384Key, 96},
+		{AlgorithmEdDSA, keys.ed25519, 64},
+	}
+
+	payload := []byte(`{"sub":"alex"}`)
+	for _, tt := range tests {
+		t.Run(string(tt.algorithm), func(t *testing.T) {
+			token, err := SignJWS(payload, tt.key, tt.algorithm, WithKeyID("key-1"))
+			if err != nil {
+				t.Fatalf("SignJWS() error: %v", err)
+			}
+
+			parts := strings.Split(token, ".")
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			if len(signature) != tt.signatureSize {
+				t.Errorf("SignJWS() signature size = %d, want %d", len(signature), tt.signatureSize)
+			}
+
+			header, verified, err := VerifyJWS(token, tt.key.Public())
+			if err != nil {
+				t.Fatalf("VerifyJWS() error: %v", err)
+			}
+			if string(verified) != string(payload) {
+				t.Errorf("VerifyJWS() payload = %s, want %s", verified, payload)
+			}
+			if header.Algorithm != tt.algorithm || header.KeyID != "key-1" || header.Type != "" {
+				t.Errorf("VerifyJWS() header = %+v", header)
+			}
+		})
+	}
+}
+
+func TestVerifyJWSRFC8037Example(t *testing.T) {
+	// example in appendix A.4 of RFC 8037
+	d, _ := base64.RawURLEncoding.DecodeString("nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A")
+	key := ed25519.NewKeyFromSeed(d)
+	expected := "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc.hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+
+	token, err := SignJWS([]byte("Example of Ed25519 signing"), key, AlgorithmEdDSA)
+	if err != nil {
+		t.Fatalf("SignJWS() error: %v", err)
+	}
+	if token != expected {
+		t.Errorf("SignJWS() = %s, want %s", token, expected)
+	}
+	if _, _, err := VerifyJWS(expected, key.Public()); err != nil {
+		t.Errorf("VerifyJWS() error: %v", err)
+	}
+}
+
+func TestVerifyJWSErrors(t *testing.T) {
+	keys := generateTestKeys(t)
+	otherKeys := generateTestKeys(t)
+
+	token, err := SignJWS([]byte("payload"), keys.ecdsa, AlgorithmES256)
+	if err != nil {
+		t.Fatalf("SignJWS() error: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	tamperedPayload := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte("tampered")) + "." + parts[2]
+	noneAlgorithm := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+	hmacAlgorithm := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256"}`)) + "." + parts[1] + "." + parts[2]
+	critical := signTestJWS(t, `{"alg":"ES256","crit":["exp"],"exp":1700000000}`, keys.ecdsa)
+	emptyCritical := signTestJWS(t, `{"alg":"ES256","crit":[]}`, keys.ecdsa)
+
+	tests := []struct {
+		name      string
+		token     string
+		publicKey crypto.PublicKey
+		expected  error
+	}{
+		{"tampered payload", tamperedPayload, keys.ecdsa.Public(), ErrInvalidSignature},
+		{"another key", token, otherKeys.ecdsa.Public(), ErrInvalidSignature},
+		{"key of another type", token, keys.rsa.Public(), ErrUnsupportedAlgorithm},
+		{"none algorithm", noneAlgorithm, keys.ecdsa.Public(), ErrUnsupportedAlgorithm},
+		{"HMAC algorithm", hmacAlgorithm, keys.ecdsa.Public(), ErrUnsupportedAlgorithm},
+		{"two parts", parts[0] + "." + parts[1], keys.ecdsa.Public(), ErrInvalidToken},
+		{"invalid base64", "!." + parts[1] + "." + parts[2], keys.ecdsa.Public(), ErrInvalidToken},
+		{"invalid header", base64.RawURLEncoding.EncodeToString([]byte("[]")) + "." + parts[1] + "." + parts[2], keys.ecdsa.Public(), ErrInvalidToken},
+		{"empty", "", keys.ecdsa.Public(), ErrInvalidToken},
+		{"critical extension", critical, keys.ecdsa.Public(), ErrInvalidToken},
+		{"empty critical extensions", emptyCritical, keys.ecdsa.Public(), ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := VerifyJWS(tt.token, tt.publicKey)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("VerifyJWS() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+// signTestJWS signs payload with header as it is so that tokens with
+// headers not written by SignJWS can be tested
+func signTestJWS(t testing.TB, header string, key crypto.Signer) string {
+	t.Helper()
+	var algorithm JWSHeader
+	if err := json.Unmarshal([]byte(header), &algorithm); err != nil {
+		t.Fatalf("unable to parse header: %v", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte("payload"))
+	signature, err := sign([]byte(signingInput), key, algorithm.Algorithm)
+	if err != nil {
+		t.Fatalf("sign() error: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestSignJWSWithMismatchedAlgorithm(t *testing.T) {
+	keys := generateTestKeys(t)
+
+	tests := []struct {
+		name      string
+		key       crypto.Signer
+		algorithm SigningAlgorithm
+	}{
+		{"RSA algorithm with ECDSA key", keys.ecdsa, AlgorithmRS256},
+		{"ES384 with P-256 key", keys.ecdsa, AlgorithmES384},
+		{"EdDSA with RSA key", keys.rsa, AlgorithmEdDSA},
+		{"unknown algorithm", keys.rsa, "HS256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SignJWS([]byte("payload"), tt.key, tt.algorithm)
+			if !errors.Is(err, ErrUnsupportedAlgorithm) {
+				t.Errorf("SignJWS() error = %v, want %v", err, ErrUnsupportedAlgorithm)
+			}
+		})
+	}
+}
+
+func TestParseJWSHeader(t *testing.T) {
+	keys := generateTestKeys(t)
+	token, err := SignJWS([]byte("payload"), keys.ed25519, AlgorithmEdDSA, WithKeyID("abc"), WithHeaderType("JWT"))
+	if err != nil {
+		t.Fatalf("SignJWS() error: %v", err)
+	}
+
+	header, err := ParseJWSHeader(token)
+	if err != nil {
+		t.Fatalf("ParseJWSHeader() error: %v", err)
+	}
+	expected := JWSHeader{Algorithm: AlgorithmEdDSA, KeyID: "abc", Type: "JWT"}
+	if *header != expected {
+		t.Errorf("ParseJWSHeader() = %+v, want %+v", *header, expected)
+	}
+}
+
+func TestGetSigningAlgorithm(t *testing.T) {
+	keys := generateTestKeys(t)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p521Key, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+
+	tests := []struct {
+		name      string
+		publicKey crypto.PublicKey
+		expected  SigningAlgorithm
+		wantErr   bool
+	}{
+		{"RSA", keys.rsa.Public(), AlgorithmRS256, false},
+		{"P-256", keys.ecdsa.Public(), AlgorithmES256, false},
+		{"P-384", p384Key.Public(), AlgorithmES384, false},
+		{"P-521", p521Key.Public(), "", true},
+		{"Ed25519", keys.ed25519.Public(), AlgorithmEdDSA, false},
+		{"unknown", "key", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm, err := GetSigningAlgorithm(tt.publicKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetSigningAlgorithm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if algorithm != tt.expected {
+				t.Errorf("GetSigningAlgorithm() = %q, want %q", algorithm, tt.expected)
+			}
+		})
+	}
+}
+
+func BenchmarkSignJWS(b *testing.B) {
+	keys := generateTestKeys(b)
+	payload := []byte(`{"sub":"alex"}`)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = SignJWS(payload, keys.ecdsa, AlgorithmES256)
+	}
+}
+
+func BenchmarkVerifyJWS(b *testing.B) {
+	keys := generateTestKeys(b)
+	token, _ := SignJWS([]byte(`{"sub":"alex"}`), keys.ecdsa, AlgorithmES256)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = VerifyJWS(token, keys.ecdsa.Public())
+	}
+}
@@ -0,0 +1,237 @@
+package cryptohelper
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const jwtHeaderType = "JWT"
+
+var (
+	// ErrTokenExpired is returned when the expiration time (exp) of a token
+	// has passed
+	ErrTokenExpired = errors.New("token is expired")
+
+	// ErrTokenNotYetValid is returned when the not-before time (nbf) of a
+	// token has not come yet
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+
+	// ErrTokenIssuedInFuture is returned when the issued-at time (iat) of a
+	// token is in the future
+	ErrTokenIssuedInFuture = errors.New("token is issued in the future")
+
+	// ErrTokenMissingExpiration is returned when expiration time (exp) is
+	// required but a token does not have one
+	ErrTokenMissingExpiration = errors.New("token has no expiration time")
+
+	// ErrInvalidIssuer is returned when the issuer (iss) of a token is not
+	// the expected one
+	ErrInvalidIssuer = errors.New("invalid issuer of token")
+
+	// ErrInvalidAudience is returned when the audience (aud) of a token does
+	// not contain the expected one
+	ErrInvalidAudience = errors.New("invalid audience of token")
+)
+
+// NumericDate is a JWT time which is encoded as the number of seconds since
+// the Unix epoch
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns t as NumericDate truncated to seconds
+func NewNumericDate(t time.Time) NumericDate {
+	return NumericDate{t.Truncate(time.Second)}
+}
+
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(d.Unix(), 10)), nil
+}
+
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	// fractions of seconds are allowed by RFC 7519
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("unable to parse numeric date %s: %w", data, err)
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return fmt.Errorf("unable to parse numeric date %s: %w", data, err)
+	}
+	seconds := int64(f)
+	d.Time = time.Unix(seconds, int64((f-float64(seconds))*float64(time.Second)))
+	return nil
+}
+
+// Audience is the audience (aud) of a JWT; it is encoded as a string if it
+// has only one value and as an array otherwise
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	// null is an absent audience rather than an empty one
+	if string(data) == "null" {
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("unable to parse audience %s: %w", data, err)
+	}
+	*a = multiple
+	return nil
+}
+
+// Claims are the registered claims of a JWT as defined in RFC 7519. It can
+// be embedded in a struct with private claims, such as
+//
+//	type UserClaims struct {
+//		cryptohelper.Claims
+//		Email string `json:"email"`
+//	}
+type Claims struct {
+	Issuer    string      `json:"iss,omitempty"`
+	Subject   string      `json:"sub,omitempty"`
+	Audience  Audience    `json:"aud,omitempty"`
+	ExpiresAt NumericDate `json:"exp,omitzero"`
+	NotBefore NumericDate `json:"nbf,omitzero"`
+	IssuedAt  NumericDate `json:"iat,omitzero"`
+	ID        string      `json:"jti,omitempty"`
+}
+
+// JWTOptions configures the validation of registered claims by VerifyJWT
+type JWTOptions struct {
+	leeway             time.Duration
+	issuer             string
+	audience           string
+	requiresExpiration bool
+	now                func() time.Time
+}
+
+// JWTOption is a functional option for VerifyJWT
+type JWTOption func(*JWTOptions)
+
+// WithLeeway allows the specified difference of clocks between the issuer
+// and the verifier in validation of exp, nbf and iat
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(o *JWTOptions) {
+		o.leeway = leeway
+	}
+}
+
+// WithIssuer requires the issuer (iss) of a token to be issuer
+func WithIssuer(issuer string) JWTOption {
+	return func(o *JWTOptions) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience requires the audience (aud) of a token to contain audience
+func WithAudience(audience string) JWTOption {
+	return func(o *JWTOptions) {
+		o.audience = audience
+	}
+}
+
+// WithExpirationRequired rejects tokens without expiration time (exp)
+func WithExpirationRequired() JWTOption {
+	return func(o *JWTOptions) {
+		o.requiresExpiration = true
+	}
+}
+
+// WithTimeFunc sets the function returning the current time; it defaults to
+// time.Now
+func WithTimeFunc(now func() time.Time) JWTOption {
+	return func(o *JWTOptions) {
+		o.now = now
+	}
+}
+
+func defaultJWTOptions() *JWTOptions {
+	return &JWTOptions{
+		now: time.Now,
+	}
+}
+
+// SignJWT signs claims, which are usually Claims or a struct embedding
+// Claims, with key and returns it as a compact JWS
+func SignJWT(claims any, key crypto.Signer, algorithm SigningAlgorithm, opts ...JWSOption) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal claims: %w", err)
+	}
+	return SignJWS(payload, key, algorithm, append([]JWSOption{WithHeaderType(jwtHeaderType)}, opts...)...)
+}
+
+// VerifyJWT verifies the signature of token with publicKey, validates its
+// registered claims and decodes its claims into claims, which can be nil or
+// a pointer to Claims or a struct embedding Claims. Time based claims are
+// validated only if they are present.
+func VerifyJWT(token string, publicKey crypto.PublicKey, claims any, opts ...JWTOption) error {
+	options := defaultJWTOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	_, payload, err := VerifyJWS(token, publicKey)
+	if err != nil {
+		return err
+	}
+
+	var registered Claims
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return fmt.Errorf("%w: unable to parse claims: %w", ErrInvalidToken, err)
+	}
+	if err := registered.validate(options); err != nil {
+		return err
+	}
+
+	if claims == nil {
+		return nil
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return fmt.Errorf("unable to decode claims: %w", err)
+	}
+	return nil
+}
+
+func (c Claims) validate(options *JWTOptions) error {
+	now := options.now()
+
+	if c.ExpiresAt.IsZero() {
+		if options.requiresExpiration {
+			return ErrTokenMissingExpiration
+		}
+	} else if !now.Before(c.ExpiresAt.Add(options.leeway)) {
+		return fmt.Errorf("%w at %s", ErrTokenExpired, c.ExpiresAt.Format(time.RFC3339))
+	}
+	if !c.NotBefore.IsZero() && now.Add(options.leeway).Before(c.NotBefore.Time) {
+		return fmt.Errorf("%w until %s", ErrTokenNotYetValid, c.NotBefore.Format(time.RFC3339))
+	}
+	if !c.IssuedAt.IsZero() && now.Add(options.leeway).Before(c.IssuedAt.Time) {
+		return fmt.Errorf("%w at %s", ErrTokenIssuedInFuture, c.IssuedAt.Format(time.RFC3339))
+	}
+	if options.issuer != "" && c.Issuer != options.issuer {
+		return fmt.Errorf("%w: %q", ErrInvalidIssuer, c.Issuer)
+	}
+	if options.audience != "" && !slices.Contains(c.Audience, options.audience) {
+		return fmt.Errorf("%w: %q", ErrInvalidAudience, c.Audience)
+	}
+	return nil
+}
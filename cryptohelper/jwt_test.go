@@ -0,0 +1,218 @@
+package cryptohelper
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testUserClaims struct {
+	Claims
+	Email string   `json:"email"`
+	Roles []string `json:"roles"`
+}
+
+func TestSignJWTAndVerifyJWT(t *testing.T) {
+	keys := generateTestKeys(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	claims := testUserClaims{
+		Claims: Claims{
+			Issuer:    "https://auth.example.com",
+			Subject:   "alex",
+			Audience:  Audience{"api", "web"},
+			ExpiresAt: NewNumericDate(now.Add(time.Hour)),
+			NotBefore: NewNumericDate(now),
+			IssuedAt:  NewNumericDate(now),
+			ID:        "token-1",
+		},
+		Email: "alex@example.com",
+		Roles: []string{"admin"},
+	}
+
+	token, err := SignJWT(claims, keys.ecdsa, AlgorithmES256, WithKeyID("key-1"))
+	if err != nil {
+		t.Fatalf("SignJWT() error: %v", err)
+	}
+
+	header, err := ParseJWSHeader(token)
+	if err != nil {
+		t.Fatalf("ParseJWSHeader() error: %v", err)
+	}
+	if header.Type != "JWT" || header.KeyID != "key-1" {
+		t.Errorf("SignJWT() header = %+v", header)
+	}
+
+	var decoded testUserClaims
+	err = VerifyJWT(token, keys.ecdsa.Public(), &decoded,
+		WithTimeFunc(func() time.Time { return now.Add(time.Minute) }),
+		WithIssuer("https://auth.example.com"),
+		WithAudience("api"),
+		WithExpirationRequired(),
+	)
+	if err != nil {
+		t.Fatalf("VerifyJWT() error: %v", err)
+	}
+	if decoded.Email != claims.Email || decoded.Subject != claims.Subject || decoded.ID != claims.ID || len(decoded.Roles) != 1 {
+		t.Errorf("VerifyJWT() claims = %+v, want %+v", decoded, claims)
+	}
+	if !decoded.ExpiresAt.Equal(claims.ExpiresAt.Time) || !decoded.IssuedAt.Equal(now) {
+		t.Errorf("VerifyJWT() times = %v, %v", decoded.ExpiresAt, decoded.IssuedAt)
+	}
+}
+
+func TestVerifyJWTClaims(t *testing.T) {
+	keys := generateTestKeys(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := WithTimeFunc(func() time.Time { return now })
+
+	tests := []struct {
+		name     string
+		claims   Claims
+		opts     []JWTOption
+		expected error
+	}{
+		{"no claims", Claims{}, nil, nil},
+		{"valid", Claims{ExpiresAt: NewNumericDate(now.Add(time.Second)), NotBefore: NewNumericDate(now), IssuedAt: NewNumericDate(now)}, nil, nil},
+		{"expired", Claims{ExpiresAt: NewNumericDate(now)}, nil, ErrTokenExpired},
+		{"expired within leeway", Claims{ExpiresAt: NewNumericDate(now.Add(-30 * time.Second))}, []JWTOption{WithLeeway(time.Minute)}, nil},
+		{"expired beyond leeway", Claims{ExpiresAt: NewNumericDate(now.Add(-2 * time.Minute))}, []JWTOption{WithLeeway(time.Minute)}, ErrTokenExpired},
+		{"not yet valid", Claims{NotBefore: NewNumericDate(now.Add(time.Second))}, nil, ErrTokenNotYetValid},
+		{"not yet valid within leeway", Claims{NotBefore: NewNumericDate(now.Add(30 * time.Second))}, []JWTOption{WithLeeway(time.Minute)}, nil},
+		{"issued in future", Claims{IssuedAt: NewNumericDate(now.Add(time.Minute))}, nil, ErrTokenIssuedInFuture},
+		{"issued in future within leeway", Claims{IssuedAt: NewNumericDate(now.Add(time.Minute))}, []JWTOption{WithLeeway(time.Minute)}, nil},
+		{"missing expiration", Claims{}, []JWTOption{WithExpirationRequired()}, ErrTokenMissingExpiration},
+		{"issuer", Claims{Issuer: "a"}, []JWTOption{WithIssuer("a")}, nil},
+		{"invalid issuer", Claims{Issuer: "b"}, []JWTOption{WithIssuer("a")}, ErrInvalidIssuer},
+		{"missing issuer", Claims{}, []JWTOption{WithIssuer("a")}, ErrInvalidIssuer},
+		{"audience", Claims{Audience: Audience{"x", "y"}}, []JWTOption{WithAudience("y")}, nil},
+		{"invalid audience", Claims{Audience: Audience{"x"}}, []JWTOption{WithAudience("y")}, ErrInvalidAudience},
+		{"missing audience", Claims{}, []JWTOption{WithAudience("y")}, ErrInvalidAudience},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := SignJWT(tt.claims, keys.ed25519, AlgorithmEdDSA)
+			if err != nil {
+				t.Fatalf("SignJWT() error: %v", err)
+			}
+			err = VerifyJWT(token, keys.ed25519.Public(), nil, append([]JWTOption{clock}, tt.opts...)...)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("VerifyJWT() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVerifyJWTNullAudience(t *testing.T) {
+	keys := generateTestKeys(t)
+	token, err := SignJWS([]byte(`{"aud":null}`), keys.ed25519, AlgorithmEdDSA)
+	if err != nil {
+		t.Fatalf("SignJWS() error: %v", err)
+	}
+
+	var claims Claims
+	if err := VerifyJWT(token, keys.ed25519.Public(), &claims); err != nil {
+		t.Fatalf("VerifyJWT() error: %v", err)
+	}
+	// null must not be decoded as an audience of an empty string
+	if claims.Audience != nil {
+		t.Errorf("Audience = %q, want nil", claims.Audience)
+	}
+	if err := VerifyJWT(token, keys.ed25519.Public(), nil, WithAudience("api")); !errors.Is(err, ErrInvalidAudience) {
+		t.Errorf("VerifyJWT() error = %v, want %v", err, ErrInvalidAudience)
+	}
+}
+
+func TestVerifyJWTInvalidSignature(t *testing.T) {
+	keys := generateTestKeys(t)
+	otherKeys := generateTestKeys(t)
+	token, err := SignJWT(Claims{Subject: "alex"}, keys.rsa, AlgorithmPS256)
+	if err != nil {
+		t.Fatalf("SignJWT() error: %v", err)
+	}
+
+	var claims Claims
+	err = VerifyJWT(token, otherKeys.rsa.Public(), &claims)
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyJWT() error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if claims.Subject != "" {
+		t.Error("VerifyJWT() should not decode claims of token with invalid signature")
+	}
+}
+
+func TestVerifyJWTInvalidClaims(t *testing.T) {
+	keys := generateTestKeys(t)
+	token, err := SignJWS([]byte(`{"exp":"tomorrow"}`), keys.ed25519, AlgorithmEdDSA)
+	if err != nil {
+		t.Fatalf("SignJWS() error: %v", err)
+	}
+
+	if err := VerifyJWT(token, keys.ed25519.Public(), nil); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("VerifyJWT() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestClaimsJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   Claims
+		expected string
+	}{
+		{"empty", Claims{}, `{}`},
+		{"single audience", Claims{Audience: Audience{"api"}}, `{"aud":"api"}`},
+		{"multiple audiences", Claims{Audience: Audience{"api", "web"}}, `{"aud":["api","web"]}`},
+		{"times", Claims{ExpiresAt: NewNumericDate(time.Unix(1700000000, 999))}, `{"exp":1700000000}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.claims)
+			if err != nil {
+				t.Fatalf("json.Marshal() error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("json.Marshal() = %s, want %s", data, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClaimsUnmarshalJSON(t *testing.T) {
+	var claims Claims
+	if err := json.Unmarshal([]byte(`{"aud":"api","exp":1700000000.5,"iat":1700000000}`), &claims); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != "api" {
+		t.Errorf("Audience = %v, want [api]", claims.Audience)
+	}
+	if !claims.ExpiresAt.Equal(time.Unix(1700000000, int64(500*time.Millisecond))) {
+		t.Errorf("ExpiresAt = %v", claims.ExpiresAt)
+	}
+	if claims.IssuedAt.Unix() != 1700000000 {
+		t.Errorf("IssuedAt = %v", claims.IssuedAt)
+	}
+
+	var withoutAudience Claims
+	if err := json.Unmarshal([]byte(`{"aud":null}`), &withoutAudience); err != nil {
+		t.Fatalf("json.Unmarshal() with null audience error: %v", err)
+	}
+	if withoutAudience.Audience != nil {
+		t.Errorf("Audience of null = %q, want nil", withoutAudience.Audience)
+	}
+
+	if err := json.Unmarshal([]byte(`{"aud":1}`), &claims); err == nil {
+		t.Error("json.Unmarshal() with invalid audience should return error")
+	}
+}
+
+func BenchmarkVerifyJWT(b *testing.B) {
+	keys := generateTestKeys(b)
+	token, _ := SignJWT(Claims{Subject: "alex", ExpiresAt: NewNumericDate(time.Now().Add(time.Hour))}, keys.ed25519, AlgorithmEdDSA)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var claims Claims
+		_ = VerifyJWT(token, keys.ed25519.Public(), &claims)
+	}
+}
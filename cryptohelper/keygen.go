@@ -0,0 +1,189 @@
+package cryptohelper
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/youmark/pkcs8"
+	"golang.org/x/crypto/ssh"
+)
+
+// KeyType is the algorithm and the size of a key to be generated
+type KeyType string
+
+const (
+	KeyTypeRSA2048   KeyType = "rsa-2048"
+	KeyTypeRSA3072   KeyType = "rsa-3072"
+	KeyTypeRSA4096   KeyType = "rsa-4096"
+	KeyTypeECDSAP256 KeyType = "ecdsa-p256"
+	KeyTypeECDSAP384 KeyType = "ecdsa-p384"
+	KeyTypeECDSAP521 KeyType = "ecdsa-p521"
+	KeyTypeEd25519   KeyType = "ed25519"
+)
+
+const (
+	pemTypePublicKey = "PUBLIC KEY"
+
+	// as recommended by OWASP for PBKDF2-HMAC-SHA256
+	defaultPBKDF2Iterations = 600000
+	defaultScryptCost       = 1 << 15
+	keyDerivationSaltSize   = 16
+)
+
+// GetKeyTypes returns all the supported key types
+func GetKeyTypes() []KeyType {
+	return []KeyType{
+		KeyTypeRSA2048,
+		KeyTypeRSA3072,
+		KeyTypeRSA4096,
+		KeyTypeECDSAP256,
+		KeyTypeECDSAP384,
+		KeyTypeECDSAP521,
+		KeyTypeEd25519,
+	}
+}
+
+// GenerateKey generates a private key of the specified type and returns it
+// as *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey
+func GenerateKey(keyType KeyType) (crypto.Signer, error) {
+	switch keyType {
+	case KeyTypeRSA2048:
+		return generateRSAKey(2048)
+	case KeyTypeRSA3072:
+		return generateRSAKey(3072)
+	case KeyTypeRSA4096:
+		return generateRSAKey(4096)
+	case KeyTypeECDSAP256:
+		return generateECDSAKey(elliptic.P256())
+	case KeyTypeECDSAP384:
+		return generateECDSAKey(elliptic.P384())
+	case KeyTypeECDSAP521:
+		return generateECDSAKey(elliptic.P521())
+	case KeyTypeEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate Ed25519 key: %w", err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%w: key type %s", ErrUnsupportedKey, keyType)
+	}
+}
+
+func generateRSAKey(bits int) (crypto.Signer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate RSA key: %w", err)
+	}
+	return key, nil
+}
+
+func generateECDSAKey(curve elliptic.Curve) (crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate ECDSA key: %w", err)
+	}
+	return key, nil
+}
+
+// PrivateKeyPEMOptions configures the key derivation of the encryption of
+// private keys by MarshalPrivateKeyPEM
+type PrivateKeyPEMOptions struct {
+	kdf pkcs8.KDFOpts
+}
+
+// PrivateKeyPEMOption is a functional option for MarshalPrivateKeyPEM
+type PrivateKeyPEMOption func(*PrivateKeyPEMOptions)
+
+// WithPBKDF2 derives the encryption key from passphrase with PBKDF2-HMAC-SHA256
+// of the specified number of iterations; this is the default with 600,000
+// iterations
+func WithPBKDF2(iterations int) PrivateKeyPEMOption {
+	return func(o *PrivateKeyPEMOptions) {
+		o.kdf = pkcs8.PBKDF2Opts{
+			SaltSize:       keyDerivationSaltSize,
+			IterationCount: iterations,
+			HMACHash:       crypto.SHA256,
+		}
+	}
+}
+
+// WithScrypt derives the encryption key from passphrase with scrypt of the
+// specified CPU/memory cost (N), which must be a power of 2 such as 32768
+func WithScrypt(cost int) PrivateKeyPEMOption {
+	return func(o *PrivateKeyPEMOptions) {
+		o.kdf = pkcs8.ScryptOpts{
+			SaltSize:                 keyDerivationSaltSize,
+			CostParameter:            cost,
+			BlockSize:                8,
+			ParallelizationParameter: 1,
+		}
+	}
+}
+
+func defaultPrivateKeyPEMOptions() *PrivateKeyPEMOptions {
+	o := &PrivateKeyPEMOptions{}
+	WithPBKDF2(defaultPBKDF2Iterations)(o)
+	return o
+}
+
+// MarshalPrivateKeyPEM returns key in PKCS#8 PEM. If passphrase is not empty,
+// the key is encrypted with AES-256-CBC with a key derived from passphrase
+// (PBES2) and it can be read by ParsePrivateKey and OpenSSL.
+func MarshalPrivateKeyPEM(key crypto.Signer, passphrase []byte, opts ...PrivateKeyPEMOption) ([]byte, error) {
+	options := defaultPrivateKeyPEMOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if len(passphrase) == 0 {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal private key: %w", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: pemTypePrivateKey, Bytes: der}), nil
+	}
+
+	der, err := pkcs8.MarshalPrivateKey(key, passphrase, &pkcs8.Opts{
+		Cipher:  pkcs8.AES256CBC,
+		KDFOpts: options.kdf,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeEncryptedPrivateKey, Bytes: der}), nil
+}
+
+// MarshalPublicKeyPEM returns the RSA, ECDSA or Ed25519 public key in PKIX
+// (SubjectPublicKeyInfo) PEM
+func MarshalPublicKeyPEM(publicKey crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: der}), nil
+}
+
+// MarshalAuthorizedKey returns the RSA, ECDSA or Ed25519 public key as a
+// line of OpenSSH authorized_keys file, such as "ssh-ed25519 AAAA... comment";
+// comment is optional
+func MarshalAuthorizedKey(publicKey crypto.PublicKey, comment string) ([]byte, error) {
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert public key to OpenSSH format: %w", err)
+	}
+
+	line := bytes.TrimSuffix(ssh.MarshalAuthorizedKey(sshPublicKey), []byte("\n"))
+	if comment != "" {
+		line = fmt.Appendf(line, " %s", comment)
+	}
+	return append(line, '\n'), nil
+}
@@ -0,0 +1,191 @@
+package cryptohelper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerateKey(t *testing.T) {
+	tests := []struct {
+		keyType KeyType
+		check   func(crypto.Signer) bool
+	}{
+		{KeyTypeRSA2048, func(k crypto.Signer) bool { return k.(*rsa.PrivateKey).N.BitLen() == 2048 }},
+		{KeyTypeRSA3072, func(k crypto.Signer) bool { return k.(*rsa.PrivateKey).N.BitLen() == 3072 }},
+		{KeyTypeRSA4096, func(k crypto.Signer) bool { return k.(*rsa.PrivateKey).N.BitLen() == 4096 }},
+		{KeyTypeECDSAP256, func(k crypto.Signer) bool { return k.(*ecdsa.PrivateKey).Curve.Params().Name == "P-256" }},
+		{KeyTypeECDSAP384, func(k crypto.Signer) bool { return k.(*ecdsa.PrivateKey).Curve.Params().Name == "P-384" }},
+		{KeyTypeECDSAP521, func(k crypto.Signer) bool { return k.(*ecdsa.PrivateKey).Curve.Params().Name == "P-521" }},
+		{KeyTypeEd25519, func(k crypto.Signer) bool { return len(k.(ed25519.PrivateKey)) == ed25519.PrivateKeySize }},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.keyType), func(t *testing.T) {
+			if tt.keyType == KeyTypeRSA4096 && testing.Short() {
+				t.Skip("skipping generation of 4096-bit RSA key in short mode")
+			}
+			key, err := GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("GenerateKey() error: %v", err)
+			}
+			if !tt.check(key) {
+				t.Errorf("GenerateKey() returned unexpected key %T", key)
+			}
+		})
+	}
+}
+
+func TestGenerateKeyUnsupported(t *testing.T) {
+	_, err := GenerateKey("dsa-1024")
+	if !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("GenerateKey() error = %v, want %v", err, ErrUnsupportedKey)
+	}
+}
+
+func TestGetKeyTypes(t *testing.T) {
+	if len(GetKeyTypes()) != 7 {
+		t.Errorf("GetKeyTypes() = %v, want 7 key types", GetKeyTypes())
+	}
+}
+
+func TestMarshalPrivateKeyPEM(t *testing.T) {
+	keys := generateTestKeys(t)
+
+	tests := []struct {
+		name         string
+		key          crypto.Signer
+		passphrase   string
+		opts         []PrivateKeyPEMOption
+		expectedType string
+	}{
+		{"RSA", keys.rsa, "", nil, "PRIVATE KEY"},
+		{"ECDSA", keys.ecdsa, "", nil, "PRIVATE KEY"},
+		{"Ed25519", keys.ed25519, "", nil, "PRIVATE KEY"},
+		{"encrypted with default PBKDF2", keys.ecdsa, testPassphrase, nil, "ENCRYPTED PRIVATE KEY"},
+		{"encrypted RSA with PBKDF2", keys.rsa, testPassphrase, []PrivateKeyPEMOption{WithPBKDF2(1000)}, "ENCRYPTED PRIVATE KEY"},
+		{"encrypted Ed25519 with scrypt", keys.ed25519, testPassphrase, []PrivateKeyPEMOption{WithScrypt(1 << 10)}, "ENCRYPTED PRIVATE KEY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pemBytes, err := MarshalPrivateKeyPEM(tt.key, []byte(tt.passphrase), tt.opts...)
+			if err != nil {
+				t.Fatalf("MarshalPrivateKeyPEM() error: %v", err)
+			}
+
+			block, _ := pem.Decode(pemBytes)
+			if block == nil || block.Type != tt.expectedType {
+				t.Fatalf("MarshalPrivateKeyPEM() returned PEM block %v, want type %s", block, tt.expectedType)
+			}
+
+			parsed, err := ParsePrivateKey(pemBytes, []byte(tt.passphrase))
+			if err != nil {
+				t.Fatalf("ParsePrivateKey() error: %v", err)
+			}
+			if !parsed.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(tt.key.Public()) {
+				t.Error("ParsePrivateKey() returned different key")
+			}
+
+			if tt.passphrase != "" {
+				if _, err := ParsePrivateKey(pemBytes, []byte("incorrect")); !errors.Is(err, ErrIncorrectPassphrase) {
+					t.Errorf("ParsePrivateKey() error = %v, want %v", err, ErrIncorrectPassphrase)
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalPublicKeyPEM(t *testing.T) {
+	keys := generateTestKeys(t)
+
+	for _, key := range []crypto.Signer{keys.rsa, keys.ecdsa, keys.ed25519} {
+		pemBytes, err := MarshalPublicKeyPEM(key.Public())
+		if err != nil {
+			t.Fatalf("MarshalPublicKeyPEM() error: %v", err)
+		}
+
+		block, _ := pem.Decode(pemBytes)
+		if block == nil || block.Type != "PUBLIC KEY" {
+			t.Fatalf("MarshalPublicKeyPEM() returned PEM block %v", block)
+		}
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			t.Fatalf("ParsePKIXPublicKey() error: %v", err)
+		}
+		if !publicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(key.Public()) {
+			t.Errorf("MarshalPublicKeyPEM() returned different key %T", publicKey)
+		}
+	}
+}
+
+func TestMarshalPublicKeyPEMUnsupported(t *testing.T) {
+	if _, err := MarshalPublicKeyPEM("not a key"); err == nil {
+		t.Error("MarshalPublicKeyPEM() with unsupported key should return error")
+	}
+}
+
+func TestMarshalAuthorizedKey(t *testing.T) {
+	keys := generateTestKeys(t)
+
+	tests := []struct {
+		name           string
+		key            crypto.Signer
+		comment        string
+		expectedPrefix string
+	}{
+		{"RSA", keys.rsa, "alex@example.com", "ssh-rsa "},
+		{"ECDSA", keys.ecdsa, "", "ecdsa-sha2-nistp256 "},
+		{"Ed25519", keys.ed25519, "deploy key", "ssh-ed25519 "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, err := MarshalAuthorizedKey(tt.key.Public(), tt.comment)
+			if err != nil {
+				t.Fatalf("MarshalAuthorizedKey() error: %v", err)
+			}
+			if !strings.HasPrefix(string(line), tt.expectedPrefix) || !strings.HasSuffix(string(line), "\n") || strings.Count(string(line), "\n") != 1 {
+				t.Errorf("MarshalAuthorizedKey() = %q, want a line starting with %q", line, tt.expectedPrefix)
+			}
+
+			publicKey, comment, _, rest, err := ssh.ParseAuthorizedKey(line)
+			if err != nil {
+				t.Fatalf("ParseAuthorizedKey() error: %v", err)
+			}
+			if comment != tt.comment || len(rest) != 0 {
+				t.Errorf("ParseAuthorizedKey() comment = %q, want %q", comment, tt.comment)
+			}
+			expected, _ := ssh.NewPublicKey(tt.key.Public())
+			if string(publicKey.Marshal()) != string(expected.Marshal()) {
+				t.Error("MarshalAuthorizedKey() returned different key")
+			}
+		})
+	}
+}
+
+func BenchmarkGenerateKey(b *testing.B) {
+	for _, keyType := range []KeyType{KeyTypeECDSAP256, KeyTypeEd25519} {
+		b.Run(string(keyType), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = GenerateKey(keyType)
+			}
+		})
+	}
+}
+
+func BenchmarkMarshalPrivateKeyPEM(b *testing.B) {
+	keys := generateTestKeys(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = MarshalPrivateKeyPEM(keys.ecdsa, []byte(testPassphrase), WithPBKDF2(1000))
+	}
+}
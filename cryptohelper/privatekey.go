@@ -0,0 +1,181 @@
+package cryptohelper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/youmark/pkcs8"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	pemTypeRSAPrivateKey       = "RSA PRIVATE KEY"
+	pemTypeECPrivateKey        = "EC PRIVATE KEY"
+	pemTypePrivateKey          = "PRIVATE KEY"
+	pemTypeEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
+	pemTypeOpenSSHPrivateKey   = "OPENSSH PRIVATE KEY"
+)
+
+var (
+	// ErrPassphraseRequired is returned when a private key is encrypted but
+	// no passphrase is specified
+	ErrPassphraseRequired = errors.New("passphrase is required to decrypt private key")
+
+	// ErrIncorrectPassphrase is returned when a private key cannot be
+	// decrypted with the specified passphrase
+	ErrIncorrectPassphrase = errors.New("incorrect passphrase of private key")
+
+	// ErrUnsupportedKey is returned when the format or the algorithm of a
+	// private key is not supported
+	ErrUnsupportedKey = errors.New("unsupported private key")
+)
+
+// ParsePrivateKey parses a PEM encoded RSA, ECDSA or Ed25519 private key in
+// one of the following formats and returns it as *rsa.PrivateKey,
+// *ecdsa.PrivateKey or ed25519.PrivateKey.
+//
+//   - PKCS#1 (RSA PRIVATE KEY)
+//   - SEC1 (EC PRIVATE KEY)
+//   - PKCS#8 (PRIVATE KEY)
+//   - PKCS#8 encrypted with PBES2 (ENCRYPTED PRIVATE KEY)
+//   - OpenSSH, with or without passphrase (OPENSSH PRIVATE KEY)
+//   - PKCS#1 or SEC1 in legacy encrypted PEM (with Proc-Type and DEK-Info
+//     headers)
+//
+// The passphrase is ignored if the key is not encrypted. The returned error
+// matches ErrPassphraseRequired, ErrIncorrectPassphrase or ErrUnsupportedKey
+// with errors.Is if it is caused by the passphrase or the key type.
+func ParsePrivateKey(pemBytes []byte, passphrase []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("unable to decode private key")
+	}
+
+	slog.Debug("Decoded private key",
+		slog.String("key_type", block.Type),
+	)
+
+	der := block.Bytes
+	// legacy encrypted PEM is insecure by design but still produced by tools
+	// such as older versions of OpenSSL
+	isLegacyEncrypted := x509.IsEncryptedPEMBlock(block)
+	if isLegacyEncrypted {
+		if len(passphrase) == 0 {
+			return nil, ErrPassphraseRequired
+		}
+		decrypted, err := x509.DecryptPEMBlock(block, passphrase)
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, ErrIncorrectPassphrase
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt private key: %w", err)
+		}
+		der = decrypted
+	}
+
+	key, err := parsePrivateKeyBlock(block.Type, der, pemBytes, passphrase)
+	if err != nil {
+		// legacy encryption does not always detect an incorrect passphrase
+		// and the decrypted bytes are not a valid key in that case
+		if isLegacyEncrypted && !errors.Is(err, ErrUnsupportedKey) {
+			return nil, ErrIncorrectPassphrase
+		}
+		return nil, err
+	}
+	return toSigner(key)
+}
+
+func parsePrivateKeyBlock(blockType string, der []byte, pemBytes []byte, passphrase []byte) (any, error) {
+	switch blockType {
+	case pemTypeRSAPrivateKey:
+		key, err := x509.ParsePKCS1PrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse PKCS#1 private key: %w", err)
+		}
+		return key, nil
+	case pemTypeECPrivateKey:
+		key, err := x509.ParseECPrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse SEC1 private key: %w", err)
+		}
+		return key, nil
+	case pemTypePrivateKey:
+		key, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse PKCS#8 private key: %w", err)
+		}
+		return key, nil
+	case pemTypeEncryptedPrivateKey:
+		if len(passphrase) == 0 {
+			return nil, ErrPassphraseRequired
+		}
+		key, err := pkcs8.ParsePKCS8PrivateKey(der, passphrase)
+		if isIncorrectPKCS8Passphrase(err) {
+			return nil, ErrIncorrectPassphrase
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt private key: %w", err)
+		}
+		return key, nil
+	case pemTypeOpenSSHPrivateKey:
+		return parseOpenSSHPrivateKey(pemBytes, passphrase)
+	default:
+		return nil, fmt.Errorf("%w: PEM block type %s", ErrUnsupportedKey, blockType)
+	}
+}
+
+func parseOpenSSHPrivateKey(pemBytes []byte, passphrase []byte) (any, error) {
+	key, err := ssh.ParseRawPrivateKey(pemBytes)
+	var missingError *ssh.PassphraseMissingError
+	if errors.As(err, &missingError) {
+		if len(passphrase) == 0 {
+			return nil, ErrPassphraseRequired
+		}
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(pemBytes, passphrase)
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, ErrIncorrectPassphrase
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse OpenSSH private key: %w", err)
+	}
+	return key, nil
+}
+
+// isIncorrectPKCS8Passphrase returns true if err is returned by pkcs8 due to
+// an incorrect passphrase; pkcs8 does not export its errors and an incorrect
+// passphrase is detected either on parsing the decrypted key (CBC) or on
+// authentication (GCM), so the messages are matched and they are pinned by
+// TestIsIncorrectPKCS8Passphrase against the version of pkcs8 in go.mod
+func isIncorrectPKCS8Passphrase(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch err.Error() {
+	case "pkcs8: incorrect password", "cipher: message authentication failed":
+		return true
+	}
+	return false
+}
+
+func toSigner(key any) (crypto.Signer, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ed25519.PrivateKey:
+		return *k, nil
+	default:
+		return nil, fmt.Errorf("%w: key type %T", ErrUnsupportedKey, key)
+	}
+}
@@ -0,0 +1,270 @@
+package cryptohelper
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/youmark/pkcs8"
+	"golang.org/x/crypto/ssh"
+)
+
+const testPassphrase = "testpassphrase"
+
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func generateTestKeys(t testing.TB) testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %v", err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	return testKeys{rsa: rsaKey, ecdsa: ecdsaKey, ed25519: ed25519Key}
+}
+
+func encodePEM(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+func marshalPKCS8PEM(t testing.TB, key any, passphrase string, opts *pkcs8.Opts) []byte {
+	t.Helper()
+	der, err := pkcs8.MarshalPrivateKey(key, []byte(passphrase), opts)
+	if err != nil {
+		t.Fatalf("Failed to marshal PKCS#8 private key: %v", err)
+	}
+	if passphrase == "" {
+		return encodePEM("PRIVATE KEY", der)
+	}
+	return encodePEM("ENCRYPTED PRIVATE KEY", der)
+}
+
+func marshalOpenSSHPEM(t testing.TB, key crypto.PrivateKey, passphrase string) []byte {
+	t.Helper()
+	var block *pem.Block
+	var err error
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(key, "test")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "test", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatalf("Failed to marshal OpenSSH private key: %v", err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+func marshalLegacyEncryptedPEM(t testing.TB, blockType string, der []byte, passphrase string) []byte {
+	t.Helper()
+	block, err := x509.EncryptPEMBlock(rand.Reader, blockType, der, []byte(passphrase), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatalf("Failed to encrypt PEM block: %v", err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+func marshalSEC1(t testing.TB, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal SEC1 private key: %v", err)
+	}
+	return der
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	keys := generateTestKeys(t)
+	scryptOpts := &pkcs8.Opts{
+		Cipher: pkcs8.AES256GCM,
+		KDFOpts: pkcs8.ScryptOpts{
+			CostParameter:            1 << 10,
+			BlockSize:                8,
+			ParallelizationParameter: 1,
+			SaltSize:                 16,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		pem        []byte
+		passphrase string
+		expected   crypto.Signer
+	}{
+		{"PKCS#1 RSA", encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(keys.rsa)), "", keys.rsa},
+		{"SEC1 ECDSA", encodePEM("EC PRIVATE KEY", marshalSEC1(t, keys.ecdsa)), "", keys.ecdsa},
+		{"PKCS#8 RSA", marshalPKCS8PEM(t, keys.rsa, "", nil), "", keys.rsa},
+		{"PKCS#8 ECDSA", marshalPKCS8PEM(t, keys.ecdsa, "", nil), "", keys.ecdsa},
+		{"PKCS#8 Ed25519", marshalPKCS8PEM(t, keys.ed25519, "", nil), "", keys.ed25519},
+		{"PKCS#8 with unused passphrase", marshalPKCS8PEM(t, keys.ecdsa, "", nil), testPassphrase, keys.ecdsa},
+		{"encrypted PKCS#8 RSA", marshalPKCS8PEM(t, keys.rsa, testPassphrase, nil), testPassphrase, keys.rsa},
+		{"encrypted PKCS#8 ECDSA", marshalPKCS8PEM(t, keys.ecdsa, testPassphrase, nil), testPassphrase, keys.ecdsa},
+		{"encrypted PKCS#8 Ed25519", marshalPKCS8PEM(t, keys.ed25519, testPassphrase, nil), testPassphrase, keys.ed25519},
+		{"encrypted PKCS#8 with scrypt and GCM", marshalPKCS8PEM(t, keys.ecdsa, testPassphrase, scryptOpts), testPassphrase, keys.ecdsa},
+		{"OpenSSH RSA", marshalOpenSSHPEM(t, keys.rsa, ""), "", keys.rsa},
+		{"OpenSSH ECDSA", marshalOpenSSHPEM(t, keys.ecdsa, ""), "", keys.ecdsa},
+		{"OpenSSH Ed25519", marshalOpenSSHPEM(t, keys.ed25519, ""), "", keys.ed25519},
+		{"encrypted OpenSSH Ed25519", marshalOpenSSHPEM(t, keys.ed25519, testPassphrase), testPassphrase, keys.ed25519},
+		{"encrypted OpenSSH ECDSA", marshalOpenSSHPEM(t, keys.ecdsa, testPassphrase), testPassphrase, keys.ecdsa},
+		{"legacy encrypted PKCS#1 RSA", marshalLegacyEncryptedPEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(keys.rsa), testPassphrase), testPassphrase, keys.rsa},
+		{"legacy encrypted SEC1 ECDSA", marshalLegacyEncryptedPEM(t, "EC PRIVATE KEY", marshalSEC1(t, keys.ecdsa), testPassphrase), testPassphrase, keys.ecdsa},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := ParsePrivateKey(tt.pem, []byte(tt.passphrase))
+			if err != nil {
+				t.Fatalf("ParsePrivateKey() error: %v", err)
+			}
+			type equaler interface {
+				Equal(crypto.PublicKey) bool
+			}
+			if !signer.Public().(equaler).Equal(tt.expected.Public()) {
+				t.Errorf("ParsePrivateKey() returned different key %T", signer)
+			}
+		})
+	}
+}
+
+func TestParsePrivateKeyErrors(t *testing.T) {
+	keys := generateTestKeys(t)
+	x25519Key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate X25519 key: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		pem        []byte
+		passphrase string
+		expected   error
+	}{
+		{"encrypted PKCS#8 without passphrase", marshalPKCS8PEM(t, keys.ecdsa, testPassphrase, nil), "", ErrPassphraseRequired},
+		{"encrypted OpenSSH without passphrase", marshalOpenSSHPEM(t, keys.ed25519, testPassphrase), "", ErrPassphraseRequired},
+		{"legacy encrypted PEM without passphrase", marshalLegacyEncryptedPEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(keys.rsa), testPassphrase), "", ErrPassphraseRequired},
+		{"encrypted PKCS#8 with incorrect passphrase", marshalPKCS8PEM(t, keys.ecdsa, testPassphrase, nil), "incorrect", ErrIncorrectPassphrase},
+		{"encrypted PKCS#8 with GCM and incorrect passphrase", marshalPKCS8PEM(t, keys.ecdsa, testPassphrase, &pkcs8.Opts{Cipher: pkcs8.AES256GCM, KDFOpts: pkcs8.DefaultOpts.KDFOpts}), "incorrect", ErrIncorrectPassphrase},
+		{"encrypted OpenSSH with incorrect passphrase", marshalOpenSSHPEM(t, keys.ed25519, testPassphrase), "incorrect", ErrIncorrectPassphrase},
+		{"legacy encrypted PEM with incorrect passphrase", marshalLegacyEncryptedPEM(t, "EC PRIVATE KEY", marshalSEC1(t, keys.ecdsa), testPassphrase), "incorrect", ErrIncorrectPassphrase},
+		{"certificate", encodePEM("CERTIFICATE", []byte{0x30}), "", ErrUnsupportedKey},
+		{"DSA key", encodePEM("DSA PRIVATE KEY", []byte{0x30}), "", ErrUnsupportedKey},
+		{"X25519 key", marshalPKCS8PEM(t, x25519Key, "", nil), "", ErrUnsupportedKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePrivateKey(tt.pem, []byte(tt.passphrase))
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("ParsePrivateKey() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+// TestIsIncorrectPKCS8Passphrase pins the errors of pkcs8 (and crypto/cipher
+// for GCM) of incorrect passphrases as they are matched by their messages
+func TestIsIncorrectPKCS8Passphrase(t *testing.T) {
+	keys := generateTestKeys(t)
+	ciphers := map[string]pkcs8.Cipher{
+		"AES-128-CBC": pkcs8.AES128CBC,
+		"AES-256-CBC": pkcs8.AES256CBC,
+		"3DES-CBC":    pkcs8.TripleDESCBC,
+		"AES-128-GCM": pkcs8.AES128GCM,
+		"AES-256-GCM": pkcs8.AES256GCM,
+	}
+
+	for name, cipher := range ciphers {
+		t.Run(name, func(t *testing.T) {
+			der, err := pkcs8.MarshalPrivateKey(keys.ecdsa, []byte(testPassphrase), &pkcs8.Opts{Cipher: cipher, KDFOpts: pkcs8.DefaultOpts.KDFOpts})
+			if err != nil {
+				t.Fatalf("Failed to marshal PKCS#8 private key: %v", err)
+			}
+			_, err = pkcs8.ParsePKCS8PrivateKey(der, []byte("incorrect"))
+			if err == nil {
+				t.Fatal("pkcs8.ParsePKCS8PrivateKey() with incorrect passphrase should return error")
+			}
+			if !isIncorrectPKCS8Passphrase(err) {
+				t.Errorf("error %q of pkcs8 is not detected as an incorrect passphrase", err)
+			}
+		})
+	}
+
+	for _, err := range []error{nil, errors.New("pkcs8: invalid PBES2 parameters"), errors.New("asn1: structure error")} {
+		if isIncorrectPKCS8Passphrase(err) {
+			t.Errorf("isIncorrectPKCS8Passphrase(%v) = true, want false", err)
+		}
+	}
+}
+
+func TestParsePrivateKeyInvalidInput(t *testing.T) {
+	tests := []struct {
+		name string
+		pem  []byte
+	}{
+		{"nil", nil},
+		{"not PEM", []byte("not a valid PEM file")},
+		{"corrupted PKCS#1", encodePEM("RSA PRIVATE KEY", []byte("corrupted"))},
+		{"corrupted PKCS#8", encodePEM("PRIVATE KEY", []byte("corrupted"))},
+		{"corrupted OpenSSH", encodePEM("OPENSSH PRIVATE KEY", []byte("corrupted"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePrivateKey(tt.pem, nil)
+			if err == nil {
+				t.Fatal("ParsePrivateKey() should return error")
+			}
+			if errors.Is(err, ErrUnsupportedKey) || errors.Is(err, ErrIncorrectPassphrase) || errors.Is(err, ErrPassphraseRequired) {
+				t.Errorf("ParsePrivateKey() error = %v, want error other than the ones of passphrase and key type", err)
+			}
+		})
+	}
+}
+
+func TestGetEcdsaKeyWithOtherKeyType(t *testing.T) {
+	keys := generateTestKeys(t)
+
+	_, err := GetEcdsaKey(marshalPKCS8PEM(t, keys.ed25519, "", nil), nil)
+	if !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("GetEcdsaKey() error = %v, want %v", err, ErrUnsupportedKey)
+	}
+}
+
+func BenchmarkParsePrivateKey(b *testing.B) {
+	keys := generateTestKeys(b)
+	benchmarks := []struct {
+		name       string
+		pem        []byte
+		passphrase string
+	}{
+		{"PKCS#8", marshalPKCS8PEM(b, keys.ecdsa, "", nil), ""},
+		{"encrypted PKCS#8", marshalPKCS8PEM(b, keys.ecdsa, testPassphrase, nil), testPassphrase},
+		{"OpenSSH", marshalOpenSSHPEM(b, keys.ed25519, ""), ""},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = ParsePrivateKey(bm.pem, []byte(bm.passphrase))
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package database
+
+import "github.com/alexhokl/helper/database/sqldata"
+
+// ColumnKind is a category of database types of columns which are
+// formatted in the same way; see sqldata.ColumnKind
+type ColumnKind = sqldata.ColumnKind
+
+const (
+	ColumnKindUnknown  = sqldata.ColumnKindUnknown
+	ColumnKindBoolean  = sqldata.ColumnKindBoolean
+	ColumnKindInteger  = sqldata.ColumnKindInteger
+	ColumnKindDecimal  = sqldata.ColumnKindDecimal
+	ColumnKindFloat    = sqldata.ColumnKindFloat
+	ColumnKindText     = sqldata.ColumnKindText
+	ColumnKindBinary   = sqldata.ColumnKindBinary
+	ColumnKindDate     = sqldata.ColumnKindDate
+	ColumnKindTime     = sqldata.ColumnKindTime
+	ColumnKindDateTime = sqldata.ColumnKindDateTime
+	ColumnKindUUID     = sqldata.ColumnKindUUID
+)
+
+// ColumnType contains metadata of a column; see sqldata.ColumnType
+type ColumnType = sqldata.ColumnType
+
+// GetTypedValue returns a value from a cell reference formatted according
+// to the type of its column (see sqldata.FormatValue). It is the same as
+// GetValue if columnType is nil.
+func GetTypedValue(pval *interface{}, columnType *ColumnType) string {
+	return sqldata.FormatValue(*pval, columnType)
+}
+
+// columnTypeAt returns nil if index is out of the range of types as
+// TableData may be created without types of columns
+func columnTypeAt(types []ColumnType, index int) *ColumnType {
+	if index >= len(types) {
+		return nil
+	}
+	return &types[index]
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestGetDataContextColumnTypes(t *testing.T) {
+	db := newFakeDB(t, map[string]*fakeResult{
+		"SELECT id, name, price FROM products": {
+			columns: []fakeColumn{
+				{name: "id", typeName: "INT", scanType: reflect.TypeFor[int64]()},
+				{name: "name", typeName: "nvarchar", nullable: true, length: 50, scanType: reflect.TypeFor[string]()},
+				{name: "price", typeName: "DECIMAL", precision: 10, scale: 2, scanType: reflect.TypeFor[[]byte]()},
+			},
+			rows: [][]driver.Value{{int64(1), "pen", []byte("1.50")}},
+		},
+	})
+
+	data, err := GetDataContext(context.Background(), db, "SELECT id, name, price FROM products")
+	if err != nil {
+		t.Fatalf("GetDataContext() error: %v", err)
+	}
+
+	expected := []ColumnType{
+		{Name: "id", DatabaseTypeName: "INT", HasNullable: true, ScanType: reflect.TypeFor[int64]()},
+		{Name: "name", DatabaseTypeName: "NVARCHAR", Nullable: true, HasNullable: true, Length: 50, HasLength: true, ScanType: reflect.TypeFor[string]()},
+		{Name: "price", DatabaseTypeName: "DECIMAL", HasNullable: true, Precision: 10, Scale: 2, HasPrecisionScale: true, ScanType: reflect.TypeFor[[]byte]()},
+	}
+	if !reflect.DeepEqual(data.ColumnTypes, expected) {
+		t.Errorf("GetDataContext() column types = %+v, want %+v", data.ColumnTypes, expected)
+	}
+}
+
+func TestGetTypedValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      interface{}
+		columnType *ColumnType
+		expected   string
+	}{
+		{"without type", []byte{0x01, 0xAB}, nil, "\x01\xab"},
+		{"binary", []byte{0x01, 0xAB}, &ColumnType{DatabaseTypeName: "VARBINARY"}, "0x01AB"},
+		{"null", nil, &ColumnType{DatabaseTypeName: "VARBINARY"}, "NULL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val := tt.input
+			if result := GetTypedValue(&val, tt.columnType); result != tt.expected {
+				t.Errorf("GetTypedValue(%v) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestColumnTypeAt(t *testing.T) {
+	types := []ColumnType{{Name: "id"}}
+	if c := columnTypeAt(types, 0); c == nil || c.Name != "id" {
+		t.Errorf("columnTypeAt(0) = %v", c)
+	}
+	if c := columnTypeAt(types, 1); c != nil {
+		t.Errorf("columnTypeAt(1) = %v, want nil", c)
+	}
+	if c := columnTypeAt(nil, 0); c != nil {
+		t.Errorf("columnTypeAt() without types = %v, want nil", c)
+	}
+}
+
+func BenchmarkGetTypedValue(b *testing.B) {
+	var val interface{} = []byte{0x01, 0x02, 0x03, 0x04}
+	columnType := &ColumnType{DatabaseTypeName: "VARBINARY"}
+	for i := 0; i < b.N; i++ {
+		_ = GetTypedValue(&val, columnType)
+	}
+}
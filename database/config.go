@@ -1,5 +1,7 @@
 package database
 
+import "time"
+
 // Config struct
 type Config struct {
 	Server   string `yaml:"server" json:"server"`
@@ -13,4 +15,8 @@ type Config struct {
 type PostgresConfig struct {
 	Config `yaml:"config" json:"config"`
 	UseSSL bool `yaml:"use_ssl" json:"use_ssl"`
+	// StatementTimeout is the statement_timeout of connections which
+	// aborts statements on the server even if their clients are gone; zero
+	// uses the default of the server
+	StatementTimeout time.Duration `yaml:"statement_timeout" json:"statement_timeout"`
 }
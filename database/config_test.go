@@ -3,6 +3,7 @@ package database
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -266,7 +267,8 @@ func TestPostgresConfigYAMLFieldNames(t *testing.T) {
   username: pguser
   password: pgpass
   name: pgdb
-use_ssl: true`
+use_ssl: true
+statement_timeout: 30s`
 
 	var config PostgresConfig
 	if err := yaml.Unmarshal([]byte(yamlStr), &config); err != nil {
@@ -279,4 +281,7 @@ use_ssl: true`
 	if config.UseSSL != true {
 		t.Errorf("UseSSL = %v, want true", config.UseSSL)
 	}
+	if config.StatementTimeout != 30*time.Second {
+		t.Errorf("StatementTimeout = %v, want %v", config.StatementTimeout, 30*time.Second)
+	}
 }
@@ -3,11 +3,15 @@ package database
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/alexhokl/helper/database/sqldata"
 	"github.com/olekukonko/tablewriter"
 )
 
+const dumpBatchSize = 1000
+
 // DumpTables prints the specified tables to standard output stream
 func DumpTables(list []TableData) error {
 	for _, data := range list {
@@ -21,26 +25,108 @@ func DumpTables(list []TableData) error {
 
 // DumpTable prints the specified table to standard output stream
 func DumpTable(data *TableData) error {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader(data.Columns)
 	var rows [][]string
 	for _, r := range data.Rows {
-		vals := getStringValues(r)
+		vals := getTypedStringValues(r, data.ColumnTypes)
 		rows = append(rows, vals)
 	}
+	renderTable(data.Columns, rows)
+	return nil
+}
+
+// DumpRowReader prints rows of the specified reader to standard output
+// stream as a single table; rows are rendered in batches of 1000 rows so that
+// large result sets are not held in memory, and widths of columns are
+// computed from the first batch (a longer value in a later batch widens its
+// column from that batch onwards)
+func DumpRowReader(reader *RowReader) error {
+	var widths []int
+	var rows [][]string
+	for r, err := range reader.Rows() {
+		if err != nil {
+			return err
+		}
+		if len(rows) == dumpBatchSize {
+			widths = renderTableBatch(reader.Columns(), rows, widths, false)
+			rows = rows[:0]
+		}
+		rows = append(rows, getTypedStringValues(r, reader.ColumnTypes()))
+	}
+	renderTableBatch(reader.Columns(), rows, widths, true)
+	return nil
+}
+
+func renderTable(columns []string, rows [][]string) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(columns)
 	table.AppendBulk(rows)
 	table.Render()
-	return nil
+}
+
+// renderTableBatch renders a batch of rows of a streamed table; the header
+// and the top border are rendered only with the first batch (when widths is
+// nil) and the bottom border only with the last one. It returns the widths of
+// columns to be used by the subsequent batches.
+func renderTableBatch(columns []string, rows [][]string, widths []int, last bool) []int {
+	table := tablewriter.NewWriter(os.Stdout)
+	// wrapping would make widths of columns differ between batches
+	table.SetAutoWrapText(false)
+	first := widths == nil
+	if first {
+		widths = getColumnWidths(columns, rows)
+		table.SetHeader(columns)
+	}
+	for i, w := range widths {
+		table.SetColMinWidth(i, w)
+	}
+	table.SetBorders(tablewriter.Border{Left: true, Right: true, Top: first, Bottom: last})
+	table.AppendBulk(rows)
+	table.Render()
+	return widths
+}
+
+func getColumnWidths(columns []string, rows [][]string) []int {
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = getDisplayWidth(c)
+	}
+	for _, row := range rows {
+		for i, v := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], getDisplayWidth(v))
+			}
+		}
+	}
+	return widths
+}
+
+func getDisplayWidth(s string) int {
+	width := 0
+	for _, line := range strings.Split(s, "\n") {
+		width = max(width, tablewriter.DisplayWidth(line))
+	}
+	return width
 }
 
 func getStringValues(row []interface{}) []string {
+	return getTypedStringValues(row, nil)
+}
+
+func getTypedStringValues(row []interface{}, columnTypes []ColumnType) []string {
 	list := []string{}
-	for _, c := range row {
-		list = append(list, getStringValue(c.(*interface{})))
+	for i, c := range row {
+		list = append(list, getTypedStringValue(c.(*interface{}), columnTypeAt(columnTypes, i)))
 	}
 	return list
 }
 
+func getTypedStringValue(val *interface{}, columnType *ColumnType) string {
+	if s, ok := sqldata.FormatTypedValue(*val, columnType); ok {
+		return s
+	}
+	return getStringValue(val)
+}
+
 func getStringValue(val *interface{}) string {
 	switch v := (*val).(type) {
 	case nil:
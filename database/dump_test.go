@@ -1,6 +1,10 @@
 package database
 
 import (
+	"context"
+	"io"
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -157,6 +161,26 @@ func TestGetStringValuesMixedTypes(t *testing.T) {
 	}
 }
 
+func TestGetTypedStringValues(t *testing.T) {
+	var val1 interface{} = true
+	var val2 interface{} = []byte{0xCA, 0xFE}
+	var val3 interface{} = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var val4 interface{} = "extra"
+
+	row := []interface{}{&val1, &val2, &val3, &val4}
+	columnTypes := []ColumnType{
+		{Name: "active", DatabaseTypeName: "BIT"},
+		{Name: "hash", DatabaseTypeName: "VARBINARY"},
+		{Name: "birthday", DatabaseTypeName: "DATE"},
+	}
+	result := getTypedStringValues(row, columnTypes)
+
+	expected := []string{"TRUE", "0xCAFE", "2023-01-01", "extra"}
+	if strings.Join(result, ",") != strings.Join(expected, ",") {
+		t.Errorf("getTypedStringValues() = %v, want %v", result, expected)
+	}
+}
+
 func TestDumpTablesEmpty(t *testing.T) {
 	err := DumpTables([]TableData{})
 	if err != nil {
@@ -227,6 +251,100 @@ func TestDumpTablesMultipleTables(t *testing.T) {
 	}
 }
 
+func TestDumpRowReader(t *testing.T) {
+	db := newFakeDB(t, map[string]*fakeResult{fakeQueryNumbers: newFakeNumbersResult(2*dumpBatchSize + 1)})
+	reader, err := GetRowReader(context.Background(), db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := DumpRowReader(reader); err != nil {
+			t.Errorf("DumpRowReader() error: %v", err)
+		}
+	})
+
+	headers := 0
+	borders := 0
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Join(strings.Fields(line), " ") == "| N |" {
+			headers++
+		}
+		if strings.HasPrefix(line, "+") {
+			borders++
+		}
+	}
+	if headers != 1 {
+		t.Errorf("DumpRowReader() printed %d headers, want 1", headers)
+	}
+	// top border, line below header and bottom border
+	if borders != 3 {
+		t.Errorf("DumpRowReader() printed %d borders, want 3", borders)
+	}
+	if !strings.Contains(output, " 2000 |") {
+		t.Error("DumpRowReader() did not print the last row")
+	}
+}
+
+func TestRenderTableBatchColumnWidths(t *testing.T) {
+	columns := []string{"name", "value"}
+	output := captureStdout(t, func() {
+		widths := renderTableBatch(columns, [][]string{{"alice", "1"}, {"bob", "12345678"}}, nil, false)
+		renderTableBatch(columns, [][]string{{"carol", "1"}}, widths, true)
+	})
+
+	// rows of the later batch are aligned with those of the first batch
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	want := len(lines[0])
+	for _, line := range lines {
+		if len(line) != want {
+			t.Errorf("renderTableBatch() printed line %q of width %d, want %d", line, len(line), want)
+		}
+	}
+	if len(lines) != 7 {
+		t.Errorf("renderTableBatch() printed %d lines, want 7", len(lines))
+	}
+}
+
+func TestDumpRowReaderEmpty(t *testing.T) {
+	db := newFakeDB(t, map[string]*fakeResult{fakeQueryNumbers: newFakeNumbersResult(0)})
+	reader, err := GetRowReader(context.Background(), db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := DumpRowReader(reader); err != nil {
+			t.Errorf("DumpRowReader() error: %v", err)
+		}
+	})
+
+	want := "+---+\n| N |\n+---+\n+---+\n"
+	if output != want {
+		t.Errorf("DumpRowReader() printed %q, want %q", output, want)
+	}
+}
+
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+	f()
+	_ = w.Close()
+	return <-output
+}
+
 // Benchmark tests
 func BenchmarkGetStringValue(b *testing.B) {
 	var val interface{} = "benchmark string"
@@ -0,0 +1,490 @@
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexhokl/helper/database/sqldata"
+)
+
+// Exporter writes columns and rows of a table in a format to a writer; see
+// ExportTable and ExportRowReader
+type Exporter interface {
+	// WriteHeader writes the columns before any rows; columnTypes can be nil
+	// if they are unknown
+	WriteHeader(columns []string, columnTypes []ColumnType) error
+	// WriteRow writes a row in the same format as TableData.Rows
+	WriteRow(row []interface{}) error
+	// Close writes the remaining data after the last row; it does not close
+	// the underlying writer
+	Close() error
+}
+
+// BinaryFormat is the format of values of binary columns in text
+type BinaryFormat int
+
+const (
+	// BinaryFormatHex formats binary values in hexadecimal such as 0xCAFE
+	BinaryFormatHex BinaryFormat = iota
+	// BinaryFormatBase64 formats binary values in standard base64 encoding
+	BinaryFormatBase64
+)
+
+// layouts of date-times if time format is not specified
+const (
+	exportDateTimeFormat         = "2006-01-02T15:04:05.999999999"
+	exportDateTimeTimeZoneFormat = time.RFC3339Nano
+	exportTimeFormat             = "15:04:05.999999999"
+	exportTimeTimeZoneFormat     = "15:04:05.999999999Z07:00"
+)
+
+// ExportOptions configures the formats of values and the layouts of the
+// exporters; options which do not apply to an exporter are ignored by it.
+type ExportOptions struct {
+	nullString   string
+	timeFormat   string
+	binaryFormat BinaryFormat
+	delimiter    rune
+	useCRLF      bool
+	noHeader     bool
+	batchSize    int
+	rowGroupSize int
+}
+
+// ExportOption is a functional option for the exporters, such as
+// NewCSVExporter and NewParquetExporter.
+type ExportOption func(*ExportOptions)
+
+// WithNullString sets the text of NULL values in CSV and Markdown; it
+// defaults to an empty string
+func WithNullString(s string) ExportOption {
+	return func(o *ExportOptions) {
+		o.nullString = s
+	}
+}
+
+// WithTimeFormat sets the layout of date-time values in CSV, JSON Lines and
+// Markdown; it defaults to RFC 3339 with time zones only if the columns
+// have time zones. Values of date and time columns are not affected.
+func WithTimeFormat(layout string) ExportOption {
+	return func(o *ExportOptions) {
+		o.timeFormat = layout
+	}
+}
+
+// WithBinaryFormat sets the format of values of binary columns in CSV,
+// JSON Lines and Markdown; it defaults to BinaryFormatHex
+func WithBinaryFormat(format BinaryFormat) ExportOption {
+	return func(o *ExportOptions) {
+		o.binaryFormat = format
+	}
+}
+
+// WithDelimiter sets the delimiter of fields in CSV; it defaults to comma
+func WithDelimiter(delimiter rune) ExportOption {
+	return func(o *ExportOptions) {
+		o.delimiter = delimiter
+	}
+}
+
+// WithCRLF uses \r\n as the line terminator in CSV instead of \n
+func WithCRLF() ExportOption {
+	return func(o *ExportOptions) {
+		o.useCRLF = true
+	}
+}
+
+// WithoutHeader omits the header of column names in CSV
+func WithoutHeader() ExportOption {
+	return func(o *ExportOptions) {
+		o.noHeader = true
+	}
+}
+
+// WithInsertBatchSize sets the number of rows in each SQL INSERT statement;
+// it defaults to 100 and SQL Server supports up to 1000
+func WithInsertBatchSize(size int) ExportOption {
+	return func(o *ExportOptions) {
+		o.batchSize = size
+	}
+}
+
+// WithRowGroupSize sets the number of rows in each row group of Parquet,
+// which are held in memory until the group is written; it defaults to 10000
+func WithRowGroupSize(size int) ExportOption {
+	return func(o *ExportOptions) {
+		o.rowGroupSize = size
+	}
+}
+
+func defaultExportOptions() *ExportOptions {
+	return &ExportOptions{
+		delimiter:    ',',
+		batchSize:    100,
+		rowGroupSize: 10000,
+	}
+}
+
+func newExportOptions(opts []ExportOption) *ExportOptions {
+	options := defaultExportOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// ExportTable writes data with exporter; exporter is closed even if data
+// cannot be written and errors of closing are joined with the write error
+func ExportTable(data *TableData, exporter Exporter) (err error) {
+	defer func() {
+		err = errors.Join(err, exporter.Close())
+	}()
+	if err := exporter.WriteHeader(data.Columns, data.ColumnTypes); err != nil {
+		return err
+	}
+	for _, r := range data.Rows {
+		if err := exporter.WriteRow(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportRowReader writes rows of reader with exporter one at a time so that
+// large result sets are not held in memory; reader and exporter are closed
+// even if rows cannot be written
+func ExportRowReader(reader *RowReader, exporter Exporter) (err error) {
+	defer func() {
+		err = errors.Join(err, exporter.Close())
+	}()
+	if err := exporter.WriteHeader(reader.Columns(), reader.ColumnTypes()); err != nil {
+		_ = reader.Close()
+		return err
+	}
+	for r, err := range reader.Rows() {
+		if err != nil {
+			return err
+		}
+		if err := exporter.WriteRow(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportHeader contains columns shared by exporters
+type exportHeader struct {
+	columns     []string
+	columnTypes []ColumnType
+}
+
+func (h *exportHeader) setHeader(columns []string, columnTypes []ColumnType) error {
+	if columnTypes != nil && len(columnTypes) != len(columns) {
+		return fmt.Errorf("there are %d column types for %d columns", len(columnTypes), len(columns))
+	}
+	h.columns = columns
+	h.columnTypes = columnTypes
+	return nil
+}
+
+// values returns values of row normalised by exportValue
+func (h *exportHeader) values(row []interface{}) ([]interface{}, error) {
+	if len(row) != len(h.columns) {
+		return nil, fmt.Errorf("row has %d values but there are %d columns", len(row), len(h.columns))
+	}
+	values := make([]interface{}, len(row))
+	for i, cell := range row {
+		values[i] = exportValue(cell, columnTypeAt(h.columnTypes, i))
+	}
+	return values, nil
+}
+
+// exportValue returns the value of a cell; byte slices are strings unless
+// they are of binary columns and UUIDs of SQL Server are strings
+func exportValue(cell interface{}, columnType *ColumnType) interface{} {
+	value := cell
+	if p, ok := cell.(*interface{}); ok {
+		if p == nil {
+			return nil
+		}
+		value = *p
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+	switch columnKind(columnType) {
+	case ColumnKindBinary:
+		return b
+	case ColumnKindUUID:
+		if len(b) == 16 {
+			return sqldata.FormatValue(b, columnType)
+		}
+	}
+	return string(b)
+}
+
+func columnKind(columnType *ColumnType) ColumnKind {
+	if columnType == nil {
+		return ColumnKindUnknown
+	}
+	return columnType.Kind()
+}
+
+func hasTimeZone(columnType *ColumnType) bool {
+	return columnType != nil && columnType.HasTimeZone()
+}
+
+// formatText formats a value returned by exportValue for text formats
+func (o *ExportOptions) formatText(value interface{}, columnType *ColumnType) string {
+	switch v := value.(type) {
+	case nil:
+		return o.nullString
+	case []byte:
+		return o.formatBinary(v)
+	case time.Time:
+		return o.formatTime(v, columnType)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (o *ExportOptions) formatBinary(b []byte) string {
+	if o.binaryFormat == BinaryFormatBase64 {
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return "0x" + strings.ToUpper(hex.EncodeToString(b))
+}
+
+func (o *ExportOptions) formatTime(t time.Time, columnType *ColumnType) string {
+	switch columnKind(columnType) {
+	case ColumnKindDate:
+		return t.Format(time.DateOnly)
+	case ColumnKindTime:
+		if hasTimeZone(columnType) {
+			return t.Format(exportTimeTimeZoneFormat)
+		}
+		return t.Format(exportTimeFormat)
+	}
+	switch {
+	case o.timeFormat != "":
+		return t.Format(o.timeFormat)
+	case columnType != nil && !columnType.HasTimeZone():
+		return t.Format(exportDateTimeFormat)
+	default:
+		return t.Format(exportDateTimeTimeZoneFormat)
+	}
+}
+
+// jsonValue converts a value returned by exportValue for JSON; decimals
+// are numbers without loss of precision
+func (o *ExportOptions) jsonValue(value interface{}, columnType *ColumnType) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return o.formatBinary(v)
+	case time.Time:
+		return o.formatTime(v, columnType)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return strconv.FormatFloat(float64(v), 'g', -1, 32)
+		}
+	case string:
+		if isNumericKind(columnKind(columnType)) && isNumberLiteral(v) {
+			return json.Number(v)
+		}
+	}
+	return value
+}
+
+func isNumericKind(kind ColumnKind) bool {
+	return kind == ColumnKindInteger || kind == ColumnKindDecimal || kind == ColumnKindFloat
+}
+
+// isNumberLiteral returns true if s is a number in both JSON and SQL, such
+// as -12.50 and 1e10
+func isNumberLiteral(s string) bool {
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
+
+type csvExporter struct {
+	exportHeader
+	options *ExportOptions
+	writer  *csv.Writer
+}
+
+// NewCSVExporter returns an exporter of CSV; see WithDelimiter, WithCRLF,
+// WithoutHeader, WithNullString, WithTimeFormat and WithBinaryFormat for
+// the dialect
+func NewCSVExporter(w io.Writer, opts ...ExportOption) Exporter {
+	options := newExportOptions(opts)
+	writer := csv.NewWriter(w)
+	writer.Comma = options.delimiter
+	writer.UseCRLF = options.useCRLF
+	return &csvExporter{options: options, writer: writer}
+}
+
+func (e *csvExporter) WriteHeader(columns []string, columnTypes []ColumnType) error {
+	if err := e.setHeader(columns, columnTypes); err != nil {
+		return err
+	}
+	if e.options.noHeader {
+		return nil
+	}
+	return e.writer.Write(columns)
+}
+
+func (e *csvExporter) WriteRow(row []interface{}) error {
+	values, err := e.values(row)
+	if err != nil {
+		return err
+	}
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = e.options.formatText(v, columnTypeAt(e.columnTypes, i))
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExporter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type jsonLinesExporter struct {
+	exportHeader
+	options *ExportOptions
+	writer  io.Writer
+	keys    [][]byte
+	buf     bytes.Buffer
+}
+
+// NewJSONLinesExporter returns an exporter of JSON Lines with an object of
+// each row; NULL values are null and keys are in the order of columns. See
+// WithTimeFormat and WithBinaryFormat for formats of values.
+func NewJSONLinesExporter(w io.Writer, opts ...ExportOption) Exporter {
+	return &jsonLinesExporter{options: newExportOptions(opts), writer: w}
+}
+
+func (e *jsonLinesExporter) WriteHeader(columns []string, columnTypes []ColumnType) error {
+	if err := e.setHeader(columns, columnTypes); err != nil {
+		return err
+	}
+	e.keys = make([][]byte, len(columns))
+	for i, c := range columns {
+		key, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		e.keys[i] = key
+	}
+	return nil
+}
+
+func (e *jsonLinesExporter) WriteRow(row []interface{}) error {
+	values, err := e.values(row)
+	if err != nil {
+		return err
+	}
+
+	// objects are written manually to preserve the order of columns
+	e.buf.Reset()
+	e.buf.WriteString("{")
+	for i, v := range values {
+		if i > 0 {
+			e.buf.WriteString(",")
+		}
+		value, err := json.Marshal(e.options.jsonValue(v, columnTypeAt(e.columnTypes, i)))
+		if err != nil {
+			return fmt.Errorf("unable to encode value of column %s: %w", e.columns[i], err)
+		}
+		e.buf.Write(e.keys[i])
+		e.buf.WriteString(":")
+		e.buf.Write(value)
+	}
+	e.buf.WriteString("}\n")
+	_, err = e.buf.WriteTo(e.writer)
+	return err
+}
+
+func (e *jsonLinesExporter) Close() error {
+	return nil
+}
+
+type markdownExporter struct {
+	exportHeader
+	options *ExportOptions
+	writer  io.Writer
+}
+
+// NewMarkdownExporter returns an exporter of a Markdown table; see
+// WithNullString, WithTimeFormat and WithBinaryFormat for formats of values
+func NewMarkdownExporter(w io.Writer, opts ...ExportOption) Exporter {
+	return &markdownExporter{options: newExportOptions(opts), writer: w}
+}
+
+func (e *markdownExporter) WriteHeader(columns []string, columnTypes []ColumnType) error {
+	if err := e.setHeader(columns, columnTypes); err != nil {
+		return err
+	}
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+		if isNumericKind(columnKind(columnTypeAt(columnTypes, i))) {
+			separators[i] = "---:"
+		}
+	}
+	if err := e.writeRow(columns); err != nil {
+		return err
+	}
+	return e.writeRow(separators)
+}
+
+func (e *markdownExporter) WriteRow(row []interface{}) error {
+	values, err := e.values(row)
+	if err != nil {
+		return err
+	}
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = e.options.formatText(v, columnTypeAt(e.columnTypes, i))
+	}
+	return e.writeRow(cells)
+}
+
+func (e *markdownExporter) writeRow(cells []string) error {
+	var builder strings.Builder
+	builder.WriteString("|")
+	for _, c := range cells {
+		builder.WriteString(" ")
+		builder.WriteString(sqldata.EscapeMarkdown(c))
+		builder.WriteString(" |")
+	}
+	builder.WriteString("\n")
+	_, err := io.WriteString(e.writer, builder.String())
+	return err
+}
+
+func (e *markdownExporter) Close() error {
+	return nil
+}
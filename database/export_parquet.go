@@ -0,0 +1,656 @@
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// physical types, converted types, encodings and other enumerations of
+// the Parquet format
+const (
+	parquetBoolean   int32 = 0
+	parquetInt32     int32 = 1
+	parquetInt64     int32 = 2
+	parquetDouble    int32 = 5
+	parquetByteArray int32 = 6
+
+	parquetConvertedUTF8            int32 = 0
+	parquetConvertedDate            int32 = 6
+	parquetConvertedTimeMicros      int32 = 8
+	parquetConvertedTimestampMicros int32 = 10
+
+	parquetEncodingPlain int32 = 0
+	parquetEncodingRLE   int32 = 3
+
+	parquetRepetitionOptional   int32 = 1
+	parquetCodecUncompressed    int32 = 0
+	parquetPageTypeDataPage     int32 = 0
+	parquetLogicalTypeString    int16 = 1
+	parquetLogicalTypeDate      int16 = 6
+	parquetLogicalTypeTime      int16 = 7
+	parquetLogicalTypeTimestamp int16 = 8
+	parquetTimeUnitMicros       int16 = 2
+)
+
+const (
+	parquetMagic     = "PAR1"
+	parquetCreatedBy = "github.com/alexhokl/helper"
+)
+
+var parquetTimeOfDayFormats = []string{"15:04:05.999999999", "15:04:05.999999999Z07:00", "15:04:05.999999999Z07"}
+
+type parquetColumn struct {
+	name          string
+	columnType    *ColumnType
+	physicalType  int32
+	logicalType   int16
+	adjustedToUTC bool
+	// values of the current row group where nil is NULL
+	values []interface{}
+}
+
+type parquetColumnChunk struct {
+	offset int64
+	size   int64
+}
+
+type parquetRowGroup struct {
+	rowCount int64
+	size     int64
+	chunks   []parquetColumnChunk
+}
+
+type parquetExporter struct {
+	exportHeader
+	options        *ExportOptions
+	writer         io.Writer
+	offset         int64
+	parquetColumns []*parquetColumn
+	rowCount       int
+	rowGroups      []parquetRowGroup
+	inferred       bool
+}
+
+// NewParquetExporter returns an exporter of Parquet with an optional column
+// of each column. Decimals are strings to keep their precisions, date-times
+// and times are in microseconds and types of columns of unknown database
+// types are inferred from values of the first row group. See
+// WithRowGroupSize for the memory usage.
+//
+// Only a subset of the format is written: a flat schema of OPTIONAL columns,
+// PLAIN encoding without dictionaries, no compression, no statistics and a
+// single data page (version 1) per column chunk; files are therefore larger
+// than those of libraries of Parquet such as pyarrow.
+func NewParquetExporter(w io.Writer, opts ...ExportOption) Exporter {
+	return &parquetExporter{options: newExportOptions(opts), writer: w}
+}
+
+func (e *parquetExporter) WriteHeader(columns []string, columnTypes []ColumnType) error {
+	if e.options.rowGroupSize < 1 {
+		return fmt.Errorf("invalid row group size: %d", e.options.rowGroupSize)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("no column to be exported")
+	}
+	if err := e.setHeader(columns, columnTypes); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(columns))
+	for i, name := range columns {
+		if name == "" {
+			return fmt.Errorf("column %d has no name", i+1)
+		}
+		if names[name] {
+			return fmt.Errorf("column %s is duplicated", name)
+		}
+		names[name] = true
+
+		c := &parquetColumn{name: name, columnType: columnTypeAt(columnTypes, i)}
+		c.setType(columnKind(c.columnType), hasTimeZone(c.columnType))
+		e.parquetColumns = append(e.parquetColumns, c)
+	}
+	return e.write([]byte(parquetMagic))
+}
+
+func (e *parquetExporter) WriteRow(row []interface{}) error {
+	values, err := e.values(row)
+	if err != nil {
+		return err
+	}
+	for i, v := range values {
+		e.parquetColumns[i].values = append(e.parquetColumns[i].values, v)
+	}
+	e.rowCount++
+	if e.rowCount < e.options.rowGroupSize {
+		return nil
+	}
+	return e.flush()
+}
+
+func (e *parquetExporter) Close() error {
+	if err := e.flush(); err != nil {
+		return err
+	}
+	e.inferTypes()
+
+	footer := e.fileMetadata()
+	length := binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))
+	if err := e.write(footer); err != nil {
+		return err
+	}
+	if err := e.write(length); err != nil {
+		return err
+	}
+	return e.write([]byte(parquetMagic))
+}
+
+func (e *parquetExporter) write(b []byte) error {
+	n, err := e.writer.Write(b)
+	e.offset += int64(n)
+	return err
+}
+
+// inferTypes sets types of columns of unknown types from their first
+// non-NULL values
+func (e *parquetExporter) inferTypes() {
+	if e.inferred {
+		return
+	}
+	e.inferred = true
+	for _, c := range e.parquetColumns {
+		if c.physicalType >= 0 {
+			continue
+		}
+		kind := ColumnKindText
+		for _, v := range c.values {
+			if v != nil {
+				kind = inferColumnKind(v)
+				break
+			}
+		}
+		c.setType(kind, true)
+	}
+}
+
+func inferColumnKind(value interface{}) ColumnKind {
+	switch value.(type) {
+	case bool:
+		return ColumnKindBoolean
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return ColumnKindInteger
+	case float32, float64:
+		return ColumnKindFloat
+	case time.Time:
+		return ColumnKindDateTime
+	case []byte:
+		return ColumnKindBinary
+	default:
+		return ColumnKindText
+	}
+}
+
+// setType sets the Parquet type of a column of kind; the physical type is
+// -1 if kind is unknown
+func (c *parquetColumn) setType(kind ColumnKind, hasTimeZone bool) {
+	c.adjustedToUTC = hasTimeZone
+	switch kind {
+	case ColumnKindBoolean:
+		c.physicalType = parquetBoolean
+	case ColumnKindInteger:
+		c.physicalType = parquetInt64
+	case ColumnKindFloat:
+		c.physicalType = parquetDouble
+	case ColumnKindDecimal, ColumnKindText, ColumnKindUUID:
+		c.physicalType, c.logicalType = parquetByteArray, parquetLogicalTypeString
+	case ColumnKindBinary:
+		c.physicalType = parquetByteArray
+	case ColumnKindDate:
+		c.physicalType, c.logicalType = parquetInt32, parquetLogicalTypeDate
+	case ColumnKindTime:
+		c.physicalType, c.logicalType = parquetInt64, parquetLogicalTypeTime
+	case ColumnKindDateTime:
+		c.physicalType, c.logicalType = parquetInt64, parquetLogicalTypeTimestamp
+	default:
+		c.physicalType = -1
+	}
+}
+
+// flush writes the current row group
+func (e *parquetExporter) flush() error {
+	if e.rowCount == 0 {
+		return nil
+	}
+	e.inferTypes()
+
+	group := parquetRowGroup{rowCount: int64(e.rowCount)}
+	for _, c := range e.parquetColumns {
+		chunk, err := e.columnChunk(c)
+		if err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, parquetColumnChunk{offset: e.offset, size: int64(len(chunk))})
+		group.size += int64(len(chunk))
+		if err := e.write(chunk); err != nil {
+			return err
+		}
+		c.values = c.values[:0]
+	}
+	e.rowGroups = append(e.rowGroups, group)
+	e.rowCount = 0
+	return nil
+}
+
+// columnChunk returns a chunk of a data page of the values of c
+func (e *parquetExporter) columnChunk(c *parquetColumn) ([]byte, error) {
+	definitionLevels := make([]bool, len(c.values))
+	var data []byte
+	var booleans []bool
+	for i, v := range c.values {
+		if v == nil {
+			continue
+		}
+		definitionLevels[i] = true
+		if c.physicalType == parquetBoolean {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("unable to export %T to BOOLEAN column %s", v, c.name)
+			}
+			booleans = append(booleans, b)
+			continue
+		}
+		var err error
+		data, err = e.appendValue(data, c, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.physicalType == parquetBoolean {
+		data = appendBitPacked(data, booleans)
+	}
+
+	levels := appendRLEBooleans(nil, definitionLevels)
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+	page = append(page, levels...)
+	page = append(page, data...)
+
+	// sizes and counts of pages are i32 in the format
+	pageSize, err := toParquetI32(len(page))
+	if err != nil {
+		return nil, fmt.Errorf("page of column %s is too large (%d bytes); use a smaller row group size: %w", c.name, len(page), err)
+	}
+	valueCount, err := toParquetI32(len(c.values))
+	if err != nil {
+		return nil, fmt.Errorf("page of column %s has too many values (%d); use a smaller row group size: %w", c.name, len(c.values), err)
+	}
+
+	t := &thriftWriter{}
+	t.fieldI32(1, parquetPageTypeDataPage)
+	t.fieldI32(2, pageSize)
+	t.fieldI32(3, pageSize)
+	t.fieldStructBegin(5)
+	t.fieldI32(1, valueCount)
+	t.fieldI32(2, parquetEncodingPlain)
+	t.fieldI32(3, parquetEncodingRLE)
+	t.fieldI32(4, parquetEncodingRLE)
+	t.structEnd()
+	t.stop()
+
+	return append(t.buf.Bytes(), page...), nil
+}
+
+// toParquetI32 returns n as an i32 of the format, or an error if n overflows
+func toParquetI32(n int) (int32, error) {
+	if n > math.MaxInt32 {
+		return 0, fmt.Errorf("%d exceeds %d", n, math.MaxInt32)
+	}
+	return int32(n), nil
+}
+
+// appendValue appends a value in PLAIN encoding
+func (e *parquetExporter) appendValue(data []byte, c *parquetColumn, value interface{}) ([]byte, error) {
+	switch c.physicalType {
+	case parquetInt32:
+		t, ok := value.(time.Time)
+		if !ok {
+			break
+		}
+		date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return binary.LittleEndian.AppendUint32(data, uint32(int32(date.Unix()/(24*60*60)))), nil
+	case parquetInt64:
+		i, ok := e.int64Value(c, value)
+		if !ok {
+			break
+		}
+		return binary.LittleEndian.AppendUint64(data, uint64(i)), nil
+	case parquetDouble:
+		f, ok := toFloat64(value)
+		if !ok {
+			break
+		}
+		return binary.LittleEndian.AppendUint64(data, math.Float64bits(f)), nil
+	case parquetByteArray:
+		var b []byte
+		switch v := value.(type) {
+		case []byte:
+			b = v
+		case string:
+			b = []byte(v)
+		default:
+			if c.logicalType != parquetLogicalTypeString {
+				return nil, fmt.Errorf("unable to export %T to binary column %s", value, c.name)
+			}
+			b = []byte(e.options.formatText(v, c.columnType))
+		}
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(b)))
+		return append(data, b...), nil
+	}
+	return nil, fmt.Errorf("unable to export %T to column %s", value, c.name)
+}
+
+func (e *parquetExporter) int64Value(c *parquetColumn, value interface{}) (int64, bool) {
+	switch c.logicalType {
+	case parquetLogicalTypeTime:
+		t, ok := value.(time.Time)
+		if s, isString := value.(string); isString {
+			t, ok = parseTimeOfDay(s)
+		}
+		if !ok {
+			return 0, false
+		}
+		if c.adjustedToUTC {
+			t = t.UTC()
+		}
+		return int64(t.Hour())*3600000000 + int64(t.Minute())*60000000 + int64(t.Second())*1000000 + int64(t.Nanosecond()/1000), true
+	case parquetLogicalTypeTimestamp:
+		t, ok := value.(time.Time)
+		if !ok {
+			return 0, false
+		}
+		if !c.adjustedToUTC {
+			// the local date-time is kept as if it is in UTC
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		}
+		return t.UnixMicro(), true
+	}
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), uint64(v) <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	}
+	return 0, false
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func parseTimeOfDay(s string) (time.Time, bool) {
+	for _, layout := range parquetTimeOfDayFormats {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// appendBitPacked appends values in bits from the least significant bit
+func appendBitPacked(data []byte, values []bool) []byte {
+	for i := 0; i < len(values); i += 8 {
+		var b byte
+		for j := 0; j < 8 && i+j < len(values); j++ {
+			if values[i+j] {
+				b |= 1 << j
+			}
+		}
+		data = append(data, b)
+	}
+	return data
+}
+
+// appendRLEBooleans appends values in runs of the RLE/bit-packing hybrid
+// encoding with a bit width of 1
+func appendRLEBooleans(data []byte, values []bool) []byte {
+	for i := 0; i < len(values); {
+		j := i + 1
+		for j < len(values) && values[j] == values[i] {
+			j++
+		}
+		data = binary.AppendUvarint(data, uint64(j-i)<<1)
+		if values[i] {
+			data = append(data, 1)
+		} else {
+			data = append(data, 0)
+		}
+		i = j
+	}
+	return data
+}
+
+// fileMetadata returns FileMetaData of the footer
+func (e *parquetExporter) fileMetadata() []byte {
+	var rowCount int64
+	for _, g := range e.rowGroups {
+		rowCount += g.rowCount
+	}
+
+	t := &thriftWriter{}
+	t.fieldI32(1, 1)
+	t.fieldListBegin(2, thriftTypeStruct, len(e.parquetColumns)+1)
+	t.structBegin()
+	t.fieldString(4, "schema")
+	t.fieldI32(5, int32(len(e.parquetColumns)))
+	t.structEnd()
+	for _, c := range e.parquetColumns {
+		t.structBegin()
+		c.writeSchemaElement(t)
+		t.structEnd()
+	}
+	t.fieldI64(3, rowCount)
+	t.fieldListBegin(4, thriftTypeStruct, len(e.rowGroups))
+	for _, g := range e.rowGroups {
+		t.structBegin()
+		t.fieldListBegin(1, thriftTypeStruct, len(g.chunks))
+		for i, chunk := range g.chunks {
+			t.structBegin()
+			t.fieldI64(2, chunk.offset)
+			t.fieldStructBegin(3)
+			e.parquetColumns[i].writeColumnMetadata(t, g.rowCount, chunk)
+			t.structEnd()
+			t.structEnd()
+		}
+		t.fieldI64(2, g.size)
+		t.fieldI64(3, g.rowCount)
+		t.structEnd()
+	}
+	t.fieldString(6, parquetCreatedBy)
+	t.stop()
+	return t.buf.Bytes()
+}
+
+func (c *parquetColumn) writeSchemaElement(t *thriftWriter) {
+	t.fieldI32(1, c.physicalType)
+	t.fieldI32(3, parquetRepetitionOptional)
+	t.fieldString(4, c.name)
+
+	switch c.logicalType {
+	case parquetLogicalTypeString:
+		t.fieldI32(6, parquetConvertedUTF8)
+	case parquetLogicalTypeDate:
+		t.fieldI32(6, parquetConvertedDate)
+	case parquetLogicalTypeTime:
+		// converted types are only for values adjusted to UTC
+		if c.adjustedToUTC {
+			t.fieldI32(6, parquetConvertedTimeMicros)
+		}
+	case parquetLogicalTypeTimestamp:
+		if c.adjustedToUTC {
+			t.fieldI32(6, parquetConvertedTimestampMicros)
+		}
+	default:
+		return
+	}
+
+	t.fieldStructBegin(10)
+	t.fieldStructBegin(c.logicalType)
+	if c.logicalType == parquetLogicalTypeTime || c.logicalType == parquetLogicalTypeTimestamp {
+		t.fieldBool(1, c.adjustedToUTC)
+		t.fieldStructBegin(2)
+		t.fieldStructBegin(parquetTimeUnitMicros)
+		t.structEnd()
+		t.structEnd()
+	}
+	t.structEnd()
+	t.structEnd()
+}
+
+func (c *parquetColumn) writeColumnMetadata(t *thriftWriter, rowCount int64, chunk parquetColumnChunk) {
+	t.fieldI32(1, c.physicalType)
+	t.fieldListBegin(2, thriftTypeI32, 2)
+	t.i32(parquetEncodingPlain)
+	t.i32(parquetEncodingRLE)
+	t.fieldListBegin(3, thriftTypeBinary, 1)
+	t.string(c.name)
+	t.fieldI32(4, parquetCodecUncompressed)
+	t.fieldI64(5, rowCount)
+	t.fieldI64(6, chunk.size)
+	t.fieldI64(7, chunk.size)
+	t.fieldI64(9, chunk.offset)
+}
+
+// types of the Thrift compact protocol
+const (
+	thriftTypeBooleanTrue  byte = 1
+	thriftTypeBooleanFalse byte = 2
+	thriftTypeI32          byte = 5
+	thriftTypeI64          byte = 6
+	thriftTypeBinary       byte = 8
+	thriftTypeList         byte = 9
+	thriftTypeStruct       byte = 12
+)
+
+// thriftWriter writes structs in the Thrift compact protocol which is used
+// by metadata of Parquet; fields must be written in ascending order of IDs
+type thriftWriter struct {
+	buf bytes.Buffer
+	// lastFieldID is the ID of the last field of the current struct
+	lastFieldID int16
+	// parentFieldIDs are IDs of the last fields of the parents of the
+	// current struct
+	parentFieldIDs []int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	delta := id - t.lastFieldID
+	if delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.varint(int64(id))
+	}
+	t.lastFieldID = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	// zigzag encoding
+	t.buf.Write(binary.AppendUvarint(nil, uint64((v<<1)^(v>>63))))
+}
+
+func (t *thriftWriter) i32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) string(s string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) fieldI32(id int16, v int32) {
+	t.fieldHeader(id, thriftTypeI32)
+	t.i32(v)
+}
+
+func (t *thriftWriter) fieldI64(id int16, v int64) {
+	t.fieldHeader(id, thriftTypeI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) fieldString(id int16, s string) {
+	t.fieldHeader(id, thriftTypeBinary)
+	t.string(s)
+}
+
+func (t *thriftWriter) fieldBool(id int16, v bool) {
+	if v {
+		t.fieldHeader(id, thriftTypeBooleanTrue)
+	} else {
+		t.fieldHeader(id, thriftTypeBooleanFalse)
+	}
+}
+
+// fieldListBegin writes the header of a list; elements are written after
+// it without field headers
+func (t *thriftWriter) fieldListBegin(id int16, elementType byte, size int) {
+	t.fieldHeader(id, thriftTypeList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	t.buf.WriteByte(0xF0 | elementType)
+	t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+}
+
+// fieldStructBegin writes the header of a struct field; fields of the
+// struct are written until structEnd
+func (t *thriftWriter) fieldStructBegin(id int16) {
+	t.fieldHeader(id, thriftTypeStruct)
+	t.structBegin()
+}
+
+// structBegin begins a struct which is an element of a list or a field
+func (t *thriftWriter) structBegin() {
+	t.parentFieldIDs = append(t.parentFieldIDs, t.lastFieldID)
+	t.lastFieldID = 0
+}
+
+func (t *thriftWriter) structEnd() {
+	t.stop()
+	t.lastFieldID = t.parentFieldIDs[len(t.parentFieldIDs)-1]
+	t.parentFieldIDs = t.parentFieldIDs[:len(t.parentFieldIDs)-1]
+}
+
+// stop ends the fields of a struct
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
@@ -0,0 +1,504 @@
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// thriftReader reads structs of the Thrift compact protocol into maps of
+// field IDs for verifying files of Parquet
+type thriftReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.offset]
+	r.offset++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.offset:])
+	r.offset += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(fieldType byte) interface{} {
+	switch fieldType {
+	case thriftTypeBooleanTrue:
+		return true
+	case thriftTypeBooleanFalse:
+		return false
+	case thriftTypeI32, thriftTypeI64:
+		return r.varint()
+	case thriftTypeBinary:
+		n := int(r.uvarint())
+		s := string(r.data[r.offset : r.offset+n])
+		r.offset += n
+		return s
+	case thriftTypeList:
+		header := r.byte()
+		size, elementType := int(header>>4), header&0x0F
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(elementType)
+		}
+		return list
+	case thriftTypeStruct:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unsupported type %d", fieldType))
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var id int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(header & 0x0F)
+	}
+}
+
+// parquetFile is the content of a file of Parquet written by
+// parquetExporter
+type parquetFile struct {
+	schema   []map[int16]interface{}
+	rowCount int64
+	// columns contains values of columns of all row groups
+	columns   map[string][]interface{}
+	rowGroups int
+}
+
+func readParquetFile(t *testing.T, data []byte) *parquetFile {
+	t.Helper()
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatalf("file does not start and end with %s", parquetMagic)
+	}
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &thriftReader{data: data[len(data)-8-footerLength : len(data)-8]}
+	metadata := footer.readStruct()
+	if footer.offset != footerLength {
+		t.Fatalf("footer has %d bytes but %d bytes are read", footerLength, footer.offset)
+	}
+
+	file := &parquetFile{rowCount: metadata[3].(int64), columns: map[string][]interface{}{}}
+	for _, s := range metadata[2].([]interface{}) {
+		file.schema = append(file.schema, s.(map[int16]interface{}))
+	}
+	for _, g := range metadata[4].([]interface{}) {
+		group := g.(map[int16]interface{})
+		file.rowGroups++
+		var size int64
+		for _, c := range group[1].([]interface{}) {
+			columnMetadata := c.(map[int16]interface{})[3].(map[int16]interface{})
+			name := columnMetadata[3].([]interface{})[0].(string)
+			values := readParquetPage(t, data, columnMetadata)
+			if int64(len(values)) != group[3].(int64) {
+				t.Fatalf("column %s has %d values in row group of %d rows", name, len(values), group[3])
+			}
+			file.columns[name] = append(file.columns[name], values...)
+			size += columnMetadata[7].(int64)
+		}
+		if size != group[2].(int64) {
+			t.Errorf("row group has %d bytes, want %d", group[2], size)
+		}
+	}
+	return file
+}
+
+func readParquetPage(t *testing.T, data []byte, columnMetadata map[int16]interface{}) []interface{} {
+	t.Helper()
+	offset := int(columnMetadata[9].(int64))
+	r := &thriftReader{data: data, offset: offset}
+	header := r.readStruct()
+	pageSize := int(header[3].(int64))
+	if int64(r.offset-offset+pageSize) != columnMetadata[7].(int64) {
+		t.Fatalf("column chunk has %d bytes, want %d", columnMetadata[7], r.offset-offset+pageSize)
+	}
+	page := data[r.offset : r.offset+pageSize]
+	count := int(header[5].(map[int16]interface{})[1].(int64))
+
+	// definition levels in runs of RLE
+	levelsLength := int(binary.LittleEndian.Uint32(page))
+	levels := &thriftReader{data: page[4 : 4+levelsLength]}
+	var defined []bool
+	for levels.offset < levelsLength {
+		run := int(levels.uvarint())
+		if run&1 != 0 {
+			t.Fatal("bit-packed runs of definition levels are not expected")
+		}
+		value := levels.byte() == 1
+		for i := 0; i < run>>1; i++ {
+			defined = append(defined, value)
+		}
+	}
+	if len(defined) != count {
+		t.Fatalf("%d definition levels for %d values", len(defined), count)
+	}
+
+	plain := page[4+levelsLength:]
+	values := make([]interface{}, count)
+	index := 0
+	for i := range values {
+		if !defined[i] {
+			continue
+		}
+		switch int32(columnMetadata[1].(int64)) {
+		case parquetBoolean:
+			values[i] = plain[index/8]&(1<<(index%8)) != 0
+			index++
+		case parquetInt32:
+			values[i] = int32(binary.LittleEndian.Uint32(plain))
+			plain = plain[4:]
+		case parquetInt64:
+			values[i] = int64(binary.LittleEndian.Uint64(plain))
+			plain = plain[8:]
+		case parquetDouble:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(plain))
+			plain = plain[8:]
+		case parquetByteArray:
+			n := binary.LittleEndian.Uint32(plain)
+			values[i] = string(plain[4 : 4+n])
+			plain = plain[4+n:]
+		}
+	}
+	return values
+}
+
+func TestParquetExporter(t *testing.T) {
+	data := newTestExportTableData()
+	var buf bytes.Buffer
+	if err := ExportTable(data, NewParquetExporter(&buf)); err != nil {
+		t.Fatalf("ExportTable() error: %v", err)
+	}
+	file := readParquetFile(t, buf.Bytes())
+
+	if file.rowCount != 2 || file.rowGroups != 1 {
+		t.Errorf("file has %d rows in %d row groups, want 2 rows in 1 row group", file.rowCount, file.rowGroups)
+	}
+	if file.schema[0][4] != "schema" || file.schema[0][5] != int64(len(data.Columns)) {
+		t.Errorf("root of schema = %v", file.schema[0])
+	}
+
+	string := map[int16]interface{}{1: map[int16]interface{}{}}
+	timestamp := func(adjustedToUTC bool) map[int16]interface{} {
+		return map[int16]interface{}{8: map[int16]interface{}{1: adjustedToUTC, 2: map[int16]interface{}{2: map[int16]interface{}{}}}}
+	}
+	birthday := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+	createdAt := time.Date(2023, 6, 15, 14, 30, 45, 123000000, time.UTC).UnixMicro()
+	updatedAt := time.Date(2023, 6, 15, 6, 30, 45, 0, time.UTC).UnixMicro()
+
+	tests := []struct {
+		physicalType  int32
+		convertedType interface{}
+		logicalType   interface{}
+		value         interface{}
+	}{
+		{parquetInt64, nil, nil, int64(1)},
+		{parquetByteArray, int64(parquetConvertedUTF8), string, "alex, \"the\" dev\nline 2"},
+		{parquetByteArray, int64(parquetConvertedUTF8), string, "12.50"},
+		{parquetBoolean, nil, nil, true},
+		{parquetDouble, nil, nil, 1.5},
+		{parquetByteArray, nil, nil, "\xca\xfe"},
+		{parquetInt32, int64(parquetConvertedDate), map[int16]interface{}{6: map[int16]interface{}{}}, int32(birthday)},
+		{parquetInt64, nil, timestamp(false), createdAt},
+		{parquetInt64, int64(parquetConvertedTimestampMicros), timestamp(true), updatedAt},
+		{parquetByteArray, int64(parquetConvertedUTF8), string, "6F9619FF-8B86-D011-B42D-00C04FC964FF"},
+	}
+
+	for i, tt := range tests {
+		name := data.Columns[i]
+		t.Run(name, func(t *testing.T) {
+			element := file.schema[i+1]
+			if element[4] != name || element[1] != int64(tt.physicalType) || element[3] != int64(parquetRepetitionOptional) {
+				t.Errorf("schema element = %v", element)
+			}
+			if element[6] != tt.convertedType {
+				t.Errorf("converted type = %v, want %v", element[6], tt.convertedType)
+			}
+			if !reflect.DeepEqual(element[10], tt.logicalType) {
+				t.Errorf("logical type = %v, want %v", element[10], tt.logicalType)
+			}
+			values := file.columns[name]
+			if values[0] != tt.value || values[1] != nil {
+				t.Errorf("values = %v, want [%v <nil>]", values, tt.value)
+			}
+		})
+	}
+}
+
+// TestParquetExporterGolden compares the output with testdata so that any
+// change of the bytes of files is reviewed; a regenerated golden file
+// should be read with another implementation of Parquet, such as the reader
+// of github.com/xitongsys/parquet-go, before it is committed.
+func TestParquetExporterGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportTable(newTestExportTableData(), NewParquetExporter(&buf)); err != nil {
+		t.Fatalf("ExportTable() error: %v", err)
+	}
+
+	path := filepath.Join("testdata", "export.parquet")
+	if *updateGolden {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("unable to update golden file: %v", err)
+		}
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read golden file: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("ExportTable() wrote %d bytes which differ from %s of %d bytes", buf.Len(), path, len(expected))
+	}
+}
+
+// TestParquetExporterReference compares the output with
+// testdata/reference.parquet which is written by another implementation of
+// Parquet (see testdata/parquetref) from the same values
+func TestParquetExporterReference(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportTable(newTestExportTableData(), NewParquetExporter(&buf)); err != nil {
+		t.Fatalf("ExportTable() error: %v", err)
+	}
+	reference, err := os.ReadFile(filepath.Join("testdata", "reference.parquet"))
+	if err != nil {
+		t.Fatalf("unable to read reference file: %v", err)
+	}
+
+	actual := readParquetFile(t, buf.Bytes())
+	expected := readParquetFile(t, reference)
+	if actual.rowCount != expected.rowCount {
+		t.Errorf("row count = %d, want %d", actual.rowCount, expected.rowCount)
+	}
+	if len(actual.schema) != len(expected.schema) {
+		t.Fatalf("schema has %d elements, want %d", len(actual.schema), len(expected.schema))
+	}
+	// the root is skipped as its name differs between implementations;
+	// type, repetition type, name, number of children, converted type and
+	// logical type are compared
+	for i := 1; i < len(expected.schema); i++ {
+		for _, id := range []int16{1, 3, 4, 5, 6, 10} {
+			if !reflect.DeepEqual(actual.schema[i][id], expected.schema[i][id]) {
+				t.Errorf("field %d of schema element %v = %v, want %v", id, expected.schema[i][4], actual.schema[i][id], expected.schema[i][id])
+			}
+		}
+	}
+	if !reflect.DeepEqual(actual.columns, expected.columns) {
+		t.Errorf("columns = %v, want %v", actual.columns, expected.columns)
+	}
+}
+
+func TestParquetExporterRowGroups(t *testing.T) {
+	data := &TableData{Columns: []string{"n", "even", "name", "time"}}
+	var expected []interface{}
+	for i := 0; i < 21; i++ {
+		var n, even, name, tm interface{} = int64(i), i%2 == 0, fmt.Sprint(i), time.Date(2023, 6, 15, 0, 0, i, 0, time.UTC)
+		if i%3 == 0 {
+			n = nil
+		}
+		data.Rows = append(data.Rows, []interface{}{&n, &even, &name, &tm})
+		expected = append(expected, n)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportTable(data, NewParquetExporter(&buf, WithRowGroupSize(10))); err != nil {
+		t.Fatalf("ExportTable() error: %v", err)
+	}
+	file := readParquetFile(t, buf.Bytes())
+
+	if file.rowCount != 21 || file.rowGroups != 3 {
+		t.Errorf("file has %d rows in %d row groups, want 21 rows in 3 row groups", file.rowCount, file.rowGroups)
+	}
+	// types are inferred from values
+	types := []int32{parquetInt64, parquetBoolean, parquetByteArray, parquetInt64}
+	for i, typ := range types {
+		if file.schema[i+1][1] != int64(typ) {
+			t.Errorf("type of column %s = %v, want %d", data.Columns[i], file.schema[i+1][1], typ)
+		}
+	}
+	if !reflect.DeepEqual(file.columns["n"], expected) {
+		t.Errorf("values of n = %v, want %v", file.columns["n"], expected)
+	}
+	for i, v := range file.columns["even"] {
+		if v != (i%2 == 0) {
+			t.Errorf("value %d of even = %v", i, v)
+		}
+	}
+	if file.columns["name"][20] != "20" || file.columns["time"][20] != time.Date(2023, 6, 15, 0, 0, 20, 0, time.UTC).UnixMicro() {
+		t.Errorf("last row = %v, %v", file.columns["name"][20], file.columns["time"][20])
+	}
+}
+
+func TestParquetExporterTimeOfDay(t *testing.T) {
+	var tm interface{} = time.Date(1, 1, 1, 14, 30, 45, 123456000, time.UTC)
+	var text interface{} = "14:30:45.123456"
+	var zoned interface{} = "14:30:45+08"
+	data := &TableData{
+		Columns: []string{"time", "text", "zoned"},
+		ColumnTypes: []ColumnType{
+			{DatabaseTypeName: "TIME"},
+			{DatabaseTypeName: "TIME"},
+			{DatabaseTypeName: "TIMETZ"},
+		},
+		Rows: [][]interface{}{{&tm, &text, &zoned}},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportTable(data, NewParquetExporter(&buf)); err != nil {
+		t.Fatalf("ExportTable() error: %v", err)
+	}
+	file := readParquetFile(t, buf.Bytes())
+
+	expected := int64(14*3600+30*60+45)*1000000 + 123456
+	if file.columns["time"][0] != expected || file.columns["text"][0] != expected {
+		t.Errorf("values = %v, %v, want %d", file.columns["time"][0], file.columns["text"][0], expected)
+	}
+	if file.columns["zoned"][0] != int64(6*3600+30*60+45)*1000000 {
+		t.Errorf("value of time with time zone = %v", file.columns["zoned"][0])
+	}
+}
+
+func TestParquetExporterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	data := &TableData{Columns: []string{"a"}}
+	if err := ExportTable(data, NewParquetExporter(&buf)); err != nil {
+		t.Fatalf("ExportTable() error: %v", err)
+	}
+	file := readParquetFile(t, buf.Bytes())
+	if file.rowCount != 0 || file.rowGroups != 0 || file.schema[1][1] != int64(parquetByteArray) {
+		t.Errorf("file = %+v", file)
+	}
+}
+
+func TestParquetExporterErrors(t *testing.T) {
+	var text interface{} = "text"
+	var number interface{} = int64(1)
+
+	tests := []struct {
+		name string
+		data *TableData
+		opts []ExportOption
+	}{
+		{"no column", &TableData{}, nil},
+		{"empty column name", &TableData{Columns: []string{"a", ""}}, nil},
+		{"duplicated column", &TableData{Columns: []string{"a", "a"}}, nil},
+		{"invalid row group size", &TableData{Columns: []string{"a"}}, []ExportOption{WithRowGroupSize(0)}},
+		{"mismatched type", &TableData{
+			Columns:     []string{"a"},
+			ColumnTypes: []ColumnType{{DatabaseTypeName: "INT"}},
+			Rows:        [][]interface{}{{&text}},
+		}, nil},
+		{"mismatched boolean", &TableData{
+			Columns:     []string{"a"},
+			ColumnTypes: []ColumnType{{DatabaseTypeName: "BIT"}},
+			Rows:        [][]interface{}{{&number}},
+		}, nil},
+		{"mismatched inferred type", &TableData{
+			Columns: []string{"a"},
+			Rows:    [][]interface{}{{&number}, {&text}},
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ExportTable(tt.data, NewParquetExporter(&bytes.Buffer{}, tt.opts...)); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestParquetExporterWriteError(t *testing.T) {
+	errWrite := errors.New("disk full")
+	w := &failingWriter{err: errWrite}
+	var val interface{} = "a"
+	data := &TableData{Columns: []string{"a"}, Rows: [][]interface{}{{&val}}}
+	if err := ExportTable(data, NewParquetExporter(w)); !errors.Is(err, errWrite) {
+		t.Errorf("ExportTable() error = %v, want %v", err, errWrite)
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestToParquetI32(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		expected int32
+		wantErr  bool
+	}{
+		{"zero", 0, 0, false},
+		{"maximum", math.MaxInt32, math.MaxInt32, false},
+		{"overflow", math.MaxInt32 + 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := toParquetI32(tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toParquetI32() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if actual != tt.expected {
+				t.Errorf("toParquetI32() = %d, want %d", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestThriftWriterLongList(t *testing.T) {
+	w := &thriftWriter{}
+	w.fieldListBegin(1, thriftTypeI32, 20)
+	for i := 0; i < 20; i++ {
+		w.i32(int32(i - 10))
+	}
+	w.fieldI32(100, -1)
+	w.stop()
+
+	fields := (&thriftReader{data: w.buf.Bytes()}).readStruct()
+	list := fields[1].([]interface{})
+	if len(list) != 20 || list[0] != int64(-10) || list[19] != int64(9) || fields[100] != int64(-1) {
+		t.Errorf("readStruct() = %v", fields)
+	}
+}
+
+func BenchmarkParquetExporter(b *testing.B) {
+	data := newTestExportTableData()
+	for i := 0; i < b.N; i++ {
+		exporter := NewParquetExporter(&bytes.Buffer{})
+		_ = ExportTable(data, exporter)
+	}
+}
@@ -0,0 +1,214 @@
+package database
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLDialect is a dialect of SQL statements
+type SQLDialect string
+
+const (
+	SQLDialectSQLServer SQLDialect = "sqlserver"
+	SQLDialectPostgres  SQLDialect = "postgres"
+)
+
+// maxSQLServerInsertRows is the maximum number of rows of a VALUES clause
+// in SQL Server
+const maxSQLServerInsertRows = 1000
+
+type sqlInsertExporter struct {
+	exportHeader
+	options   *ExportOptions
+	writer    io.Writer
+	tableName string
+	dialect   SQLDialect
+	prefix    string
+	rows      []string
+}
+
+// NewSQLInsertExporter returns an exporter of INSERT statements of
+// tableName in dialect; tableName can be qualified by a schema such as
+// dbo.users. See WithInsertBatchSize for the number of rows of each
+// statement.
+func NewSQLInsertExporter(w io.Writer, tableName string, dialect SQLDialect, opts ...ExportOption) Exporter {
+	return &sqlInsertExporter{
+		options:   newExportOptions(opts),
+		writer:    w,
+		tableName: tableName,
+		dialect:   dialect,
+	}
+}
+
+func (e *sqlInsertExporter) WriteHeader(columns []string, columnTypes []ColumnType) error {
+	if e.dialect != SQLDialectSQLServer && e.dialect != SQLDialectPostgres {
+		return fmt.Errorf("unsupported SQL dialect [%s]", e.dialect)
+	}
+	if e.tableName == "" {
+		return fmt.Errorf("table name is not set")
+	}
+	if e.options.batchSize < 1 || (e.dialect == SQLDialectSQLServer && e.options.batchSize > maxSQLServerInsertRows) {
+		return fmt.Errorf("invalid batch size of INSERT statements: %d", e.options.batchSize)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("no column to be inserted")
+	}
+	if err := e.setHeader(columns, columnTypes); err != nil {
+		return err
+	}
+
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = e.quoteIdentifier(c)
+	}
+	parts := strings.Split(e.tableName, ".")
+	for i, p := range parts {
+		parts[i] = e.quoteIdentifier(p)
+	}
+	e.prefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", strings.Join(parts, "."), strings.Join(names, ", "))
+	return nil
+}
+
+func (e *sqlInsertExporter) WriteRow(row []interface{}) error {
+	values, err := e.values(row)
+	if err != nil {
+		return err
+	}
+	literals := make([]string, len(values))
+	for i, v := range values {
+		literal, err := e.literal(v, columnTypeAt(e.columnTypes, i))
+		if err != nil {
+			return fmt.Errorf("unable to convert value of column %s: %w", e.columns[i], err)
+		}
+		literals[i] = literal
+	}
+	e.rows = append(e.rows, "("+strings.Join(literals, ", ")+")")
+	if len(e.rows) < e.options.batchSize {
+		return nil
+	}
+	return e.flush()
+}
+
+func (e *sqlInsertExporter) Close() error {
+	return e.flush()
+}
+
+func (e *sqlInsertExporter) flush() error {
+	if len(e.rows) == 0 {
+		return nil
+	}
+	statement := e.prefix + strings.Join(e.rows, ",\n") + ";\n"
+	e.rows = e.rows[:0]
+	_, err := io.WriteString(e.writer, statement)
+	return err
+}
+
+func (e *sqlInsertExporter) quoteIdentifier(name string) string {
+	if e.dialect == SQLDialectSQLServer {
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (e *sqlInsertExporter) quoteString(s string) string {
+	quoted := "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	if e.dialect == SQLDialectSQLServer {
+		// Unicode strings are not converted to the code page of the database
+		return "N" + quoted
+	}
+	return quoted
+}
+
+// literal returns a value returned by exportValue as a literal of SQL
+func (e *sqlInsertExporter) literal(value interface{}, columnType *ColumnType) (string, error) {
+	isSQLServer := e.dialect == SQLDialectSQLServer
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		switch {
+		case isSQLServer && v:
+			return "1", nil
+		case isSQLServer:
+			return "0", nil
+		case v:
+			return "TRUE", nil
+		default:
+			return "FALSE", nil
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return e.floatLiteral(float64(v), 32)
+	case float64:
+		return e.floatLiteral(v, 64)
+	case []byte:
+		if isSQLServer {
+			return "0x" + strings.ToUpper(hex.EncodeToString(v)), nil
+		}
+		return `'\x` + hex.EncodeToString(v) + "'", nil
+	case time.Time:
+		return "'" + e.timeLiteral(v, columnType) + "'", nil
+	case string:
+		if isNumericKind(columnKind(columnType)) && isNumberLiteral(v) {
+			return v, nil
+		}
+		return e.quoteString(v), nil
+	default:
+		return e.quoteString(fmt.Sprint(v)), nil
+	}
+}
+
+func (e *sqlInsertExporter) floatLiteral(f float64, bitSize int) (string, error) {
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, bitSize), nil
+	}
+	if e.dialect == SQLDialectSQLServer {
+		return "", fmt.Errorf("%v is not supported by SQL Server", f)
+	}
+	switch {
+	case math.IsNaN(f):
+		return "'NaN'", nil
+	case f > 0:
+		return "'Infinity'", nil
+	default:
+		return "'-Infinity'", nil
+	}
+}
+
+// timeLiteral formats time in the precisions of the dialect; ISO 8601 is
+// used for SQL Server as other formats of DATETIME depend on languages
+func (e *sqlInsertExporter) timeLiteral(t time.Time, columnType *ColumnType) string {
+	isSQLServer := e.dialect == SQLDialectSQLServer
+	switch columnKind(columnType) {
+	case ColumnKindDate:
+		return t.Format(time.DateOnly)
+	case ColumnKindTime:
+		layout := "15:04:05.999999"
+		if isSQLServer {
+			layout = "15:04:05.9999999"
+		}
+		if hasTimeZone(columnType) {
+			layout += "-07:00"
+		}
+		return t.Format(layout)
+	}
+
+	switch {
+	case isSQLServer && hasTimeZone(columnType):
+		return t.Format("2006-01-02T15:04:05.9999999-07:00")
+	case isSQLServer && columnType != nil && (columnType.DatabaseTypeName == "DATETIME" || columnType.DatabaseTypeName == "SMALLDATETIME"):
+		return t.Format("2006-01-02T15:04:05.999")
+	case isSQLServer:
+		return t.Format("2006-01-02T15:04:05.9999999")
+	case hasTimeZone(columnType):
+		return t.Format("2006-01-02 15:04:05.999999-07:00")
+	default:
+		return t.Format("2006-01-02 15:04:05.999999")
+	}
+}
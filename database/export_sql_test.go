@@ -0,0 +1,157 @@
+package database
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSQLInsertExporter(t *testing.T) {
+	data := newTestExportTableData()
+
+	tests := []struct {
+		name      string
+		tableName string
+		dialect   SQLDialect
+		expected  string
+	}{
+		{
+			name:      "SQL Server",
+			tableName: "dbo.users",
+			dialect:   SQLDialectSQLServer,
+			expected: "INSERT INTO [dbo].[users] ([id], [name], [price], [active], [score], [hash], [birthday], [created_at], [updated_at], [guid]) VALUES\n" +
+				"(1, N'alex, \"the\" dev\nline 2', 12.50, 1, 1.5, 0xCAFE, '2023-06-15', '2023-06-15T14:30:45.123', '2023-06-15T14:30:45+08:00', N'6F9619FF-8B86-D011-B42D-00C04FC964FF'),\n" +
+				"(NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL);\n",
+		},
+		{
+			name:      "PostgreSQL",
+			tableName: "users",
+			dialect:   SQLDialectPostgres,
+			expected: "INSERT INTO \"users\" (\"id\", \"name\", \"price\", \"active\", \"score\", \"hash\", \"birthday\", \"created_at\", \"updated_at\", \"guid\") VALUES\n" +
+				"(1, 'alex, \"the\" dev\nline 2', 12.50, TRUE, 1.5, '\\xcafe', '2023-06-15', '2023-06-15 14:30:45.123', '2023-06-15 14:30:45+08:00', '6F9619FF-8B86-D011-B42D-00C04FC964FF'),\n" +
+				"(NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL);\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := exportToString(t, data, func(w *bytes.Buffer) Exporter {
+				return NewSQLInsertExporter(w, tt.tableName, tt.dialect)
+			})
+			if output != tt.expected {
+				t.Errorf("NewSQLInsertExporter() output =\n%s\nwant\n%s", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSQLInsertExporterBatchSize(t *testing.T) {
+	data := &TableData{Columns: []string{"n"}}
+	for i := 0; i < 5; i++ {
+		var val interface{} = int64(i)
+		data.Rows = append(data.Rows, []interface{}{&val})
+	}
+
+	output := exportToString(t, data, func(w *bytes.Buffer) Exporter {
+		return NewSQLInsertExporter(w, "numbers", SQLDialectPostgres, WithInsertBatchSize(2))
+	})
+
+	expected := "INSERT INTO \"numbers\" (\"n\") VALUES\n(0),\n(1);\n" +
+		"INSERT INTO \"numbers\" (\"n\") VALUES\n(2),\n(3);\n" +
+		"INSERT INTO \"numbers\" (\"n\") VALUES\n(4);\n"
+	if output != expected {
+		t.Errorf("NewSQLInsertExporter() output =\n%s\nwant\n%s", output, expected)
+	}
+
+	empty := exportToString(t, &TableData{Columns: []string{"n"}}, func(w *bytes.Buffer) Exporter {
+		return NewSQLInsertExporter(w, "numbers", SQLDialectPostgres)
+	})
+	if empty != "" {
+		t.Errorf("NewSQLInsertExporter() output of no rows = %q, want empty", empty)
+	}
+}
+
+func TestSQLInsertExporterLiterals(t *testing.T) {
+	dateTime := time.Date(2023, 6, 15, 14, 30, 45, 123456789, time.UTC)
+
+	tests := []struct {
+		name       string
+		value      interface{}
+		columnType *ColumnType
+		sqlServer  string
+		postgres   string
+	}{
+		{"quote", "it's", nil, "N'it''s'", "'it''s'"},
+		{"identifier-like", "]\"", nil, "N']\"'", "']\"'"},
+		{"number in text column", "007", &ColumnType{DatabaseTypeName: "VARCHAR"}, "N'007'", "'007'"},
+		{"invalid number in decimal column", "NaN", &ColumnType{DatabaseTypeName: "NUMERIC"}, "N'NaN'", "'NaN'"},
+		{"int", 42, nil, "42", "42"},
+		{"uint", uint8(7), nil, "7", "7"},
+		{"float32", float32(0.1), nil, "0.1", "0.1"},
+		{"negative infinity", math.Inf(-1), nil, "", "'-Infinity'"},
+		{"false", false, nil, "0", "FALSE"},
+		{"empty binary", []byte{}, &ColumnType{DatabaseTypeName: "BYTEA"}, "0x", "'\\x'"},
+		{"time", dateTime, &ColumnType{DatabaseTypeName: "TIME"}, "'14:30:45.1234567'", "'14:30:45.123456'"},
+		{"time with time zone", dateTime, &ColumnType{DatabaseTypeName: "TIMETZ"}, "'14:30:45.1234567+00:00'", "'14:30:45.123456+00:00'"},
+		{"datetime", dateTime, &ColumnType{DatabaseTypeName: "DATETIME"}, "'2023-06-15T14:30:45.123'", "'2023-06-15 14:30:45.123456'"},
+		{"datetime2", dateTime, &ColumnType{DatabaseTypeName: "DATETIME2"}, "'2023-06-15T14:30:45.1234567'", "'2023-06-15 14:30:45.123456'"},
+		{"unknown time", dateTime, nil, "'2023-06-15T14:30:45.1234567'", "'2023-06-15 14:30:45.123456'"},
+		{"other", struct{ A int }{1}, nil, "N'{1}'", "'{1}'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for dialect, expected := range map[SQLDialect]string{SQLDialectSQLServer: tt.sqlServer, SQLDialectPostgres: tt.postgres} {
+				e := &sqlInsertExporter{dialect: dialect}
+				literal, err := e.literal(tt.value, tt.columnType)
+				if expected == "" {
+					if err == nil {
+						t.Errorf("literal() in %s should return error but got %s", dialect, literal)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("literal() in %s error: %v", dialect, err)
+				}
+				if literal != expected {
+					t.Errorf("literal() in %s = %s, want %s", dialect, literal, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLInsertExporterErrors(t *testing.T) {
+	var inf interface{} = math.Inf(1)
+	data := &TableData{Columns: []string{"n"}, Rows: [][]interface{}{{&inf}}}
+
+	tests := []struct {
+		name     string
+		data     *TableData
+		exporter Exporter
+	}{
+		{"unsupported dialect", data, NewSQLInsertExporter(&bytes.Buffer{}, "numbers", "oracle")},
+		{"no table name", data, NewSQLInsertExporter(&bytes.Buffer{}, "", SQLDialectPostgres)},
+		{"no column", &TableData{}, NewSQLInsertExporter(&bytes.Buffer{}, "numbers", SQLDialectPostgres)},
+		{"zero batch size", data, NewSQLInsertExporter(&bytes.Buffer{}, "numbers", SQLDialectPostgres, WithInsertBatchSize(0))},
+		{"batch size exceeding SQL Server limit", data, NewSQLInsertExporter(&bytes.Buffer{}, "numbers", SQLDialectSQLServer, WithInsertBatchSize(1001))},
+		{"infinity in SQL Server", data, NewSQLInsertExporter(&bytes.Buffer{}, "numbers", SQLDialectSQLServer)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ExportTable(tt.data, tt.exporter); err == nil {
+				t.Error("expected error but got nil")
+			}
+		})
+	}
+}
+
+func BenchmarkSQLInsertExporter(b *testing.B) {
+	data := newTestExportTableData()
+	for i := 0; i < b.N; i++ {
+		exporter := NewSQLInsertExporter(&bytes.Buffer{}, "users", SQLDialectSQLServer)
+		_ = ExportTable(data, exporter)
+	}
+}
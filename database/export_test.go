@@ -0,0 +1,322 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// newTestExportTableData returns a table with a value of each kind of
+// columns and a row of NULL values
+func newTestExportTableData() *TableData {
+	hongKong := time.FixedZone("HKT", 8*60*60)
+	values := []interface{}{
+		int64(1),
+		"alex, \"the\" dev\nline 2",
+		[]byte("12.50"),
+		true,
+		1.5,
+		[]byte{0xCA, 0xFE},
+		time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 6, 15, 14, 30, 45, 123000000, time.UTC),
+		time.Date(2023, 6, 15, 14, 30, 45, 0, hongKong),
+		[]byte{0xFF, 0x19, 0x96, 0x6F, 0x86, 0x8B, 0x11, 0xD0, 0xB4, 0x2D, 0x00, 0xC0, 0x4F, 0xC9, 0x64, 0xFF},
+	}
+	row := make([]interface{}, len(values))
+	nulls := make([]interface{}, len(values))
+	for i := range values {
+		row[i] = &values[i]
+		nulls[i] = new(interface{})
+	}
+	return &TableData{
+		Columns: []string{"id", "name", "price", "active", "score", "hash", "birthday", "created_at", "updated_at", "guid"},
+		ColumnTypes: []ColumnType{
+			{Name: "id", DatabaseTypeName: "BIGINT"},
+			{Name: "name", DatabaseTypeName: "NVARCHAR"},
+			{Name: "price", DatabaseTypeName: "DECIMAL"},
+			{Name: "active", DatabaseTypeName: "BIT"},
+			{Name: "score", DatabaseTypeName: "FLOAT"},
+			{Name: "hash", DatabaseTypeName: "VARBINARY"},
+			{Name: "birthday", DatabaseTypeName: "DATE"},
+			{Name: "created_at", DatabaseTypeName: "DATETIME2"},
+			{Name: "updated_at", DatabaseTypeName: "DATETIMEOFFSET"},
+			{Name: "guid", DatabaseTypeName: "UNIQUEIDENTIFIER"},
+		},
+		Rows: [][]interface{}{row, nulls},
+	}
+}
+
+func exportToString(t *testing.T, data *TableData, exporter func(w *bytes.Buffer) Exporter) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := ExportTable(data, exporter(&buf)); err != nil {
+		t.Fatalf("ExportTable() error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestCSVExporter(t *testing.T) {
+	data := newTestExportTableData()
+
+	tests := []struct {
+		name     string
+		opts     []ExportOption
+		expected string
+	}{
+		{
+			name: "default",
+			expected: "id,name,price,active,score,hash,birthday,created_at,updated_at,guid\n" +
+				"1,\"alex, \"\"the\"\" dev\nline 2\",12.50,true,1.5,0xCAFE,2023-06-15,2023-06-15T14:30:45.123,2023-06-15T14:30:45+08:00,6F9619FF-8B86-D011-B42D-00C04FC964FF\n" +
+				",,,,,,,,,\n",
+		},
+		{
+			name: "dialect",
+			opts: []ExportOption{WithDelimiter(';'), WithCRLF(), WithoutHeader(), WithNullString("NULL"), WithBinaryFormat(BinaryFormatBase64), WithTimeFormat(time.DateTime)},
+			expected: "1;\"alex, \"\"the\"\" dev\r\nline 2\";12.50;true;1.5;yv4=;2023-06-15;2023-06-15 14:30:45;2023-06-15 14:30:45;6F9619FF-8B86-D011-B42D-00C04FC964FF\r\n" +
+				"NULL;NULL;NULL;NULL;NULL;NULL;NULL;NULL;NULL;NULL\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := exportToString(t, data, func(w *bytes.Buffer) Exporter { return NewCSVExporter(w, tt.opts...) })
+			if output != tt.expected {
+				t.Errorf("NewCSVExporter() output =\n%s\nwant\n%s", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCSVExporterWithoutColumnTypes(t *testing.T) {
+	var val1 interface{} = []byte("text")
+	var val2 interface{} = time.Date(2023, 6, 15, 14, 30, 45, 0, time.UTC)
+	data := &TableData{
+		Columns: []string{"a", "b", "c"},
+		Rows:    [][]interface{}{{&val1, &val2, 42}},
+	}
+
+	output := exportToString(t, data, func(w *bytes.Buffer) Exporter { return NewCSVExporter(w) })
+
+	expected := "a,b,c\ntext,2023-06-15T14:30:45Z,42\n"
+	if output != expected {
+		t.Errorf("NewCSVExporter() output = %q, want %q", output, expected)
+	}
+}
+
+func TestJSONLinesExporter(t *testing.T) {
+	data := newTestExportTableData()
+
+	output := exportToString(t, data, func(w *bytes.Buffer) Exporter { return NewJSONLinesExporter(w) })
+
+	expected := `{"id":1,"name":"alex, \"the\" dev\nline 2","price":12.50,"active":true,"score":1.5,"hash":"0xCAFE","birthday":"2023-06-15","created_at":"2023-06-15T14:30:45.123","updated_at":"2023-06-15T14:30:45+08:00","guid":"6F9619FF-8B86-D011-B42D-00C04FC964FF"}` + "\n" +
+		`{"id":null,"name":null,"price":null,"active":null,"score":null,"hash":null,"birthday":null,"created_at":null,"updated_at":null,"guid":null}` + "\n"
+	if output != expected {
+		t.Errorf("NewJSONLinesExporter() output =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestJSONLinesExporterSpecialValues(t *testing.T) {
+	var nan interface{} = math.NaN()
+	var decimal interface{} = "NaN"
+	data := &TableData{
+		Columns:     []string{"float", "decimal"},
+		ColumnTypes: []ColumnType{{DatabaseTypeName: "FLOAT8"}, {DatabaseTypeName: "NUMERIC"}},
+		Rows:        [][]interface{}{{&nan, &decimal}},
+	}
+
+	output := exportToString(t, data, func(w *bytes.Buffer) Exporter { return NewJSONLinesExporter(w) })
+
+	expected := `{"float":"NaN","decimal":"NaN"}` + "\n"
+	if output != expected {
+		t.Errorf("NewJSONLinesExporter() output = %q, want %q", output, expected)
+	}
+}
+
+func TestMarkdownExporter(t *testing.T) {
+	data := newTestExportTableData()
+	data.Columns = data.Columns[:4]
+	data.ColumnTypes = data.ColumnTypes[:4]
+	for i, r := range data.Rows {
+		data.Rows[i] = r[:4]
+	}
+
+	output := exportToString(t, data, func(w *bytes.Buffer) Exporter { return NewMarkdownExporter(w, WithNullString("NULL")) })
+
+	expected := "| id | name | price | active |\n" +
+		"| ---: | --- | ---: | --- |\n" +
+		"| 1 | alex, \"the\" dev<br>line 2 | 12.50 | true |\n" +
+		"| NULL | NULL | NULL | NULL |\n"
+	if output != expected {
+		t.Errorf("NewMarkdownExporter() output =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	exporters := map[string]func(w *bytes.Buffer) Exporter{
+		"CSV":        func(w *bytes.Buffer) Exporter { return NewCSVExporter(w) },
+		"JSON Lines": func(w *bytes.Buffer) Exporter { return NewJSONLinesExporter(w) },
+		"Markdown":   func(w *bytes.Buffer) Exporter { return NewMarkdownExporter(w) },
+		"SQL":        func(w *bytes.Buffer) Exporter { return NewSQLInsertExporter(w, "users", SQLDialectPostgres) },
+		"Parquet":    func(w *bytes.Buffer) Exporter { return NewParquetExporter(w) },
+	}
+	var val interface{} = "a"
+
+	for name, newExporter := range exporters {
+		t.Run(name, func(t *testing.T) {
+			mismatchedRow := &TableData{Columns: []string{"a", "b"}, Rows: [][]interface{}{{&val}}}
+			if err := ExportTable(mismatchedRow, newExporter(&bytes.Buffer{})); err == nil {
+				t.Error("ExportTable() of row with missing values should return error")
+			}
+			mismatchedTypes := &TableData{Columns: []string{"a", "b"}, ColumnTypes: []ColumnType{{Name: "a"}}}
+			if err := ExportTable(mismatchedTypes, newExporter(&bytes.Buffer{})); err == nil {
+				t.Error("ExportTable() with missing column types should return error")
+			}
+		})
+	}
+
+	if err := ExportTable(&TableData{Columns: []string{"a"}}, NewCSVExporter(&bytes.Buffer{}, WithDelimiter('"'))); err == nil {
+		t.Error("ExportTable() of CSV with invalid delimiter should return error")
+	}
+}
+
+func TestExportRowReader(t *testing.T) {
+	errBroken := errors.New("connection broken")
+	result := newFakeNumbersResult(3)
+	db := newFakeDB(t, map[string]*fakeResult{
+		fakeQueryNumbers: result,
+		"SELECT broken":  {columns: result.columns, rows: result.rows, err: errBroken},
+	})
+
+	reader, err := GetRowReader(context.Background(), db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := ExportRowReader(reader, NewCSVExporter(&buf)); err != nil {
+		t.Fatalf("ExportRowReader() error: %v", err)
+	}
+	if buf.String() != "n\n0\n1\n2\n" {
+		t.Errorf("ExportRowReader() output = %q", buf.String())
+	}
+
+	reader, err = GetRowReader(context.Background(), db, "SELECT broken")
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+	if err := ExportRowReader(reader, NewJSONLinesExporter(&bytes.Buffer{})); !errors.Is(err, errBroken) {
+		t.Errorf("ExportRowReader() error = %v, want %v", err, errBroken)
+	}
+
+	// the reader is closed if the header cannot be written
+	reader, err = GetRowReader(context.Background(), db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+	if err := ExportRowReader(reader, NewSQLInsertExporter(&bytes.Buffer{}, "", SQLDialectPostgres)); err == nil {
+		t.Error("ExportRowReader() without table name should return error")
+	}
+	if db.Stats().InUse != 0 {
+		t.Errorf("%d connections are in use after export", db.Stats().InUse)
+	}
+}
+
+type closeRecordingExporter struct {
+	Exporter
+	errRow   error
+	errClose error
+	closed   int
+}
+
+func (e *closeRecordingExporter) WriteRow(row []interface{}) error {
+	if e.errRow != nil {
+		return e.errRow
+	}
+	return e.Exporter.WriteRow(row)
+}
+
+func (e *closeRecordingExporter) Close() error {
+	e.closed++
+	return errors.Join(e.Exporter.Close(), e.errClose)
+}
+
+func TestExportClosesExporterOnError(t *testing.T) {
+	errRow := errors.New("row cannot be written")
+	errClose := errors.New("exporter cannot be closed")
+	data := &TableData{Columns: []string{"n"}, Rows: [][]interface{}{{int64(1)}}}
+
+	exporter := &closeRecordingExporter{Exporter: NewCSVExporter(&bytes.Buffer{}), errRow: errRow, errClose: errClose}
+	err := ExportTable(data, exporter)
+	if !errors.Is(err, errRow) || !errors.Is(err, errClose) {
+		t.Errorf("ExportTable() error = %v, want both %v and %v", err, errRow, errClose)
+	}
+	if exporter.closed != 1 {
+		t.Errorf("exporter is closed %d times, want 1", exporter.closed)
+	}
+
+	exporter = &closeRecordingExporter{Exporter: NewCSVExporter(&bytes.Buffer{})}
+	if err := ExportTable(data, exporter); err != nil {
+		t.Errorf("ExportTable() error: %v", err)
+	}
+	if exporter.closed != 1 {
+		t.Errorf("exporter is closed %d times, want 1", exporter.closed)
+	}
+
+	db := newFakeDB(t, map[string]*fakeResult{fakeQueryNumbers: newFakeNumbersResult(3)})
+	reader, err := GetRowReader(context.Background(), db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+	exporter = &closeRecordingExporter{Exporter: NewCSVExporter(&bytes.Buffer{}), errRow: errRow}
+	if err := ExportRowReader(reader, exporter); !errors.Is(err, errRow) {
+		t.Errorf("ExportRowReader() error = %v, want %v", err, errRow)
+	}
+	if exporter.closed != 1 {
+		t.Errorf("exporter is closed %d times, want 1", exporter.closed)
+	}
+	if db.Stats().InUse != 0 {
+		t.Errorf("%d connections are in use after export", db.Stats().InUse)
+	}
+}
+
+func TestExportValue(t *testing.T) {
+	var text interface{} = []byte("text")
+	tests := []struct {
+		name       string
+		cell       interface{}
+		columnType *ColumnType
+		expected   interface{}
+	}{
+		{"pointer", &text, nil, "text"},
+		{"nil pointer", (*interface{})(nil), nil, nil},
+		{"value", int64(1), nil, int64(1)},
+		{"bytes of text column", []byte("text"), &ColumnType{DatabaseTypeName: "VARCHAR"}, "text"},
+		{"uuid of PostgreSQL", make([]byte, 16), &ColumnType{DatabaseTypeName: "UUID"}, "00000000-0000-0000-0000-000000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := exportValue(tt.cell, tt.columnType); actual != tt.expected {
+				t.Errorf("exportValue() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func BenchmarkCSVExporter(b *testing.B) {
+	data := newTestExportTableData()
+	for i := 0; i < b.N; i++ {
+		exporter := NewCSVExporter(&bytes.Buffer{})
+		_ = ExportTable(data, exporter)
+	}
+}
+
+func BenchmarkJSONLinesExporter(b *testing.B) {
+	data := newTestExportTableData()
+	for i := 0; i < b.N; i++ {
+		exporter := NewJSONLinesExporter(&bytes.Buffer{})
+		_ = ExportTable(data, exporter)
+	}
+}
@@ -1,20 +1,29 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/alexhokl/helper/database/sqldata"
 	_ "github.com/denisenkom/go-mssqldb"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
-// TableData struct
-type TableData struct {
-	Rows    [][]interface{}
-	Columns []string
-}
+// postgresCancelDeadlineDelay is the time PostgreSQL has to respond to the
+// cancel request of a cancelled query before its connection is closed
+const postgresCancelDeadlineDelay = 5 * time.Second
+
+// TableData contains rows of a table with their columns; see
+// sqldata.TableData
+type TableData = sqldata.TableData
 
 // GetConnection returns a SQL database connection
 func GetConnection(config *Config) (*sql.DB, error) {
@@ -29,8 +38,20 @@ func GetConnection(config *Config) (*sql.DB, error) {
 	return sql.Open("sqlserver", connectionURL.String())
 }
 
-// GetPostgresConnection returns a PostgreSQL database connection
+// GetPostgresConnection returns a PostgreSQL database connection; queries
+// cancelled by their contexts are also cancelled on the server and
+// statements are aborted by the server after StatementTimeout of config.
+// Unlike sql.Open, the connection string is parsed here, so an invalid
+// configuration is returned as an error rather than by the first query.
 func GetPostgresConnection(config *PostgresConfig) (*sql.DB, error) {
+	connConfig, err := newPostgresConnConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return stdlib.OpenDB(*connConfig), nil
+}
+
+func newPostgresConnConfig(config *PostgresConfig) (*pgx.ConnConfig, error) {
 	parameters := url.Values{}
 	parameters.Add("dbname", config.Name)
 	if !config.UseSSL {
@@ -42,60 +63,57 @@ func GetPostgresConnection(config *PostgresConfig) (*sql.DB, error) {
 		Host:     config.Server,
 		RawQuery: parameters.Encode(),
 	}
-	return sql.Open("pgx", connectionURL.String())
-}
-
-// GetData returns data retrieved by using query with conn
-func GetData(conn *sql.DB, query string) (*TableData, error) {
-	rows, errQuery := conn.Query(query)
-	if errQuery != nil {
-		return nil, errQuery
+	connConfig, err := pgx.ParseConfig(connectionURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse PostgreSQL connection config: %w", err)
 	}
-	defer rows.Close()
+	connConfig.BuildContextWatcherHandler = newPostgresContextWatcherHandler
+	if config.StatementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(config.StatementTimeout.Milliseconds(), 10)
+	}
+	return connConfig, nil
+}
 
-	cols, errColumns := rows.Columns()
-	if errColumns != nil {
-		return nil, errColumns
+// newPostgresContextWatcherHandler sends a cancel request to the server when
+// the context of a query is done; by default pgx only closes the connection
+// and the query keeps running on the server
+func newPostgresContextWatcherHandler(conn *pgconn.PgConn) ctxwatch.Handler {
+	return &pgconn.CancelRequestContextWatcherHandler{
+		Conn:          conn,
+		DeadlineDelay: postgresCancelDeadlineDelay,
 	}
+}
 
-	columnCount := len(cols)
+// GetData returns data retrieved by using query with conn
+func GetData(conn *sql.DB, query string) (*TableData, error) {
+	return GetDataContext(context.Background(), conn, query)
+}
 
-	var dataRows [][]interface{}
-	for rows.Next() {
-		vals := make([]interface{}, columnCount)
-		for i := 0; i < columnCount; i++ {
-			vals[i] = new(interface{})
-		}
-		err := rows.Scan(vals...)
-		if err != nil {
-			return nil, err
-		}
-		dataRows = append(dataRows, vals)
+// GetDataContext returns data retrieved by using query with conn. The query
+// is cancelled when ctx is done, so its timeout is set with the deadline of
+// ctx, such as context.WithTimeout; SQL Server cancels the statement on the
+// server and so does PostgreSQL with connections of GetPostgresConnection.
+// args are bound as parameters of the query, such as @p1 or
+// sql.Named("name", value) with @name for SQL Server and $1 for PostgreSQL.
+func GetDataContext(ctx context.Context, conn *sql.DB, query string, args ...any) (*TableData, error) {
+	reader, err := GetRowReader(ctx, conn, query, args...)
+	if err != nil {
+		return nil, err
 	}
+	return reader.ReadAll()
+}
 
-	data := &TableData{
-		Rows:    dataRows,
-		Columns: cols,
+// contextError ensures errors of cancelled queries wrap the error of ctx as
+// drivers report cancellations differently
+func contextError(ctx context.Context, err error) error {
+	ctxErr := ctx.Err()
+	if ctxErr == nil || errors.Is(err, ctxErr) {
+		return err
 	}
-
-	return data, nil
+	return fmt.Errorf("%w: %w", ctxErr, err)
 }
 
 // GetValue returns a typed value from a cell reference
 func GetValue(pval *interface{}) string {
-	switch v := (*pval).(type) {
-	case nil:
-		return "NULL"
-	case bool:
-		if v {
-			return "1"
-		}
-		return "0"
-	case []byte:
-		return string(v)
-	case time.Time:
-		return v.Format("2006-01-02 15:04:05.999")
-	default:
-		return fmt.Sprint(v)
-	}
+	return sqldata.FormatValue(*pval, nil)
 }
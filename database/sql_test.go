@@ -1,11 +1,167 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// fakeQueryArgs returns its arguments as a row
+	fakeQueryArgs = "SELECT args"
+	// fakeQueryWait blocks until the query is cancelled
+	fakeQueryWait = "WAITFOR DELAY"
 )
 
+// fakeColumn describes a column of fakeResult; length, precision and
+// scale are not reported if they are zero
+type fakeColumn struct {
+	name      string
+	typeName  string
+	nullable  bool
+	length    int64
+	precision int64
+	scale     int64
+	scanType  reflect.Type
+}
+
+// fakeResult is the result of a query of fakeConnector
+type fakeResult struct {
+	columns []fakeColumn
+	rows    [][]driver.Value
+	// err is returned after rows instead of io.EOF
+	err error
+}
+
+// fakeConnector is a database/sql driver with results of queries in memory
+type fakeConnector struct {
+	results map[string]*fakeResult
+}
+
+type fakeConn struct {
+	connector *fakeConnector
+}
+
+type fakeRows struct {
+	ctx     context.Context
+	columns []fakeColumn
+	rows    [][]driver.Value
+	err     error
+	index   int
+}
+
+func newFakeDB(t testing.TB, results map[string]*fakeResult) *sql.DB {
+	t.Helper()
+	db := sql.OpenDB(&fakeConnector{results: results})
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{connector: c}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake: prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fake: transactions are not supported")
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch query {
+	case fakeQueryArgs:
+		columns := make([]fakeColumn, len(args))
+		row := make([]driver.Value, len(args))
+		for i, arg := range args {
+			columns[i] = fakeColumn{name: fmt.Sprintf("p%d", arg.Ordinal)}
+			if arg.Name != "" {
+				columns[i].name = arg.Name
+			}
+			row[i] = arg.Value
+		}
+		return &fakeRows{ctx: ctx, columns: columns, rows: [][]driver.Value{row}}, nil
+	case fakeQueryWait:
+		<-ctx.Done()
+		// drivers do not necessarily wrap errors of contexts
+		return nil, errors.New("fake: query cancelled")
+	}
+	result, ok := c.connector.results[query]
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown query %q", query)
+	}
+	return &fakeRows{ctx: ctx, columns: result.columns, rows: result.rows, err: result.err}, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	names := make([]string, len(r.columns))
+	for i, c := range r.columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.columns[index].typeName
+}
+
+func (r *fakeRows) ColumnTypeNullable(index int) (bool, bool) {
+	return r.columns[index].nullable, true
+}
+
+func (r *fakeRows) ColumnTypeLength(index int) (int64, bool) {
+	return r.columns[index].length, r.columns[index].length > 0
+}
+
+func (r *fakeRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	c := r.columns[index]
+	return c.precision, c.scale, c.precision > 0
+}
+
+func (r *fakeRows) ColumnTypeScanType(index int) reflect.Type {
+	if r.columns[index].scanType == nil {
+		return reflect.TypeFor[any]()
+	}
+	return r.columns[index].scanType
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.ctx.Err() != nil {
+		return errors.New("fake: query cancelled")
+	}
+	if r.index >= len(r.rows) {
+		if r.err != nil {
+			return r.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.rows[r.index])
+	r.index++
+	return nil
+}
+
 func TestTableDataStruct(t *testing.T) {
 	data := TableData{
 		Columns: []string{"id", "name", "created_at"},
@@ -199,6 +355,63 @@ func TestGetPostgresConnectionWithSSL(t *testing.T) {
 	}
 }
 
+func TestGetPostgresConnectionInvalidConfig(t *testing.T) {
+	config := &PostgresConfig{
+		Config: Config{
+			Server: "localhost:invalid-port",
+			Name:   "testdb",
+		},
+	}
+
+	if _, err := GetPostgresConnection(config); err == nil {
+		t.Error("GetPostgresConnection() with invalid port should return error")
+	}
+}
+
+func TestNewPostgresConnConfigStatementTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		expected string
+		ok       bool
+	}{
+		{"without timeout", 0, "", false},
+		{"seconds", 30 * time.Second, "30000", true},
+		{"milliseconds", 1500 * time.Millisecond, "1500", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &PostgresConfig{
+				Config:           Config{Server: "localhost:5432", Name: "testdb"},
+				StatementTimeout: tt.timeout,
+			}
+			connConfig, err := newPostgresConnConfig(config)
+			if err != nil {
+				t.Fatalf("newPostgresConnConfig() error: %v", err)
+			}
+			value, ok := connConfig.RuntimeParams["statement_timeout"]
+			if ok != tt.ok || value != tt.expected {
+				t.Errorf("statement_timeout = %q (set: %v), want %q (set: %v)", value, ok, tt.expected, tt.ok)
+			}
+			if connConfig.BuildContextWatcherHandler == nil {
+				t.Error("BuildContextWatcherHandler is not set")
+			}
+		})
+	}
+}
+
+func TestNewPostgresContextWatcherHandler(t *testing.T) {
+	conn := &pgconn.PgConn{}
+	handler, ok := newPostgresContextWatcherHandler(conn).(*pgconn.CancelRequestContextWatcherHandler)
+	if !ok {
+		t.Fatalf("newPostgresContextWatcherHandler() = %T, want *pgconn.CancelRequestContextWatcherHandler", handler)
+	}
+	if handler.Conn != conn || handler.DeadlineDelay != postgresCancelDeadlineDelay {
+		t.Errorf("newPostgresContextWatcherHandler() = %+v", handler)
+	}
+}
+
 func TestGetPostgresConnectionWithSpecialCharactersInPassword(t *testing.T) {
 	config := &PostgresConfig{
 		Config: Config{
@@ -385,3 +598,94 @@ func TestGetValueTableDriven(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDataContext(t *testing.T) {
+	db := newFakeDB(t, map[string]*fakeResult{
+		"SELECT id, name FROM users": {
+			columns: []fakeColumn{{name: "id"}, {name: "name"}},
+			rows:    [][]driver.Value{{int64(1), "alex"}, {int64(2), nil}},
+		},
+	})
+
+	data, err := GetDataContext(context.Background(), db, "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("GetDataContext() error: %v", err)
+	}
+	if strings.Join(data.Columns, ",") != "id,name" || len(data.Rows) != 2 {
+		t.Fatalf("GetDataContext() = %+v", data)
+	}
+	if GetValue(data.Rows[0][1].(*interface{})) != "alex" || GetValue(data.Rows[1][1].(*interface{})) != "NULL" {
+		t.Errorf("GetDataContext() rows = %v", data.Rows)
+	}
+
+	if _, err := GetData(db, "SELECT unknown"); err == nil {
+		t.Error("GetData() of unknown query should return error")
+	}
+}
+
+func TestGetDataContextArguments(t *testing.T) {
+	db := newFakeDB(t, nil)
+
+	data, err := GetDataContext(context.Background(), db, fakeQueryArgs, 42, sql.Named("name", "alex"))
+	if err != nil {
+		t.Fatalf("GetDataContext() error: %v", err)
+	}
+	if strings.Join(data.Columns, ",") != "p1,name" {
+		t.Errorf("GetDataContext() columns = %v, want [p1 name]", data.Columns)
+	}
+	if GetValue(data.Rows[0][0].(*interface{})) != "42" || GetValue(data.Rows[0][1].(*interface{})) != "alex" {
+		t.Errorf("GetDataContext() rows = %v", data.Rows)
+	}
+}
+
+func TestGetDataContextCancellation(t *testing.T) {
+	db := newFakeDB(t, nil)
+
+	tests := []struct {
+		name     string
+		ctx      func() (context.Context, context.CancelFunc)
+		expected error
+	}{
+		{
+			name: "context timeout",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			expected: context.DeadlineExceeded,
+		},
+		{
+			name: "context cancelled",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			expected: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			_, err := GetDataContext(ctx, db, fakeQueryWait)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("GetDataContext() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func BenchmarkGetDataContext(b *testing.B) {
+	rows := make([][]driver.Value, 1000)
+	for i := range rows {
+		rows[i] = []driver.Value{int64(i), "name"}
+	}
+	db := newFakeDB(b, map[string]*fakeResult{
+		"SELECT": {columns: []fakeColumn{{name: "id"}, {name: "name"}}, rows: rows},
+	})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = GetDataContext(context.Background(), db, "SELECT")
+	}
+}
@@ -0,0 +1,227 @@
+// Package sqldata contains data retrieved from SQL databases, such as
+// TableData and ColumnType, and formats their values; it does not depend on
+// any database drivers so that packages which only present data, such as
+// cli and googleapi, do not link the drivers.
+package sqldata
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TableData contains rows of a table with their columns
+type TableData struct {
+	Rows    [][]interface{}
+	Columns []string
+	// ColumnTypes is in the same order as Columns if data is retrieved from
+	// a database
+	ColumnTypes []ColumnType
+}
+
+// ColumnKind is a category of database types of columns which are
+// formatted in the same way
+type ColumnKind int
+
+const (
+	ColumnKindUnknown ColumnKind = iota
+	ColumnKindBoolean
+	ColumnKindInteger
+	ColumnKindDecimal
+	ColumnKindFloat
+	ColumnKindText
+	ColumnKindBinary
+	ColumnKindDate
+	ColumnKindTime
+	ColumnKindDateTime
+	ColumnKindUUID
+)
+
+// ColumnType contains metadata of a column; fields with a Has prefix
+// indicate whether the preceding fields are reported by the driver
+type ColumnType struct {
+	Name string
+	// DatabaseTypeName is the name of the type in upper case, such as
+	// NVARCHAR, DECIMAL or INT4
+	DatabaseTypeName  string
+	Nullable          bool
+	HasNullable       bool
+	Length            int64
+	HasLength         bool
+	Precision         int64
+	Scale             int64
+	HasPrecisionScale bool
+	// ScanType is the Go type of values of the column
+	ScanType reflect.Type
+}
+
+var columnKinds = map[string]ColumnKind{
+	"BIT":              ColumnKindBoolean,
+	"BOOL":             ColumnKindBoolean,
+	"BOOLEAN":          ColumnKindBoolean,
+	"TINYINT":          ColumnKindInteger,
+	"SMALLINT":         ColumnKindInteger,
+	"INT":              ColumnKindInteger,
+	"INTEGER":          ColumnKindInteger,
+	"BIGINT":           ColumnKindInteger,
+	"INT2":             ColumnKindInteger,
+	"INT4":             ColumnKindInteger,
+	"INT8":             ColumnKindInteger,
+	"OID":              ColumnKindInteger,
+	"DECIMAL":          ColumnKindDecimal,
+	"NUMERIC":          ColumnKindDecimal,
+	"MONEY":            ColumnKindDecimal,
+	"SMALLMONEY":       ColumnKindDecimal,
+	"REAL":             ColumnKindFloat,
+	"FLOAT":            ColumnKindFloat,
+	"FLOAT4":           ColumnKindFloat,
+	"FLOAT8":           ColumnKindFloat,
+	"DOUBLE PRECISION": ColumnKindFloat,
+	"CHAR":             ColumnKindText,
+	"VARCHAR":          ColumnKindText,
+	"NCHAR":            ColumnKindText,
+	"NVARCHAR":         ColumnKindText,
+	"TEXT":             ColumnKindText,
+	"NTEXT":            ColumnKindText,
+	"BPCHAR":           ColumnKindText,
+	"NAME":             ColumnKindText,
+	"CITEXT":           ColumnKindText,
+	"XML":              ColumnKindText,
+	"JSON":             ColumnKindText,
+	"JSONB":            ColumnKindText,
+	"BINARY":           ColumnKindBinary,
+	"VARBINARY":        ColumnKindBinary,
+	"IMAGE":            ColumnKindBinary,
+	"BYTEA":            ColumnKindBinary,
+	"DATE":             ColumnKindDate,
+	"TIME":             ColumnKindTime,
+	"TIMETZ":           ColumnKindTime,
+	"DATETIME":         ColumnKindDateTime,
+	"DATETIME2":        ColumnKindDateTime,
+	"SMALLDATETIME":    ColumnKindDateTime,
+	"DATETIMEOFFSET":   ColumnKindDateTime,
+	"TIMESTAMP":        ColumnKindDateTime,
+	"TIMESTAMPTZ":      ColumnKindDateTime,
+	"UNIQUEIDENTIFIER": ColumnKindUUID,
+	"UUID":             ColumnKindUUID,
+}
+
+var timeZoneTypeNames = map[string]bool{
+	"DATETIMEOFFSET": true,
+	"TIMESTAMPTZ":    true,
+	"TIMETZ":         true,
+}
+
+// NewColumnTypes returns metadata of columns reported by a driver
+func NewColumnTypes(types []*sql.ColumnType) []ColumnType {
+	list := make([]ColumnType, len(types))
+	for i, t := range types {
+		c := &list[i]
+		c.Name = t.Name()
+		c.DatabaseTypeName = strings.ToUpper(t.DatabaseTypeName())
+		c.Nullable, c.HasNullable = t.Nullable()
+		c.Length, c.HasLength = t.Length()
+		c.Precision, c.Scale, c.HasPrecisionScale = t.DecimalSize()
+		c.ScanType = t.ScanType()
+	}
+	return list
+}
+
+// Kind returns the category of the database type of the column
+func (c *ColumnType) Kind() ColumnKind {
+	return columnKinds[c.DatabaseTypeName]
+}
+
+// HasTimeZone returns true if values of the column have time zones, such as
+// DATETIMEOFFSET and TIMESTAMPTZ
+func (c *ColumnType) HasTimeZone() bool {
+	return timeZoneTypeNames[c.DatabaseTypeName]
+}
+
+// FormatValue returns the text of a value of a column; values of binary
+// columns are in hexadecimal, dates have no time and date-times have time
+// zones only if the columns have time zones. NULL, booleans (as 1 and 0),
+// byte slices and times are formatted in the same way regardless of types
+// if columnType is nil.
+func FormatValue(value interface{}, columnType *ColumnType) string {
+	if s, ok := FormatTypedValue(value, columnType); ok {
+		return s
+	}
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// FormatTypedValue formats values whose formats depend on the types of
+// their columns and returns false for other values, such as NULL, or if
+// columnType is nil
+func FormatTypedValue(value interface{}, columnType *ColumnType) (string, bool) {
+	if columnType == nil {
+		return "", false
+	}
+	switch v := value.(type) {
+	case []byte:
+		switch columnType.Kind() {
+		case ColumnKindBinary:
+			return "0x" + strings.ToUpper(hex.EncodeToString(v)), true
+		case ColumnKindUUID:
+			if len(v) == 16 {
+				return formatUUID(v, columnType.DatabaseTypeName == "UNIQUEIDENTIFIER"), true
+			}
+		}
+	case time.Time:
+		switch columnType.Kind() {
+		case ColumnKindDate:
+			return v.Format(time.DateOnly), true
+		case ColumnKindTime:
+			if columnType.HasTimeZone() {
+				return v.Format("15:04:05.999 -07:00"), true
+			}
+			return v.Format("15:04:05.999"), true
+		case ColumnKindDateTime:
+			if columnType.HasTimeZone() {
+				return v.Format("2006-01-02 15:04:05.999 -07:00"), true
+			}
+		}
+	}
+	return "", false
+}
+
+// formatUUID formats 16 bytes as a UUID; SQL Server stores the first three
+// groups of UNIQUEIDENTIFIER in little-endian and shows them in upper case
+func formatUUID(b []byte, isSQLServer bool) string {
+	if isSQLServer {
+		return fmt.Sprintf("%08X-%04X-%04X-%X-%X",
+			binary.LittleEndian.Uint32(b[0:4]),
+			binary.LittleEndian.Uint16(b[4:6]),
+			binary.LittleEndian.Uint16(b[6:8]),
+			b[8:10],
+			b[10:16],
+		)
+	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+var markdownReplacer = strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>")
+
+// EscapeMarkdown escapes pipes and line breaks of text in a cell of a
+// Markdown table
+func EscapeMarkdown(s string) string {
+	return markdownReplacer.Replace(s)
+}
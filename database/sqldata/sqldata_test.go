@@ -0,0 +1,113 @@
+package sqldata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestColumnTypeKind(t *testing.T) {
+	tests := []struct {
+		typeName    string
+		kind        ColumnKind
+		hasTimeZone bool
+	}{
+		{"BIT", ColumnKindBoolean, false},
+		{"BOOL", ColumnKindBoolean, false},
+		{"INT4", ColumnKindInteger, false},
+		{"BIGINT", ColumnKindInteger, false},
+		{"MONEY", ColumnKindDecimal, false},
+		{"NUMERIC", ColumnKindDecimal, false},
+		{"FLOAT8", ColumnKindFloat, false},
+		{"NVARCHAR", ColumnKindText, false},
+		{"JSONB", ColumnKindText, false},
+		{"BYTEA", ColumnKindBinary, false},
+		{"DATE", ColumnKindDate, false},
+		{"TIMETZ", ColumnKindTime, true},
+		{"DATETIME2", ColumnKindDateTime, false},
+		{"DATETIMEOFFSET", ColumnKindDateTime, true},
+		{"TIMESTAMPTZ", ColumnKindDateTime, true},
+		{"UNIQUEIDENTIFIER", ColumnKindUUID, false},
+		{"GEOGRAPHY", ColumnKindUnknown, false},
+		{"", ColumnKindUnknown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			c := &ColumnType{DatabaseTypeName: tt.typeName}
+			if kind := c.Kind(); kind != tt.kind {
+				t.Errorf("Kind() = %d, want %d", kind, tt.kind)
+			}
+			if hasTimeZone := c.HasTimeZone(); hasTimeZone != tt.hasTimeZone {
+				t.Errorf("HasTimeZone() = %v, want %v", hasTimeZone, tt.hasTimeZone)
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	hongKong := time.FixedZone("HKT", 8*60*60)
+	dateTime := time.Date(2023, 6, 15, 14, 30, 45, 123000000, hongKong)
+	// 6F9619FF-8B86-D011-B42D-00C04FC964FF stored by SQL Server
+	guid := []byte{0xFF, 0x19, 0x96, 0x6F, 0x86, 0x8B, 0x11, 0xD0, 0xB4, 0x2D, 0x00, 0xC0, 0x4F, 0xC9, 0x64, 0xFF}
+
+	tests := []struct {
+		name       string
+		input      interface{}
+		columnType *ColumnType
+		expected   string
+	}{
+		{"without type", []byte{0x01, 0xAB}, nil, "\x01\xab"},
+		{"binary", []byte{0x01, 0xAB}, &ColumnType{DatabaseTypeName: "VARBINARY"}, "0x01AB"},
+		{"empty binary", []byte{}, &ColumnType{DatabaseTypeName: "BYTEA"}, "0x"},
+		{"decimal", []byte("12.50"), &ColumnType{DatabaseTypeName: "DECIMAL"}, "12.50"},
+		{"text", []byte("hello"), &ColumnType{DatabaseTypeName: "VARCHAR"}, "hello"},
+		{"uniqueidentifier", guid, &ColumnType{DatabaseTypeName: "UNIQUEIDENTIFIER"}, "6F9619FF-8B86-D011-B42D-00C04FC964FF"},
+		{"uuid bytes", guid, &ColumnType{DatabaseTypeName: "UUID"}, "ff19966f-868b-11d0-b42d-00c04fc964ff"},
+		{"uuid string", "ff19966f-868b-11d0-b42d-00c04fc964ff", &ColumnType{DatabaseTypeName: "UUID"}, "ff19966f-868b-11d0-b42d-00c04fc964ff"},
+		{"date", dateTime, &ColumnType{DatabaseTypeName: "DATE"}, "2023-06-15"},
+		{"time", dateTime, &ColumnType{DatabaseTypeName: "TIME"}, "14:30:45.123"},
+		{"time with time zone", dateTime, &ColumnType{DatabaseTypeName: "TIMETZ"}, "14:30:45.123 +08:00"},
+		{"date-time", dateTime, &ColumnType{DatabaseTypeName: "DATETIME2"}, "2023-06-15 14:30:45.123"},
+		{"date-time with time zone", dateTime, &ColumnType{DatabaseTypeName: "DATETIMEOFFSET"}, "2023-06-15 14:30:45.123 +08:00"},
+		{"null", nil, &ColumnType{DatabaseTypeName: "VARBINARY"}, "NULL"},
+		{"bool", true, &ColumnType{DatabaseTypeName: "BIT"}, "1"},
+		{"unknown type", int64(42), &ColumnType{DatabaseTypeName: "GEOGRAPHY"}, "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := FormatValue(tt.input, tt.columnType); result != tt.expected {
+				t.Errorf("FormatValue(%v) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"a|b", "a\\|b"},
+		{"line 1\nline 2", "line 1<br>line 2"},
+		{"line 1\r\nline 2", "line 1<br>line 2"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := EscapeMarkdown(tt.input); result != tt.expected {
+				t.Errorf("EscapeMarkdown(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func BenchmarkFormatValue(b *testing.B) {
+	var val interface{} = []byte{0x01, 0x02, 0x03, 0x04}
+	columnType := &ColumnType{DatabaseTypeName: "VARBINARY"}
+	for i := 0; i < b.N; i++ {
+		_ = FormatValue(val, columnType)
+	}
+}
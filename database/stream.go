@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"iter"
+
+	"github.com/alexhokl/helper/database/sqldata"
+)
+
+var errRowsRead = errors.New("rows have already been read")
+
+// RowReader reads rows of a query one at a time so that large result sets
+// are not held in memory
+type RowReader struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	rows        *sql.Rows
+	columns     []string
+	columnTypes []ColumnType
+	err         error
+}
+
+// GetRowReader runs query with conn and returns a reader of its rows; see
+// GetDataContext for cancellation and args. The reader must be closed
+// unless its rows are read until the end.
+func GetRowReader(ctx context.Context, conn *sql.DB, query string, args ...any) (*RowReader, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	rows, errQuery := conn.QueryContext(ctx, query, args...)
+	if errQuery != nil {
+		cancel()
+		return nil, contextError(ctx, errQuery)
+	}
+
+	cols, errColumns := rows.Columns()
+	if errColumns != nil {
+		_ = rows.Close()
+		cancel()
+		return nil, errColumns
+	}
+
+	types, errTypes := rows.ColumnTypes()
+	if errTypes != nil {
+		_ = rows.Close()
+		cancel()
+		return nil, errTypes
+	}
+
+	return &RowReader{
+		ctx:         ctx,
+		cancel:      cancel,
+		rows:        rows,
+		columns:     cols,
+		columnTypes: sqldata.NewColumnTypes(types),
+	}, nil
+}
+
+// Columns returns the names of the columns
+func (r *RowReader) Columns() []string {
+	return r.columns
+}
+
+// ColumnTypes returns the types of the columns
+func (r *RowReader) ColumnTypes() []ColumnType {
+	return r.columnTypes
+}
+
+// Rows returns an iterator of rows in the same format as TableData.Rows;
+// the iteration stops at the first error and the reader is closed when the
+// iteration ends. Rows can only be iterated once.
+func (r *RowReader) Rows() iter.Seq2[[]interface{}, error] {
+	return func(yield func([]interface{}, error) bool) {
+		defer r.Close()
+
+		if r.err != nil {
+			yield(nil, r.err)
+			return
+		}
+		r.err = errRowsRead
+
+		for r.rows.Next() {
+			vals := newRowValues(len(r.columns))
+			if err := r.rows.Scan(vals...); err != nil {
+				yield(nil, contextError(r.ctx, err))
+				return
+			}
+			if !yield(vals, nil) {
+				return
+			}
+		}
+		if err := r.rows.Err(); err != nil {
+			yield(nil, contextError(r.ctx, err))
+		}
+	}
+}
+
+// ReadAll reads the remaining rows into TableData
+func (r *RowReader) ReadAll() (*TableData, error) {
+	var dataRows [][]interface{}
+	for row, err := range r.Rows() {
+		if err != nil {
+			return nil, err
+		}
+		dataRows = append(dataRows, row)
+	}
+
+	data := &TableData{
+		Rows:        dataRows,
+		Columns:     r.columns,
+		ColumnTypes: r.columnTypes,
+	}
+
+	return data, nil
+}
+
+// Close closes the reader and cancels the query if rows have not been read
+// until the end
+func (r *RowReader) Close() error {
+	defer r.cancel()
+	return r.rows.Close()
+}
+
+func newRowValues(columnCount int) []interface{} {
+	vals := make([]interface{}, columnCount)
+	for i := 0; i < columnCount; i++ {
+		vals[i] = new(interface{})
+	}
+	return vals
+}
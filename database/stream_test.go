@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+const fakeQueryNumbers = "SELECT n FROM numbers"
+
+func newFakeNumbersResult(count int) *fakeResult {
+	rows := make([][]driver.Value, count)
+	for i := range rows {
+		rows[i] = []driver.Value{int64(i)}
+	}
+	return &fakeResult{columns: []fakeColumn{{name: "n"}}, rows: rows}
+}
+
+func TestRowReader(t *testing.T) {
+	db := newFakeDB(t, map[string]*fakeResult{fakeQueryNumbers: newFakeNumbersResult(5)})
+
+	reader, err := GetRowReader(context.Background(), db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+	if len(reader.Columns()) != 1 || reader.Columns()[0] != "n" {
+		t.Errorf("Columns() = %v, want [n]", reader.Columns())
+	}
+
+	var values []string
+	for row, err := range reader.Rows() {
+		if err != nil {
+			t.Fatalf("Rows() error: %v", err)
+		}
+		values = append(values, GetValue(row[0].(*interface{})))
+	}
+	if len(values) != 5 || values[0] != "0" || values[4] != "4" {
+		t.Errorf("Rows() = %v", values)
+	}
+
+	// rows can only be iterated once
+	for _, err := range reader.Rows() {
+		if !errors.Is(err, errRowsRead) {
+			t.Errorf("second Rows() error = %v, want %v", err, errRowsRead)
+		}
+	}
+	if err := reader.Close(); err != nil {
+		t.Errorf("Close() after Rows() error: %v", err)
+	}
+}
+
+func TestRowReaderBreak(t *testing.T) {
+	db := newFakeDB(t, map[string]*fakeResult{fakeQueryNumbers: newFakeNumbersResult(5)})
+
+	reader, err := GetRowReader(context.Background(), db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+	count := 0
+	for range reader.Rows() {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("Rows() yielded %d rows, want 2", count)
+	}
+	// the query is cancelled when the iteration stops
+	if reader.ctx.Err() == nil {
+		t.Error("query is not cancelled after the iteration stops")
+	}
+	if db.Stats().InUse != 0 {
+		t.Errorf("%d connections are in use after the iteration stops", db.Stats().InUse)
+	}
+}
+
+func TestRowReaderErrors(t *testing.T) {
+	errBroken := errors.New("connection broken")
+	result := newFakeNumbersResult(3)
+	result.err = errBroken
+	db := newFakeDB(t, map[string]*fakeResult{fakeQueryNumbers: result})
+
+	reader, err := GetRowReader(context.Background(), db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+	count := 0
+	var lastErr error
+	for _, err := range reader.Rows() {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		count++
+	}
+	if count != 3 || !errors.Is(lastErr, errBroken) {
+		t.Errorf("Rows() yielded %d rows and error %v, want 3 rows and %v", count, lastErr, errBroken)
+	}
+
+	if _, err := GetRowReader(context.Background(), db, "SELECT unknown"); err == nil {
+		t.Error("GetRowReader() of unknown query should return error")
+	}
+}
+
+func TestRowReaderCancellation(t *testing.T) {
+	db := newFakeDB(t, map[string]*fakeResult{fakeQueryNumbers: newFakeNumbersResult(5)})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader, err := GetRowReader(ctx, db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+	count := 0
+	var lastErr error
+	for _, err := range reader.Rows() {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		count++
+		cancel()
+	}
+	if count != 1 || !errors.Is(lastErr, context.Canceled) {
+		t.Errorf("Rows() yielded %d rows and error %v, want 1 row and %v", count, lastErr, context.Canceled)
+	}
+}
+
+func TestRowReaderReadAll(t *testing.T) {
+	db := newFakeDB(t, map[string]*fakeResult{fakeQueryNumbers: newFakeNumbersResult(3)})
+
+	reader, err := GetRowReader(context.Background(), db, fakeQueryNumbers)
+	if err != nil {
+		t.Fatalf("GetRowReader() error: %v", err)
+	}
+	data, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+	if len(data.Rows) != 3 || len(data.Columns) != 1 {
+		t.Errorf("ReadAll() = %+v", data)
+	}
+}
+
+func BenchmarkRowReader(b *testing.B) {
+	db := newFakeDB(b, map[string]*fakeResult{fakeQueryNumbers: newFakeNumbersResult(1000)})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reader, _ := GetRowReader(context.Background(), db, fakeQueryNumbers)
+		for range reader.Rows() {
+		}
+	}
+}
@@ -0,0 +1,8 @@
+module github.com/alexhokl/helper/database/testdata/parquetref
+
+go 1.25.5
+
+require (
+	github.com/xitongsys/parquet-go v1.6.2
+	github.com/xitongsys/parquet-go-source v0.0.0-20240122235623-d6294584ab18
+)
@@ -0,0 +1,77 @@
+// Command parquetref writes testdata/reference.parquet with
+// github.com/xitongsys/parquet-go, an independent implementation of Parquet,
+// from the same values as newTestExportTableData so that the output of
+// parquetExporter can be compared with it in TestParquetExporterReference.
+//
+// Usage (from this directory):
+//
+//	go mod tidy
+//	go run . ../reference.parquet
+package main
+
+import (
+	"os"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type row struct {
+	ID        *int64   `parquet:"name=id, type=INT64, repetitiontype=OPTIONAL, encoding=PLAIN"`
+	Name      *string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, logicaltype=STRING, repetitiontype=OPTIONAL, encoding=PLAIN"`
+	Price     *string  `parquet:"name=price, type=BYTE_ARRAY, convertedtype=UTF8, logicaltype=STRING, repetitiontype=OPTIONAL, encoding=PLAIN"`
+	Active    *bool    `parquet:"name=active, type=BOOLEAN, repetitiontype=OPTIONAL, encoding=PLAIN"`
+	Score     *float64 `parquet:"name=score, type=DOUBLE, repetitiontype=OPTIONAL, encoding=PLAIN"`
+	Hash      *string  `parquet:"name=hash, type=BYTE_ARRAY, repetitiontype=OPTIONAL, encoding=PLAIN"`
+	Birthday  *int32   `parquet:"name=birthday, type=INT32, convertedtype=DATE, logicaltype=DATE, repetitiontype=OPTIONAL, encoding=PLAIN"`
+	CreatedAt *int64   `parquet:"name=created_at, type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=false, logicaltype.unit=MICROS, repetitiontype=OPTIONAL, encoding=PLAIN"`
+	UpdatedAt *int64   `parquet:"name=updated_at, type=INT64, convertedtype=TIMESTAMP_MICROS, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MICROS, repetitiontype=OPTIONAL, encoding=PLAIN"`
+	GUID      *string  `parquet:"name=guid, type=BYTE_ARRAY, convertedtype=UTF8, logicaltype=STRING, repetitiontype=OPTIONAL, encoding=PLAIN"`
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func main() {
+	f, err := local.NewLocalFileWriter(os.Args[1])
+	if err != nil {
+		panic(err)
+	}
+	w, err := writer.NewParquetWriter(f, new(row), 1)
+	if err != nil {
+		panic(err)
+	}
+	w.CompressionType = parquet.CompressionCodec_UNCOMPRESSED
+	w.RowGroupSize = 128 * 1024 * 1024
+	w.PageSize = 8 * 1024
+	rows := []row{
+		{
+			// dates are days and timestamps are microseconds since the
+			// Unix epoch as written by parquetExporter
+			ID:        ptr(int64(1)),
+			Name:      ptr("alex, \"the\" dev\nline 2"),
+			Price:     ptr("12.50"),
+			Active:    ptr(true),
+			Score:     ptr(1.5),
+			Hash:      ptr("\xCA\xFE"),
+			Birthday:  ptr(int32(19523)),
+			CreatedAt: ptr(int64(1686839445123000)),
+			UpdatedAt: ptr(int64(1686810645000000)),
+			GUID:      ptr("6F9619FF-8B86-D011-B42D-00C04FC964FF"),
+		},
+		{},
+	}
+	for _, r := range rows {
+		if err := w.Write(r); err != nil {
+			panic(err)
+		}
+	}
+	if err := w.WriteStop(); err != nil {
+		panic(err)
+	}
+	if err := f.Close(); err != nil {
+		panic(err)
+	}
+}
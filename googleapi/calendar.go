@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/alexhokl/helper/collection"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/option"
@@ -14,6 +15,10 @@ import (
 
 const listDefaultMax = 250
 
+// deleteEventsConcurrency limits concurrent requests of DeleteEvents to stay
+// within the rate limit of Calendar API
+const deleteEventsConcurrency = 4
+
 func NewCalendarService(ctx context.Context, oauthConfig *oauth2.Config, token *oauth2.Token) (*calendar.Service, error) {
 	httpClient := oauthConfig.Client(ctx, token)
 	tokenSource := oauthConfig.TokenSource(ctx, token)
@@ -148,13 +153,12 @@ func DeleteEvent(srv *calendar.Service, calendarID string, eventID string) error
 	return nil
 }
 
+// DeleteEvents deletes the specified events concurrently and returns the
+// errors of all events which cannot be deleted
 func DeleteEvents(srv *calendar.Service, calendarID string, eventIDs []string) error {
-	for _, eventID := range eventIDs {
-		if err := DeleteEvent(srv, calendarID, eventID); err != nil {
-			return err
-		}
-	}
-	return nil
+	return collection.ForEach(context.Background(), eventIDs, func(_ context.Context, eventID string) error {
+		return DeleteEvent(srv, calendarID, eventID)
+	}, collection.WithWorkers(deleteEventsConcurrency))
 }
 
 // / GetCalendarTimeZone
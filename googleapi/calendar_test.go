@@ -1,6 +1,7 @@
 package googleapi
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -51,6 +52,19 @@ func TestDeleteEventsEmptySlice(t *testing.T) {
 	}
 }
 
+func TestDeleteEventsCollectsAllErrors(t *testing.T) {
+	// every event fails validation before any request is sent
+	err := DeleteEvents(nil, "", []string{"event-1", "event-2"})
+	if err == nil {
+		t.Fatal("DeleteEvents() with empty calendarID should return error")
+	}
+	for _, want := range []string{"item 0:", "item 1:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("DeleteEvents() error = %v, should contain %q", err, want)
+		}
+	}
+}
+
 func TestGetCalendarTimeZoneEmptyCalendarID(t *testing.T) {
 	_, err := GetCalendarTimeZone(nil, "")
 	if err == nil {
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/alexhokl/helper/database/sqldata"
 	"google.golang.org/api/option"
 	sheets "google.golang.org/api/sheets/v4"
 )
@@ -16,6 +17,29 @@ type ColumnFormatConfig interface {
 	ColumnFormat() string
 }
 
+const (
+	sheetDateFormat     = "yyyy-mm-dd"
+	sheetDateTimeFormat = "yyyy-mm-dd hh:mm:ss"
+)
+
+type columnFormatConfig struct {
+	index    int
+	dataType string
+	format   string
+}
+
+func (c *columnFormatConfig) ColumnIndex() int {
+	return c.index
+}
+
+func (c *columnFormatConfig) ColumnDataType() string {
+	return c.dataType
+}
+
+func (c *columnFormatConfig) ColumnFormat() string {
+	return c.format
+}
+
 func NewSpreadsheetService(ctx context.Context, client *http.Client) (*sheets.Service, error) {
 	return sheets.NewService(
 		ctx,
@@ -80,6 +104,40 @@ func UpdateRows(service *sheets.Service, document *sheets.Spreadsheet, sheetName
 	return nil
 }
 
+// UpdateTypedRows is the same as UpdateRows except values are formatted
+// according to the types of their columns and values of text columns are
+// not interpreted as numbers, dates or formulas
+func UpdateTypedRows(service *sheets.Service, document *sheets.Spreadsheet, sheetName string, rows [][]interface{}, columnTypes []sqldata.ColumnType) error {
+	values := newTypedRowsValueRange(rows, columnTypes)
+	_, err := service.Spreadsheets.Values.Update(
+		document.SpreadsheetId,
+		fmt.Sprintf("%s!A2", sheetName),
+		values,
+	).ValueInputOption("USER_ENTERED").Do()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetColumnFormatConfigs returns formats of date, date-time and money
+// columns for UpdateColumnStyles; date-times with time zones are excluded
+// as they are not recognised as dates
+func GetColumnFormatConfigs(columnTypes []sqldata.ColumnType) []ColumnFormatConfig {
+	var configs []ColumnFormatConfig
+	for i, c := range columnTypes {
+		switch {
+		case c.Kind() == sqldata.ColumnKindDate:
+			configs = append(configs, &columnFormatConfig{index: i, dataType: "date", format: sheetDateFormat})
+		case c.Kind() == sqldata.ColumnKindDateTime && !c.HasTimeZone():
+			configs = append(configs, &columnFormatConfig{index: i, dataType: "date", format: sheetDateTimeFormat})
+		case c.DatabaseTypeName == "MONEY" || c.DatabaseTypeName == "SMALLMONEY":
+			configs = append(configs, &columnFormatConfig{index: i, dataType: "money"})
+		}
+	}
+	return configs
+}
+
 func UpdateColumnStyles(service *sheets.Service, document *sheets.Spreadsheet, sheetId int64, columns []ColumnFormatConfig) error {
 	if columns == nil {
 		return nil
@@ -106,6 +164,33 @@ func newRowsValueRange(rows [][]interface{}) *sheets.ValueRange {
 	return values
 }
 
+func newTypedRowsValueRange(rows [][]interface{}, columnTypes []sqldata.ColumnType) *sheets.ValueRange {
+	values := &sheets.ValueRange{}
+	values.Values = make([][]interface{}, len(rows))
+	for i, r := range rows {
+		for j, cell := range r {
+			values.Values[i] = append(
+				values.Values[i],
+				getTypedValue(cell.(*interface{}), columnTypes, j),
+			)
+		}
+	}
+	return values
+}
+
+func getTypedValue(pval *interface{}, columnTypes []sqldata.ColumnType, index int) string {
+	if index >= len(columnTypes) {
+		return getValue(pval)
+	}
+	columnType := &columnTypes[index]
+	value := sqldata.FormatValue(*pval, columnType)
+	// an apostrophe keeps a value as text when it is entered by user
+	if *pval != nil && columnType.Kind() == sqldata.ColumnKindText {
+		return "'" + value
+	}
+	return value
+}
+
 func getValue(pval *interface{}) string {
 	switch v := (*pval).(type) {
 	case nil:
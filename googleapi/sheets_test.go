@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alexhokl/helper/database/sqldata"
 	sheets "google.golang.org/api/sheets/v4"
 )
 
@@ -612,6 +613,70 @@ func TestNewColumnFormatRequestMultipleColumns(t *testing.T) {
 	}
 }
 
+func TestNewTypedRowsValueRange(t *testing.T) {
+	var val1 interface{} = "00123"
+	var val2 interface{} = []byte{0xCA, 0xFE}
+	var val3 interface{} = time.Date(2023, 6, 15, 14, 30, 45, 0, time.UTC)
+	var val4 interface{} = nil
+	var val5 interface{} = int64(42)
+	rows := [][]interface{}{
+		{&val1, &val2, &val3, &val4, &val5},
+	}
+	columnTypes := []sqldata.ColumnType{
+		{Name: "code", DatabaseTypeName: "VARCHAR"},
+		{Name: "hash", DatabaseTypeName: "VARBINARY"},
+		{Name: "birthday", DatabaseTypeName: "DATE"},
+		{Name: "remarks", DatabaseTypeName: "NVARCHAR"},
+	}
+
+	result := newTypedRowsValueRange(rows, columnTypes)
+
+	expected := []interface{}{"'00123", "0xCAFE", "2023-06-15", "NULL", "42"}
+	if len(result.Values) != 1 || len(result.Values[0]) != len(expected) {
+		t.Fatalf("Values = %v, want [%v]", result.Values, expected)
+	}
+	for i, v := range expected {
+		if result.Values[0][i] != v {
+			t.Errorf("Values[0][%d] = %v, want %q", i, result.Values[0][i], v)
+		}
+	}
+}
+
+func TestGetColumnFormatConfigs(t *testing.T) {
+	columnTypes := []sqldata.ColumnType{
+		{Name: "id", DatabaseTypeName: "INT"},
+		{Name: "birthday", DatabaseTypeName: "DATE"},
+		{Name: "created_at", DatabaseTypeName: "DATETIME2"},
+		{Name: "updated_at", DatabaseTypeName: "DATETIMEOFFSET"},
+		{Name: "price", DatabaseTypeName: "MONEY"},
+	}
+
+	result := GetColumnFormatConfigs(columnTypes)
+
+	expected := []columnFormatConfig{
+		{index: 1, dataType: "date", format: sheetDateFormat},
+		{index: 2, dataType: "date", format: sheetDateTimeFormat},
+		{index: 4, dataType: "money"},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("GetColumnFormatConfigs() length = %d, want %d", len(result), len(expected))
+	}
+	for i, e := range expected {
+		c := result[i]
+		if c.ColumnIndex() != e.index || c.ColumnDataType() != e.dataType || c.ColumnFormat() != e.format {
+			t.Errorf("GetColumnFormatConfigs()[%d] = %+v, want %+v", i, c, e)
+		}
+	}
+	// configurations are supported by newColumnFormatRequest
+	if request := newColumnFormatRequest(123, result); len(request.Requests) != len(expected) {
+		t.Errorf("newColumnFormatRequest() requests = %d, want %d", len(request.Requests), len(expected))
+	}
+
+	if result := GetColumnFormatConfigs(nil); result != nil {
+		t.Errorf("GetColumnFormatConfigs(nil) = %v, want nil", result)
+	}
+}
+
 // Benchmark tests
 func BenchmarkGetValue(b *testing.B) {
 	var val interface{} = "benchmark string"
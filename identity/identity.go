@@ -11,7 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/youmark/pkcs8"
+	"github.com/alexhokl/helper/cryptohelper"
 )
 
 // GetTokenString retrieves bearer token string from the specified request
@@ -70,14 +70,16 @@ func GetPublicKeyFromCertificate(path string) (*rsa.PublicKey, error) {
 	}
 }
 
-// GetPrivateKey extract encrypted RSA private key from PEM file
+// GetPrivateKey extract RSA private key from PEM file which may be encrypted
+// with password; see cryptohelper.ParsePrivateKey for the supported formats
 func GetPrivateKey(path string, password string) (*rsa.PrivateKey, error) {
-	pemBlock, errFile := getPemBlock(path)
-	if errFile != nil {
-		return nil, errFile
+	path = filepath.Clean(path)
+	bytes, errRead := os.ReadFile(path)
+	if errRead != nil {
+		return nil, fmt.Errorf("unable to read file %s with error %w", path, errRead)
 	}
 
-	key, errDecrpt := pkcs8.ParsePKCS8PrivateKey(pemBlock.Bytes, []byte(password))
+	key, errDecrpt := cryptohelper.ParsePrivateKey(bytes, []byte(password))
 	if errDecrpt != nil {
 		return nil, fmt.Errorf("unable to decrypted key from file %s with error %w", path, errDecrpt)
 	}
@@ -353,6 +353,30 @@ func TestGetPrivateKeyWrongPassword(t *testing.T) {
 	}
 }
 
+func TestGetPrivateKeyPKCS1(t *testing.T) {
+	// Generate test key
+	privateKey := generateTestRSAKey(t)
+
+	// Create unencrypted PKCS#1 private key PEM file
+	privKeyPath := filepath.Join(t.TempDir(), "private_pkcs1.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+	if err := os.WriteFile(privKeyPath, pemBytes, 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	loadedKey, err := GetPrivateKey(privKeyPath, "")
+	if err != nil {
+		t.Fatalf("GetPrivateKey() error: %v", err)
+	}
+
+	if loadedKey.D.Cmp(privateKey.D) != 0 {
+		t.Error("GetPrivateKey() returned different key")
+	}
+}
+
 func TestGetPrivateKeyFileNotFound(t *testing.T) {
 	_, err := GetPrivateKey("/nonexistent/path/to/key.pem", "password")
 	if err == nil {